@@ -0,0 +1,148 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"timetracker/api/model"
+	"timetracker/logger"
+)
+
+type contextKey string
+
+const (
+	userContextKey         contextKey = "user"
+	sessionContextKey      contextKey = "session"
+	sessionTokenContextKey contextKey = "session_token"
+	requestIDContextKey    contextKey = "request_id"
+)
+
+func userFromContext(ctx context.Context) (*model.User, bool) {
+	user, ok := ctx.Value(userContextKey).(*model.User)
+	return user, ok
+}
+
+func sessionFromContext(ctx context.Context) (*model.Session, bool) {
+	session, ok := ctx.Value(sessionContextKey).(*model.Session)
+	return session, ok
+}
+
+// verifiedUserFromContext returns the signed in user, unless the session still waits for its second factor.
+func verifiedUserFromContext(ctx context.Context) (*model.User, bool) {
+	user, ok := userFromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	if session, ok := sessionFromContext(ctx); ok && session.MFAPending {
+		return nil, false
+	}
+	return user, true
+}
+
+// mayFilterByUser tells whether the caller may list trackers by their owner userID: users by
+// their own id, admins by any, just as the owner of a tracker is only shown to them.
+func mayFilterByUser(ctx context.Context, userID int) bool {
+	user, ok := verifiedUserFromContext(ctx)
+	return ok && (user.ID == userID || user.Role == model.RoleAdmin)
+}
+
+func sessionTokenFromContext(ctx context.Context) string {
+	token, _ := ctx.Value(sessionTokenContextKey).(string)
+	return token
+}
+
+// sessionToken reads the session token from the Authorization bearer header, which the
+// mobile app uses, or from the session cookie set for browsers.
+func sessionToken(req *http.Request, cookieName string) string {
+	if authorization := req.Header.Get("Authorization"); authorization != "" {
+		scheme, token, ok := strings.Cut(authorization, " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+
+	if cookie, err := req.Cookie(cookieName); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
+
+// authenticate resolves the session token of the request, if any, and stores the
+// session user in the request context. It never rejects a request by itself, routes
+// that need a user are wrapped with requireAuth.
+func (r *router) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		token := sessionToken(req, r.cfg.Auth.CookieName)
+		if token == "" {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		session, user, err := r.handler.service.AuthenticateService(req.Context(), token)
+		if err != nil {
+			if !strings.Contains(err.Error(), "not found") {
+				r.logger.Errorf("authenticate: Failed to resolve session - %v", err)
+			}
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		ctx := context.WithValue(req.Context(), userContextKey, user)
+		ctx = context.WithValue(ctx, sessionContextKey, session)
+		ctx = context.WithValue(ctx, sessionTokenContextKey, token)
+		ctx = logger.ContextWith(ctx, logger.UserID(user.ID))
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+// requireAuth rejects requests without a valid session with 401 Unauthorized. Sessions that
+// still wait for their second factor are rejected as well.
+func (h *handler) requireAuth(next http.HandlerFunc) http.HandlerFunc {
+	return h.requireSession(func(w http.ResponseWriter, req *http.Request) {
+		if session, _ := sessionFromContext(req.Context()); session.MFAPending {
+			h.sendErrorResponse(w, req, http.StatusUnauthorized,
+				"Two-factor verification required",
+				"Verify the session with POST /v1/auth/2fa/verify first",
+				"MFA_REQUIRED")
+			return
+		}
+		next(w, req)
+	})
+}
+
+// requireAdmin only lets sessions of users with the admin role through.
+func (h *handler) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return h.requireAuth(func(w http.ResponseWriter, req *http.Request) {
+		if user, _ := userFromContext(req.Context()); user.Role != model.RoleAdmin {
+			h.sendErrorResponse(w, req, http.StatusForbidden,
+				"Forbidden",
+				"This endpoint requires the admin role",
+				"FORBIDDEN")
+			return
+		}
+		next(w, req)
+	})
+}
+
+// requireSession rejects requests without a session, but unlike requireAuth accepts
+// sessions whose second factor is still pending.
+func (h *handler) requireSession(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if _, ok := sessionFromContext(req.Context()); !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="timetracker"`)
+			h.sendErrorResponse(w, req, http.StatusUnauthorized,
+				"Authentication required",
+				"A valid session token is required for this endpoint",
+				"UNAUTHORIZED")
+			return
+		}
+		next(w, req)
+	}
+}
@@ -0,0 +1,201 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// oidcStateCookie holds the state of the OIDC login started in the browser until the callback.
+const oidcStateCookie = "oidc_state"
+
+// OIDCLoginHandler starts an OpenID Connect login.
+// It stores a fresh state, nonce and PKCE verifier and redirects the user agent to the
+// authorization endpoint of the identity provider. The state is also set as an HttpOnly cookie,
+// which binds the login to this browser.
+//
+// Returns:
+//   - 302 Found: Redirect to the identity provider
+//   - 404 Not Found: OIDC login is disabled in the configuration
+//   - 502 Bad Gateway: The identity provider metadata could not be loaded
+func (h *handler) OIDCLoginHandler(w http.ResponseWriter, req *http.Request) {
+	h.log(req).Infof("OIDCLoginHandler: Processing request from %s", req.RemoteAddr)
+
+	authURL, state, err := h.service.BeginOIDCLoginService(req.Context())
+	if err != nil {
+		h.log(req).Errorf("OIDCLoginHandler: Service error - %v", err)
+		if errors.Is(err, errOIDCDisabled) {
+			h.sendErrorResponse(w, req, http.StatusNotFound,
+				"OIDC login disabled",
+				"Single sign-on is not configured for this server",
+				"OIDC_DISABLED")
+			return
+		}
+
+		h.sendErrorResponse(w, req, http.StatusBadGateway,
+			"Failed to start login",
+			"The identity provider could not be reached",
+			"LOGIN_ERROR")
+		return
+	}
+
+	h.setOIDCStateCookie(w, state, time.Now().Add(oidcLoginTTL))
+	http.Redirect(w, req, authURL, http.StatusFound)
+}
+
+// OIDCCallbackHandler completes an OpenID Connect login.
+// The identity provider redirects back here with the code and state query parameters. The state has
+// to match the state cookie set by OIDCLoginHandler in the same browser. The code is exchanged with the stored PKCE verifier, the ID token is verified against the provider keys and
+// the user is provisioned by the email claim. The API session is set as a cookie and either returned
+// as JSON or, when post_login_redirect_url is configured, followed by a redirect to that URL.
+//
+// Returns:
+//   - 200 OK: Session token, expiry and user data
+//   - 302 Found: Redirect to the configured post login URL
+//   - 400 Bad Request: Missing code or state parameter
+//   - 401 Unauthorized: The login was denied or could not be verified
+//   - 404 Not Found: OIDC login is disabled in the configuration
+//   - 500 Internal Server Error: Database or server errors
+func (h *handler) OIDCCallbackHandler(w http.ResponseWriter, req *http.Request) {
+	h.log(req).Infof("OIDCCallbackHandler: Processing request from %s", req.RemoteAddr)
+
+	query := req.URL.Query()
+	if providerErr := query.Get("error"); providerErr != "" {
+		h.log(req).Warnf("OIDCCallbackHandler: Identity provider returned %s - %s", providerErr, query.Get("error_description"))
+		h.sendErrorResponse(w, req, http.StatusUnauthorized,
+			"Login denied",
+			"The identity provider did not authorize the login",
+			"LOGIN_FAILED")
+		return
+	}
+
+	code, state := query.Get("code"), query.Get("state")
+	if code == "" || state == "" {
+		h.sendErrorResponse(w, req, http.StatusBadRequest,
+			"Invalid callback",
+			"code and state query parameters are required",
+			"INVALID_CALLBACK")
+		return
+	}
+
+	browserState := ""
+	if cookie, err := req.Cookie(oidcStateCookie); err == nil {
+		browserState = cookie.Value
+	}
+	// The state is used up whether or not the login succeeds.
+	h.setOIDCStateCookie(w, "", time.Unix(0, 0))
+
+	login, err := h.service.CompleteOIDCLoginService(req.Context(), state, browserState, code)
+	if err != nil {
+		h.log(req).Errorf("OIDCCallbackHandler: Service error - %v", err)
+		switch {
+		case errors.Is(err, errOIDCDisabled):
+			h.sendErrorResponse(w, req, http.StatusNotFound,
+				"OIDC login disabled",
+				"Single sign-on is not configured for this server",
+				"OIDC_DISABLED")
+		case errors.Is(err, errLoginFailed):
+			h.sendErrorResponse(w, req, http.StatusUnauthorized,
+				"Login failed",
+				"The login could not be verified, please sign in again",
+				"LOGIN_FAILED")
+		default:
+			h.sendErrorResponse(w, req, http.StatusInternalServerError,
+				"Login failed",
+				"An error occurred while creating the session",
+				"LOGIN_ERROR")
+		}
+		return
+	}
+
+	h.log(req).Infof("OIDCCallbackHandler: User ID %d logged in", login.User.ID)
+	h.setSessionCookie(w, login.Token, login.ExpiresAt)
+
+	if redirectURL := h.cfg.OIDC.PostLoginRedirectURL; redirectURL != "" {
+		http.Redirect(w, req, redirectURL, http.StatusFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(login)
+}
+
+// LogoutHandler revokes the session used for the request and clears the session cookie.
+//
+// Returns:
+//   - 204 No Content: Session revoked
+//   - 401 Unauthorized: No session
+//   - 500 Internal Server Error: Database or server errors
+func (h *handler) LogoutHandler(w http.ResponseWriter, req *http.Request) {
+	h.log(req).Infof("LogoutHandler: Processing request from %s", req.RemoteAddr)
+
+	if err := h.service.LogoutService(req.Context(), sessionTokenFromContext(req.Context())); err != nil &&
+		!strings.Contains(err.Error(), "not found") {
+		h.log(req).Errorf("LogoutHandler: Service error - %v", err)
+		h.sendErrorResponse(w, req, http.StatusInternalServerError,
+			"Failed to log out",
+			"An error occurred while revoking the session",
+			"LOGOUT_ERROR")
+		return
+	}
+
+	h.setSessionCookie(w, "", time.Unix(0, 0))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CurrentUserHandler returns the user the session belongs to.
+//
+// Returns:
+//   - 200 OK: User data
+//   - 401 Unauthorized: No valid session
+func (h *handler) CurrentUserHandler(w http.ResponseWriter, req *http.Request) {
+	user, _ := userFromContext(req.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(user)
+}
+
+// setOIDCStateCookie keeps the state of a login in progress. SameSite Lax still sends it with the
+// top level redirect back from the identity provider.
+func (h *handler) setOIDCStateCookie(w http.ResponseWriter, state string, expiresAt time.Time) {
+	cookie := &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     "/",
+		Expires:  expiresAt,
+		HttpOnly: true,
+		Secure:   h.cfg.Auth.CookieSecure,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if state == "" {
+		cookie.MaxAge = -1
+	}
+	http.SetCookie(w, cookie)
+}
+
+func (h *handler) setSessionCookie(w http.ResponseWriter, token string, expiresAt time.Time) {
+	cookie := &http.Cookie{
+		Name:     h.cfg.Auth.CookieName,
+		Value:    token,
+		Path:     "/",
+		Expires:  expiresAt,
+		HttpOnly: true,
+		Secure:   h.cfg.Auth.CookieSecure,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if token == "" {
+		cookie.MaxAge = -1
+	}
+	http.SetCookie(w, cookie)
+}
@@ -0,0 +1,200 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"timetracker/api/model"
+	"timetracker/errorutil"
+	"timetracker/internal/oidc"
+)
+
+// oidcLoginTTL is how long a user may take at the identity provider before the login expires.
+const oidcLoginTTL = 10 * time.Minute
+
+var (
+	errOIDCDisabled = errorutil.New("oidc login is not enabled")
+	errLoginFailed  = errorutil.New("login failed")
+)
+
+// BeginOIDCLoginService stores a new login request and returns the authorization URL to send the
+// user agent to, together with the state that the browser has to present again in the callback.
+func (s *service) BeginOIDCLoginService(ctx context.Context) (string, string, error) {
+	if s.oidc == nil {
+		return "", "", errOIDCDisabled
+	}
+
+	state, err := oidc.RandomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := oidc.RandomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL, err := s.oidc.AuthCodeURL(ctx, state, nonce, challenge)
+	if err != nil {
+		return "", "", errorutil.Wrap(err, "Failed to build authorization URL")
+	}
+
+	err = s.repo.SaveOIDCLoginRequest(ctx, model.OIDCLoginRequest{
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(oidcLoginTTL),
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return authURL, state, nil
+}
+
+// CompleteOIDCLoginService finishes the authorization code flow: it exchanges the code,
+// verifies the ID token, provisions the user by issuer and subject and issues an API session. browserState is
+// the state the browser kept from the start of the login; it has to match state, otherwise an
+// attacker could have the browser complete a login the attacker started.
+// Every error caused by the login itself wraps errLoginFailed.
+func (s *service) CompleteOIDCLoginService(ctx context.Context, state, browserState, code string) (*model.LoginResponse, error) {
+	if s.oidc == nil {
+		return nil, errOIDCDisabled
+	}
+	if subtle.ConstantTimeCompare([]byte(state), []byte(browserState)) != 1 {
+		return nil, fmt.Errorf("%w: state was not issued to this browser", errLoginFailed)
+	}
+
+	loginRequest, err := s.repo.ConsumeOIDCLoginRequest(ctx, state)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, fmt.Errorf("%w: unknown or expired state", errLoginFailed)
+		}
+		return nil, err
+	}
+
+	token, err := s.oidc.Exchange(ctx, code, loginRequest.CodeVerifier)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errLoginFailed, err)
+	}
+
+	claims, err := s.oidc.VerifyIDToken(ctx, token.IDToken, loginRequest.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errLoginFailed, err)
+	}
+
+	identity, err := s.identityFromClaims(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.UpsertOIDCUser(ctx, *identity)
+	if errors.Is(err, errOIDCIdentityConflict) {
+		return nil, fmt.Errorf("%w: %v", errLoginFailed, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.createSession(ctx, user)
+}
+
+func (s *service) identityFromClaims(claims *oidc.Claims) (*model.OIDCIdentity, error) {
+	email := strings.ToLower(strings.TrimSpace(claims.Email))
+	if email == "" {
+		return nil, fmt.Errorf("%w: id_token has no email claim", errLoginFailed)
+	}
+	if !claims.EmailVerified {
+		return nil, fmt.Errorf("%w: email %s is not verified", errLoginFailed, email)
+	}
+
+	if domains := s.cfg.OIDC.AllowedEmailDomains; len(domains) > 0 {
+		_, domain, _ := strings.Cut(email, "@")
+		if !slices.ContainsFunc(domains, func(d string) bool { return strings.EqualFold(d, domain) }) {
+			return nil, fmt.Errorf("%w: email domain %s is not allowed", errLoginFailed, domain)
+		}
+	}
+
+	role := model.RoleUser
+	if slices.ContainsFunc(s.cfg.Auth.AdminEmails, func(e string) bool { return strings.EqualFold(e, email) }) {
+		role = model.RoleAdmin
+	}
+
+	return &model.OIDCIdentity{
+		Issuer:  claims.Issuer,
+		Subject: claims.Subject,
+		Email:   email,
+		Name:    claims.Name,
+		Role:    role,
+	}, nil
+}
+
+func (s *service) createSession(ctx context.Context, user *model.User) (*model.LoginResponse, error) {
+	token, err := newSessionToken()
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := time.Duration(s.cfg.Auth.SessionTTLMinutes) * time.Minute
+	if ttl <= 0 {
+		ttl = 12 * time.Hour
+	}
+
+	// Users with two-factor authentication get a session that only unlocks once the second factor is verified.
+	session, err := s.repo.CreateSession(ctx, user.ID, hashToken(token), time.Now().Add(ttl), user.TOTPEnabled)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.LoginResponse{
+		Token:       token,
+		TokenType:   "Bearer",
+		ExpiresAt:   session.ExpiresAt,
+		MFARequired: session.MFAPending,
+		User:        *user,
+	}, nil
+}
+
+func (s *service) AuthenticateService(ctx context.Context, token string) (*model.Session, *model.User, error) {
+	ctx, span := tracer.Start(ctx, "service.AuthenticateService")
+	defer span.End()
+
+	return s.repo.GetSessionUser(ctx, hashToken(token))
+}
+
+func (s *service) LogoutService(ctx context.Context, token string) error {
+	ctx, span := tracer.Start(ctx, "service.LogoutService")
+	defer span.End()
+
+	return s.repo.RevokeSession(ctx, hashToken(token))
+}
+
+func newSessionToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", errorutil.Wrap(err, "Failed to generate session token")
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken is what gets stored for session tokens, so a database leak does not leak live sessions.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,111 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+	"timetracker/api/model"
+	"timetracker/internal/config"
+	"timetracker/internal/oidc"
+	"timetracker/logger"
+)
+
+func TestIdentityFromClaims(t *testing.T) {
+	log := logger.NewLogger("auth", filepath.Join(t.TempDir(), "api.log"))
+	cfg := &config.Config{
+		Auth: config.AuthConfig{AdminEmails: []string{"Boss@Example.com"}},
+		OIDC: config.OIDCConfig{AllowedEmailDomains: []string{"example.com"}},
+	}
+	s := Service(Repository(nil, log), cfg)
+
+	tests := []struct {
+		name   string
+		claims oidc.Claims
+		role   string
+		failed bool
+	}{
+		{"verified user", oidc.Claims{Email: "Ann@Example.com", EmailVerified: true}, model.RoleUser, false},
+		{"verified admin", oidc.Claims{Email: "boss@example.com", EmailVerified: true}, model.RoleAdmin, false},
+		{"unverified email", oidc.Claims{Email: "boss@example.com"}, "", true},
+		{"no email", oidc.Claims{EmailVerified: true}, "", true},
+		{"other domain", oidc.Claims{Email: "ann@example.org", EmailVerified: true}, "", true},
+	}
+
+	for _, tt := range tests {
+		identity, err := s.identityFromClaims(&tt.claims)
+		if tt.failed {
+			if !errors.Is(err, errLoginFailed) {
+				t.Errorf("%s: error %v, want errLoginFailed", tt.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if identity.Role != tt.role {
+			t.Errorf("%s: role %s, want %s", tt.name, identity.Role, tt.role)
+		}
+	}
+}
+
+// TestUpsertOIDCUserDoesNotRebindAccounts signs in with a second identity that claims the email of
+// a linked account and checks that the account stays with its identity. It needs TEST_DATABASE_URL.
+func TestUpsertOIDCUserDoesNotRebindAccounts(t *testing.T) {
+	database, withDB := openTestDatabase(t)
+	if !withDB {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	log := logger.NewLogger("auth", filepath.Join(t.TempDir(), "api.log"))
+	r := Repository(database, log)
+	ctx := context.Background()
+
+	suffix := time.Now().UnixNano()
+	email := fmt.Sprintf("owner-%d@example.com", suffix)
+	owner := model.OIDCIdentity{Issuer: "auth-test", Subject: fmt.Sprintf("owner-%d", suffix), Email: email}
+	user, err := r.UpsertOIDCUser(ctx, owner)
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	for _, intruder := range []model.OIDCIdentity{
+		{Issuer: "auth-test", Subject: fmt.Sprintf("intruder-%d", suffix), Email: email, Role: model.RoleAdmin},
+		{Issuer: "other-issuer", Subject: owner.Subject, Email: email},
+	} {
+		if _, err := r.UpsertOIDCUser(ctx, intruder); !errors.Is(err, errOIDCIdentityConflict) {
+			t.Errorf("Login of %s/%s with the owner's email answered %v, want errOIDCIdentityConflict",
+				intruder.Issuer, intruder.Subject, err)
+		}
+	}
+
+	// The owner still signs in, also after the email changed at the provider.
+	owner.Email = fmt.Sprintf("renamed-%d@example.com", suffix)
+	again, err := r.UpsertOIDCUser(ctx, owner)
+	if err != nil {
+		t.Fatalf("Owner failed to sign in again: %v", err)
+	}
+	if again.ID != user.ID || again.Email != owner.Email || again.Role != model.RoleUser {
+		t.Errorf("Owner signed in as user %d %s %s, want user %d %s user", again.ID, again.Email, again.Role, user.ID, owner.Email)
+	}
+
+	// An account without identity is linked by its email on the first login.
+	var unlinkedID int
+	unlinked := model.OIDCIdentity{Issuer: "auth-test", Subject: fmt.Sprintf("unlinked-%d", suffix),
+		Email: fmt.Sprintf("unlinked-%d@example.com", suffix)}
+	if err := database.QueryRow(`INSERT INTO users (email) VALUES ($1) RETURNING id`, unlinked.Email).Scan(&unlinkedID); err != nil {
+		t.Fatalf("Failed to create unlinked user: %v", err)
+	}
+	linked, err := r.UpsertOIDCUser(ctx, unlinked)
+	if err != nil || linked.ID != unlinkedID {
+		t.Errorf("First login of an unlinked account answered %v, %v, want user %d", linked, err, unlinkedID)
+	}
+}
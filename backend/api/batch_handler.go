@@ -0,0 +1,255 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"timetracker/api/model"
+)
+
+// parseTrackerOperation decodes and validates the data of a batch operation with the same rules as
+// the matching single request. It returns the validation errors when the operation is invalid.
+func (h *handler) parseTrackerOperation(req *http.Request, op model.BatchOperation) (*trackerOperation, []model.FieldError) {
+	parsed := &trackerOperation{op: op.Op, id: op.ID}
+	if op.Version != nil {
+		parsed.versions = []int{*op.Version}
+	}
+
+	if errs := validateStruct(&op); len(errs) > 0 {
+		return nil, errs
+	}
+	if op.Op != model.BatchOpCreate && op.ID <= 0 {
+		return nil, []model.FieldError{fieldError("id", model.FieldErrorOutOfRange, "id must be a positive integer")}
+	}
+
+	decode := func(v any) []model.FieldError {
+		if len(bytes.TrimSpace(op.Data)) == 0 {
+			return []model.FieldError{fieldError("data", model.FieldErrorRequired, "data is required")}
+		}
+		if err := json.Unmarshal(op.Data, v); err != nil {
+			return []model.FieldError{fieldError("data", model.FieldErrorInvalid, "data must be a valid JSON object")}
+		}
+		return nil
+	}
+
+	switch op.Op {
+	case model.BatchOpCreate:
+		parsed.create = &model.CreateTrackerRequest{}
+		if errs := decode(parsed.create); errs != nil {
+			return nil, errs
+		}
+		if user, ok := verifiedUserFromContext(req.Context()); ok {
+			parsed.create.UserID = &user.ID
+		}
+		return parsed, prefixFieldErrors("data", validateStruct(parsed.create))
+	case model.BatchOpUpdate:
+		parsed.replace = &model.UpdateTrackerRequest{}
+		if errs := decode(parsed.replace); errs != nil {
+			return nil, errs
+		}
+		return parsed, prefixFieldErrors("data", validateStruct(parsed.replace))
+	case model.BatchOpPatch:
+		parsed.patch = &model.TrackerPatch{}
+		if errs := decode(parsed.patch); errs != nil {
+			return nil, errs
+		}
+		return parsed, prefixFieldErrors("data", h.validateTrackerPatch(parsed.patch))
+	default:
+		// delete, the op was validated above.
+		return parsed, nil
+	}
+}
+
+// trackerOperationError maps the error of a batch operation to the status and code the matching
+// single request would answer with.
+func trackerOperationError(id int, err error) (int, *model.OperationError) {
+	switch {
+	case errors.Is(err, errVersionMismatch):
+		return http.StatusPreconditionFailed, &model.OperationError{
+			Code:    "PRECONDITION_FAILED",
+			Message: "The tracker was modified since the given version",
+		}
+	case errors.Is(err, errPatchConflict):
+		return http.StatusConflict, &model.OperationError{
+			Code:    "CONFLICT",
+			Message: "The tracker kept changing while the patch was applied, retry it",
+		}
+	case errors.Is(err, errInvalidPatch):
+		return http.StatusBadRequest, &model.OperationError{
+			Code:    "VALIDATION_ERROR",
+			Message: "end_time cannot be before start_time",
+			Errors: []model.FieldError{
+				fieldError("end_time", model.FieldErrorOutOfOrder, "end_time cannot be before start_time"),
+			},
+		}
+	case strings.Contains(err.Error(), "not found"):
+		return http.StatusNotFound, &model.OperationError{
+			Code:    "NOT_FOUND",
+			Message: fmt.Sprintf("No tracker exists with ID %d", id),
+		}
+	default:
+		return http.StatusInternalServerError, &model.OperationError{
+			Code:    "BATCH_OPERATION_ERROR",
+			Message: "An error occurred while writing the tracker to database",
+		}
+	}
+}
+
+// BatchTrackersHandler executes a list of create, update, patch and delete operations in one
+// database transaction. It expects a JSON payload containing:
+//   - mode: string (optional, "atomic" (default) or "best_effort")
+//   - operations: array (required, 1-100 operations)
+//
+// Each operation contains:
+//   - op: string (required, create, update, patch or delete)
+//   - id: integer (required for update, patch and delete)
+//   - version: integer (optional, makes update, patch and delete conditional like If-Match)
+//   - data: object (the body of the matching single request, required for create, update and patch)
+//
+// In atomic mode either all operations are committed or none, and invalid operations prevent the
+// whole batch from running. In best_effort mode every valid operation that succeeds is committed.
+// The results array holds one entry per operation, in order, with the status code the single
+// request would have returned. Operations that were rolled back or never ran report 424.
+//
+// Returns:
+//   - 200 OK: All operations succeeded and were committed
+//   - 207 Multi-Status: At least one operation failed, see committed and results
+//   - 400 Bad Request: Invalid JSON payload, mode, or number of operations
+//   - 500 Internal Server Error: The transaction could not be committed
+func (h *handler) BatchTrackersHandler(w http.ResponseWriter, req *http.Request) {
+	h.log(req).Infof("BatchTrackersHandler: Processing request from %s", req.RemoteAddr)
+
+	var request model.BatchRequest
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		h.log(req).Warnf("BatchTrackersHandler: Failed to decode JSON - %v", err)
+		h.sendErrorResponse(w, req, http.StatusBadRequest,
+			"Invalid JSON payload",
+			"Request body must be valid JSON matching BatchRequest schema",
+			"INVALID_JSON")
+		return
+	}
+
+	if validationErrors := validateStruct(&request); len(validationErrors) > 0 {
+		h.log(req).Warnf("BatchTrackersHandler: Validation failed - %s", fieldErrorMessages(validationErrors))
+		h.sendValidationErrorResponse(w, req, validationErrors)
+		return
+	}
+	if request.Mode == "" {
+		request.Mode = model.BatchModeAtomic
+	}
+	atomic := request.Mode == model.BatchModeAtomic
+
+	results := make([]model.BatchOperationResult, len(request.Operations))
+	ops := make([]trackerOperation, 0, len(request.Operations))
+	opIndexes := make([]int, 0, len(request.Operations))
+
+	for i, op := range request.Operations {
+		results[i] = model.BatchOperationResult{Index: i, Op: op.Op, ID: op.ID}
+
+		parsed, validationErrors := h.parseTrackerOperation(req, op)
+		if len(validationErrors) > 0 {
+			results[i].Status = http.StatusBadRequest
+			results[i].Error = &model.OperationError{
+				Code:    "VALIDATION_ERROR",
+				Message: fieldErrorMessages(validationErrors),
+				Errors:  validationErrors,
+			}
+			continue
+		}
+		ops = append(ops, *parsed)
+		opIndexes = append(opIndexes, i)
+	}
+
+	invalid := len(ops) < len(request.Operations)
+	committed := false
+	var opResults []trackerOperationResult
+
+	if !(atomic && invalid) && len(ops) > 0 {
+		executed, ok, err := h.service.BatchTrackersService(req.Context(), ops, atomic)
+		if err != nil {
+			h.log(req).Errorf("BatchTrackersHandler: Service error - %v", err)
+			h.sendErrorResponse(w, req, http.StatusInternalServerError,
+				"Failed to execute batch",
+				"An error occurred while committing the batch to database",
+				"BATCH_ERROR")
+			return
+		}
+		committed = ok
+		opResults = executed
+	}
+
+	allSucceeded := batchOperationResults(results, ops, opIndexes, opResults, committed)
+
+	status := http.StatusOK
+	if !allSucceeded {
+		status = http.StatusMultiStatus
+	}
+
+	h.log(req).Infof("BatchTrackersHandler: Processed %d operations in %s mode, committed: %t",
+		len(results), request.Mode, committed)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(model.BatchResponse{
+		Mode:      request.Mode,
+		Committed: committed,
+		Results:   results,
+	})
+}
+
+// batchOperationResults records the outcome of the executed operations in results, opResults[j]
+// being the one of ops[j] at results[opIndexes[j]]. Operations that succeeded but
+// were rolled back, and those that never ran, report 424. It returns whether every operation
+// succeeded and was committed.
+func batchOperationResults(results []model.BatchOperationResult, ops []trackerOperation, opIndexes []int, opResults []trackerOperationResult, committed bool) bool {
+	for j, opResult := range opResults {
+		result := &results[opIndexes[j]]
+		switch {
+		case !opResult.executed:
+			// Left for the not executed case below.
+		case opResult.err != nil:
+			result.Status, result.Error = trackerOperationError(ops[j].id, opResult.err)
+			result.Tracker = opResult.tracker
+		case !committed:
+			result.Status = http.StatusFailedDependency
+			result.Error = &model.OperationError{
+				Code:    "ROLLED_BACK",
+				Message: "The operation succeeded but was rolled back because another operation failed",
+			}
+		default:
+			result.Status = http.StatusOK
+			result.Tracker = opResult.tracker
+			if opResult.tracker != nil {
+				result.ID = opResult.tracker.ID
+			}
+			if ops[j].op == model.BatchOpCreate {
+				result.Status = http.StatusCreated
+			} else if ops[j].op == model.BatchOpDelete {
+				result.Status = http.StatusNoContent
+			}
+		}
+	}
+
+	allSucceeded := committed
+	for i := range results {
+		if results[i].Status == 0 {
+			results[i].Status = http.StatusFailedDependency
+			results[i].Error = &model.OperationError{
+				Code:    "NOT_EXECUTED",
+				Message: "The operation was not executed because another operation failed",
+			}
+		}
+		if results[i].Status >= 300 {
+			allSucceeded = false
+		}
+	}
+	return allSucceeded
+}
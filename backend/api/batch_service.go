@@ -0,0 +1,98 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+package api
+
+import (
+	"context"
+	"errors"
+	"timetracker/api/model"
+	"timetracker/errorutil"
+)
+
+// errBatchAborted rolls back the transaction of an atomic batch after one operation failed.
+var errBatchAborted = errorutil.New("batch aborted")
+
+// trackerOperation is a validated batch operation. Exactly one of create, replace and patch is set
+// for the matching op, a delete has none.
+type trackerOperation struct {
+	op       string
+	id       int
+	versions []int
+	create   *model.CreateTrackerRequest
+	replace  *model.UpdateTrackerRequest
+	patch    *model.TrackerPatch
+}
+
+type trackerOperationResult struct {
+	tracker  *model.Tracker
+	err      error
+	executed bool
+}
+
+// BatchTrackersService runs all operations in one transaction. In atomic mode the first failing
+// operation rolls back the whole batch. Otherwise each operation runs in its own savepoint, so a
+// failure only undoes that operation and the others are committed.
+// The returned error is only set when the transaction itself failed.
+func (s *service) BatchTrackersService(ctx context.Context, ops []trackerOperation, atomic bool) ([]trackerOperationResult, bool, error) {
+	ctx, span := tracer.Start(ctx, "service.BatchTrackersService")
+	defer span.End()
+
+	results := make([]trackerOperationResult, len(ops))
+
+	err := s.repo.InTx(ctx, func(tx *repository) error {
+		for i, op := range ops {
+			if atomic {
+				results[i] = executeTrackerOperation(ctx, tx, op)
+				if results[i].err != nil {
+					return errBatchAborted
+				}
+				continue
+			}
+
+			err := tx.Savepoint(ctx, "batch_operation", func() error {
+				results[i] = executeTrackerOperation(ctx, tx, op)
+				return results[i].err
+			})
+			if err != nil && err != results[i].err {
+				// The savepoint itself failed, which leaves the transaction unusable.
+				return err
+			}
+		}
+		return nil
+	})
+
+	if errors.Is(err, errBatchAborted) {
+		return results, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return results, true, nil
+}
+
+func executeTrackerOperation(ctx context.Context, repo *repository, op trackerOperation) trackerOperationResult {
+	result := trackerOperationResult{executed: true}
+
+	switch op.op {
+	case model.BatchOpCreate:
+		result.tracker, result.err = repo.CreateTracker(ctx, *op.create)
+	case model.BatchOpUpdate:
+		result.tracker, result.err = replaceTracker(ctx, repo, op.id, *op.replace, op.versions)
+	case model.BatchOpPatch:
+		result.tracker, result.err = patchTracker(ctx, repo, op.id, *op.patch, op.versions)
+	case model.BatchOpDelete:
+		result.err = repo.DeleteTracker(ctx, op.id, op.versions)
+	}
+
+	// Like the 412 of a single request, a version mismatch carries the current tracker.
+	if errors.Is(result.err, errVersionMismatch) {
+		if current, err := repo.GetTrackerByID(ctx, op.id); err == nil {
+			result.tracker = current
+		}
+	}
+
+	return result
+}
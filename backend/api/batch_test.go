@@ -0,0 +1,161 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"timetracker/api/model"
+	"timetracker/errorutil"
+	"timetracker/internal/config"
+	"timetracker/logger"
+)
+
+func TestTrackerOperationError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"version mismatch", errVersionMismatch, http.StatusPreconditionFailed, "PRECONDITION_FAILED"},
+		{"invalid patch", fmt.Errorf("%w: end_time cannot be before start_time", errInvalidPatch), http.StatusBadRequest, "VALIDATION_ERROR"},
+		{"patch conflict", fmt.Errorf("%w: 3 attempts", errPatchConflict), http.StatusConflict, "CONFLICT"},
+		{"not found", errorutil.New("tracker with ID 42 not found"), http.StatusNotFound, "NOT_FOUND"},
+		{"database", errorutil.New("connection refused"), http.StatusInternalServerError, "BATCH_OPERATION_ERROR"},
+	}
+
+	for _, tt := range tests {
+		status, opErr := trackerOperationError(42, tt.err)
+		if status != tt.status || opErr.Code != tt.code {
+			t.Errorf("%s: %d %s, want %d %s", tt.name, status, opErr.Code, tt.status, tt.code)
+		}
+	}
+}
+
+func TestBatchOperationResults(t *testing.T) {
+	created := &model.Tracker{ID: 7}
+	current := &model.Tracker{ID: 42, Version: 5}
+	ops := []trackerOperation{
+		{op: model.BatchOpCreate},
+		{op: model.BatchOpPatch, id: 42},
+		{op: model.BatchOpDelete, id: 43},
+	}
+
+	tests := []struct {
+		name      string
+		opResults []trackerOperationResult
+		committed bool
+		statuses  []int
+		codes     []string
+		succeeded bool
+	}{
+		{"all committed", []trackerOperationResult{
+			{tracker: created, executed: true},
+			{tracker: current, executed: true},
+			{executed: true},
+		}, true, []int{201, 200, 204}, []string{"", "", ""}, true},
+		{"atomic rollback after a failure", []trackerOperationResult{
+			{tracker: created, executed: true},
+			{tracker: current, err: errVersionMismatch, executed: true},
+			{},
+		}, false, []int{424, 412, 424}, []string{"ROLLED_BACK", "PRECONDITION_FAILED", "NOT_EXECUTED"}, false},
+		{"best effort keeps the others", []trackerOperationResult{
+			{tracker: created, executed: true},
+			{err: errorutil.New("tracker with ID 42 not found"), executed: true},
+			{executed: true},
+		}, true, []int{201, 404, 204}, []string{"", "NOT_FOUND", ""}, false},
+	}
+
+	for _, tt := range tests {
+		results := make([]model.BatchOperationResult, len(ops))
+		succeeded := batchOperationResults(results, ops, []int{0, 1, 2}, tt.opResults, tt.committed)
+
+		if succeeded != tt.succeeded {
+			t.Errorf("%s: succeeded %v, want %v", tt.name, succeeded, tt.succeeded)
+		}
+		for i, result := range results {
+			code := ""
+			if result.Error != nil {
+				code = result.Error.Code
+			}
+			if result.Status != tt.statuses[i] || code != tt.codes[i] {
+				t.Errorf("%s: operation %d reports %d %q, want %d %q", tt.name, i, result.Status, code, tt.statuses[i], tt.codes[i])
+			}
+		}
+	}
+
+	// A version mismatch carries the current tracker, like the 412 of a single request.
+	results := make([]model.BatchOperationResult, 1)
+	batchOperationResults(results, ops[1:2], []int{0}, []trackerOperationResult{{tracker: current, err: errVersionMismatch, executed: true}}, false)
+	if results[0].Tracker != current {
+		t.Error("Version mismatch does not carry the current tracker")
+	}
+}
+
+// TestBatchTrackersHandlerWithoutExecution covers the batches answered before the database is
+// touched: the size limit, and atomic batches with an invalid operation.
+func TestBatchTrackersHandlerWithoutExecution(t *testing.T) {
+	log := logger.NewLogger("batch", filepath.Join(t.TempDir(), "api.log"))
+	cfg := &config.Config{}
+	h := Handler(Service(Repository(nil, log), cfg), log, cfg)
+
+	operations := func(n int) string {
+		ops := make([]string, n)
+		for i := range ops {
+			ops[i] = `{"op": "delete", "id": 1}`
+		}
+		return strings.Join(ops, ",")
+	}
+
+	tests := []struct {
+		name     string
+		body     string
+		status   int
+		statuses []int
+	}{
+		{"no operations", `{"operations": []}`, http.StatusBadRequest, nil},
+		{"above the limit", `{"operations": [` + operations(101) + `]}`, http.StatusBadRequest, nil},
+		{"invalid mode", `{"mode": "eventually", "operations": [` + operations(1) + `]}`, http.StatusBadRequest, nil},
+		{"atomic with an invalid operation", `{"operations": [
+			{"op": "create", "data": {"task": "Review", "start_time": "2025-10-09T09:00:00Z"}},
+			{"op": "create", "data": {"start_time": "2025-10-09T09:00:00Z"}},
+			{"op": "patch"}
+		]}`, http.StatusMultiStatus, []int{424, 400, 400}},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/v1/trackers/batch", strings.NewReader(tt.body))
+		w := httptest.NewRecorder()
+		h.BatchTrackersHandler(w, req)
+
+		if w.Code != tt.status {
+			t.Errorf("%s: answered %d, want %d: %s", tt.name, w.Code, tt.status, w.Body)
+			continue
+		}
+		if tt.statuses == nil {
+			continue
+		}
+		var response model.BatchResponse
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if response.Committed {
+			t.Errorf("%s: reports committed", tt.name)
+		}
+		for i, result := range response.Results {
+			if result.Status != tt.statuses[i] {
+				t.Errorf("%s: operation %d reports %d, want %d", tt.name, i, result.Status, tt.statuses[i])
+			}
+		}
+	}
+}
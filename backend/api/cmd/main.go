@@ -6,30 +6,152 @@
 package main
 
 import (
-	"net/http"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"timetracker/api"
 	"timetracker/db"
+	"timetracker/internal/config"
 	"timetracker/logger"
+
+	"google.golang.org/grpc"
 )
 
 func main() {
-	logger := logger.NewLogger("api", "./api.log")
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		logger.NewLogger("api", "./api.log").Errorf("Failed to load config: %v", err)
+		return
+	}
+
+	logger, err := logger.NewFromConfig("api", cfg.Log)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to set up logging: %v\n", err)
+		return
+	}
+	defer logger.Close()
+
+	// Spans are flushed at the end of the shutdown, after the workers finished their last ones.
+	shutdownTracing := func(context.Context) error { return nil }
+	if cfg.Tracing.Enabled {
+		if shutdownTracing, err = api.SetupTracing(cfg); err != nil {
+			logger.Errorf("Failed to set up tracing, running without: %v", err)
+			shutdownTracing = func(context.Context) error { return nil }
+		}
+	}
 
 	pgDB := db.Init(logger)
 
 	repo := api.Repository(pgDB.GetDB(), logger)
 
-	service := api.Service(repo)
+	service := api.Service(repo, cfg)
 
-	handler := api.Handler(service, logger)
+	handler := api.Handler(service, logger, cfg)
 
 	defer pgDB.CloseDB()
 
-	router := api.Router(logger, handler)
+	// SIGINT or SIGTERM starts the shutdown; a second one kills the process right away.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// The background workers stop with ctx and are waited for before the database closes.
+	var workers sync.WaitGroup
+
+	// The event stream and the live timer socket both follow the changes of all instances.
+	if cfg.Events.Enabled || cfg.WebSocket.Enabled {
+		listener, err := pgDB.Listen(api.TrackerEventsChannel, logger)
+		if err != nil {
+			logger.Errorf("Failed to listen for tracker events: %v", err)
+		} else {
+			defer listener.Close()
+			workers.Add(1)
+			go func() {
+				defer workers.Done()
+				service.ListenTrackerEvents(ctx, listener)
+			}()
+		}
+	}
+
+	if cfg.WebSocket.Enabled {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			handler.RunLiveTimers(ctx)
+		}()
+	}
+
+	if cfg.Webhooks.Enabled {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			service.DispatchWebhooks(ctx)
+		}()
+	}
+
+	var grpcServer *grpc.Server
+	if cfg.GRPC.Enabled {
+		lis, err := net.Listen("tcp", ":"+cfg.GRPC.Port)
+		if err != nil {
+			logger.Errorf("Could not listen for gRPC on :%s: %v", cfg.GRPC.Port, err)
+		} else {
+			grpcServer = api.GRPCServer(handler)
+
+			go func() {
+				logger.Infof("Starting gRPC server on :%s", cfg.GRPC.Port)
+				if err := grpcServer.Serve(lis); err != nil {
+					logger.Errorf("gRPC server stopped: %v", err)
+				}
+			}()
+		}
+	}
+
+	router := api.Router(logger, handler, cfg)
+	server := api.HTTPServer(cfg, router.SetRoutes())
+
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Infof("Starting server on %s", server.Addr)
+		serverErr <- server.ListenAndServe()
+	}()
 
-	logger.Infof("Starting server on :8080")
+	select {
+	case err := <-serverErr:
+		logger.Errorf("Could not start server: %v", err)
+	case <-ctx.Done():
+	}
+	stop()
+
+	timeout := api.ShutdownTimeout(cfg)
+	logger.Infof("Shutting down, waiting up to %s for requests in flight", timeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Warnf("Requests still in flight after %s, closing their connections: %v", timeout, err)
+		server.Close()
+	}
+
+	if grpcServer != nil {
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-shutdownCtx.Done():
+			logger.Warnf("gRPC calls still in flight after %s, cancelling them", timeout)
+			grpcServer.Stop()
+		}
+	}
 
-	if err := http.ListenAndServe(":8080", router.SetRoutes()); err != nil {
-		logger.Errorf("Could not start server: %s\n", err.Error())
+	workers.Wait()
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Warnf("Failed to flush traces: %v", err)
 	}
+	logger.Infof("Server stopped, closing database")
 }
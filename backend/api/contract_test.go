@@ -0,0 +1,413 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"timetracker/api/model"
+	"timetracker/internal/config"
+	"timetracker/logger"
+	"timetracker/migrate"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+// The contract tests send requests through the whole middleware chain of SetRoutes and validate
+// each request and response against the embedded OpenAPI document, so a handler and its
+// documentation cannot drift apart.
+//
+// Without TEST_DATABASE_URL the database is unreachable: the routes answer with their error
+// responses, which still have to match the document. With TEST_DATABASE_URL pointing to an empty
+// Postgres database, it is migrated and seeded and every case must answer with its status.
+
+// contractCase is one request of the contract tests. Path and header values may reference the
+// seeded fixtures as {tracker}, {deleted}, {user}, {share}, {share_token}, {webhook} and
+// {deleted_webhook}.
+type contractCase struct {
+	name   string
+	method string
+	path   string
+	body   string
+	header map[string]string
+	// token is the session the request is sent with: "", "user" or "logout".
+	token string
+	// status is the expected status when a database is available.
+	status int
+	// invalid marks requests that break the document on purpose, e.g. to test a validation error.
+	invalid bool
+	// stream marks responses that stay open, the request is cancelled after a short time.
+	stream bool
+}
+
+var contractCases = []contractCase{
+	{name: "list trackers", method: "GET", path: "/v1/trackers", status: 200},
+	{name: "create tracker", method: "POST", path: "/v1/trackers",
+		body:   `{"task":"Write contract tests","project":"timetracker","start_time":"2025-10-11T09:00:00Z","end_time":"2025-10-11T10:00:00Z"}`,
+		status: 201},
+	{name: "create tracker owned by the session user", method: "POST", path: "/v1/trackers", token: "user",
+		body: `{"task":"Owned tracker","start_time":"2025-10-11T09:00:00Z"}`, status: 201},
+	{name: "create invalid tracker", method: "POST", path: "/v1/trackers",
+		body: `{"task":"","start_time":"2025-10-11T09:00:00Z","end_time":"2025-10-11T08:00:00Z"}`, status: 400, invalid: true},
+	{name: "batch", method: "POST", path: "/v1/trackers/batch",
+		body:   `{"mode":"best_effort","operations":[{"op":"create","data":{"task":"Batched","start_time":"2025-10-11T09:00:00Z"}},{"op":"patch","id":{tracker},"data":{"project":null}}]}`,
+		status: 200},
+	{name: "batch with failed operation", method: "POST", path: "/v1/trackers/batch",
+		body: `{"mode":"best_effort","operations":[{"op":"delete","id":2147483647}]}`, status: 207},
+	{name: "event stream", method: "GET", path: "/v1/trackers/events", status: 200, stream: true},
+	{name: "live timers without session", method: "GET", path: "/v1/trackers/live", status: 401},
+	{name: "live timers without handshake", method: "GET", path: "/v1/trackers/live", token: "user", status: 400},
+	{name: "get tracker", method: "GET", path: "/v1/trackers/{tracker}", status: 200},
+	{name: "get missing tracker", method: "GET", path: "/v1/trackers/2147483647", status: 404},
+	{name: "replace tracker", method: "PUT", path: "/v1/trackers/{tracker}",
+		body: `{"task":"Replaced","start_time":"2025-10-11T09:00:00Z","end_time":"2025-10-11T11:00:00Z"}`, status: 200},
+	{name: "replace tracker with stale If-Match", method: "PUT", path: "/v1/trackers/{tracker}",
+		header: map[string]string{"If-Match": `"999"`},
+		body:   `{"task":"Stale","start_time":"2025-10-11T09:00:00Z"}`, status: 412},
+	{name: "patch tracker", method: "PATCH", path: "/v1/trackers/{tracker}",
+		header: map[string]string{"Content-Type": "application/merge-patch+json"},
+		body:   `{"project":"contract","end_time":null}`, status: 200},
+	{name: "patch tracker without fields", method: "PATCH", path: "/v1/trackers/{tracker}",
+		header: map[string]string{"Content-Type": "application/merge-patch+json"},
+		body:   `{}`, status: 400, invalid: true},
+	{name: "delete tracker", method: "DELETE", path: "/v1/trackers/{deleted}", status: 204},
+	{name: "oidc login", method: "GET", path: "/v1/auth/oidc/login", status: 404},
+	{name: "oidc callback", method: "GET", path: "/v1/auth/oidc/callback?code=c&state=s", status: 404},
+	{name: "current user", method: "GET", path: "/v1/auth/me", token: "user", status: 200},
+	{name: "current user without session", method: "GET", path: "/v1/auth/me", status: 401},
+	{name: "enroll two-factor", method: "POST", path: "/v1/auth/2fa/enroll", token: "user", status: 200},
+	{name: "regenerate recovery codes before confirming", method: "POST", path: "/v1/auth/2fa/recovery-codes",
+		token: "user", body: `{"code":"000000"}`, status: 409},
+	{name: "confirm two-factor with wrong code", method: "POST", path: "/v1/auth/2fa/confirm",
+		token: "user", body: `{"code":"000000"}`, status: 401},
+	{name: "verify without pending second factor", method: "POST", path: "/v1/auth/2fa/verify",
+		token: "user", body: `{"code":"000000"}`, status: 409},
+	{name: "reset two-factor of a user", method: "DELETE", path: "/v1/admin/users/{user}/2fa", token: "user", status: 204},
+	{name: "create share link", method: "POST", path: "/v1/share-links", token: "user",
+		body: `{"project":"timetracker","expires_in_hours":1}`, status: 201},
+	{name: "list share links", method: "GET", path: "/v1/share-links", token: "user", status: 200},
+	{name: "shared report", method: "GET", path: "/v1/share/{share_token}", status: 200},
+	{name: "shared report with invalid token", method: "GET", path: "/v1/share/invalid", status: 404},
+	{name: "revoke share link", method: "DELETE", path: "/v1/share-links/{share}", token: "user", status: 204},
+	{name: "graphql", method: "POST", path: "/v1/graphql",
+		body: `{"query":"{ trackers(limit: 5) { totalCount nodes { id task } } }"}`, status: 200},
+	{name: "graphql without query", method: "POST", path: "/v1/graphql", body: `{}`, status: 400, invalid: true},
+	{name: "pull sync changes", method: "GET", path: "/v1/sync", token: "user", status: 200},
+	{name: "push sync changes", method: "POST", path: "/v1/sync", token: "user",
+		body:   `{"changes":[{"client_id":"6f1c2d7e-9a0b-4c3d-8e6f-5a4b3c2d1e0f","op":"upsert","modified_at":"2025-10-11T12:00:00Z","data":{"task":"Offline","start_time":"2025-10-11T09:00:00Z"}}]}`,
+		status: 200},
+	{name: "create webhook", method: "POST", path: "/v1/webhooks", token: "user",
+		body: `{"url":"http://127.0.0.1:1/timetracker","event_types":["tracker.stopped"]}`, status: 201},
+	{name: "create invalid webhook", method: "POST", path: "/v1/webhooks", token: "user",
+		body: `{"url":"ftp://hooks.example.com","event_types":[]}`, status: 400, invalid: true},
+	{name: "list webhooks", method: "GET", path: "/v1/webhooks", token: "user", status: 200},
+	{name: "list webhooks without session", method: "GET", path: "/v1/webhooks", status: 401},
+	{name: "get webhook", method: "GET", path: "/v1/webhooks/{webhook}", token: "user", status: 200},
+	{name: "get missing webhook", method: "GET", path: "/v1/webhooks/2147483647", token: "user", status: 404},
+	{name: "webhook delivery log", method: "GET", path: "/v1/webhooks/{webhook}/deliveries?status=pending&limit=10",
+		token: "user", status: 200},
+	{name: "delete webhook", method: "DELETE", path: "/v1/webhooks/{deleted_webhook}", token: "user", status: 204},
+	{name: "logout", method: "POST", path: "/v1/auth/logout", token: "logout", status: 204},
+	{name: "health", method: "GET", path: "/health", status: 200},
+	{name: "openapi document", method: "GET", path: "/openapi.yaml", status: 200},
+	{name: "api docs", method: "GET", path: "/docs", status: 200},
+	{name: "metrics", method: "GET", path: "/metrics", status: 200},
+}
+
+type contractEnv struct {
+	router   *router
+	handler  http.Handler
+	doc      *openapi3.T
+	spec     routers.Router
+	withDB   bool
+	tokens   map[string]string
+	fixtures map[string]string
+}
+
+func init() {
+	openapi3filter.RegisterBodyDecoder("text/event-stream", openapi3filter.PlainBodyDecoder)
+	openapi3filter.RegisterBodyDecoder("text/html", openapi3filter.PlainBodyDecoder)
+}
+
+func newContractEnv(t *testing.T) *contractEnv {
+	t.Helper()
+
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(openAPISpec)
+	if err != nil {
+		t.Fatalf("Failed to load the OpenAPI document: %v", err)
+	}
+	if err := doc.Validate(loader.Context); err != nil {
+		t.Fatalf("The OpenAPI document is invalid: %v", err)
+	}
+	spec, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		t.Fatalf("Failed to route the OpenAPI document: %v", err)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	cfg.RateLimit.Enabled = false
+	cfg.Share.SigningSecret = "contract-test-secret"
+	cfg.Metrics.Enabled = true
+
+	log := logger.NewLogger("contract", filepath.Join(t.TempDir(), "api.log"))
+
+	env := &contractEnv{
+		doc:    doc,
+		spec:   spec,
+		tokens: map[string]string{},
+		fixtures: map[string]string{
+			"tracker": "1", "deleted": "2", "user": "1", "share": "1", "share_token": "invalid",
+			"webhook": "1", "deleted_webhook": "2",
+		},
+	}
+
+	var database *sql.DB
+	database, env.withDB = openTestDatabase(t)
+
+	repo := Repository(database, log)
+	service := Service(repo, cfg)
+	env.router = Router(log, Handler(service, log, cfg), cfg)
+	env.handler = env.router.SetRoutes()
+
+	if env.withDB {
+		env.seed(t, service)
+	} else {
+		env.tokens["user"] = "contract-user-token"
+		env.tokens["logout"] = "contract-logout-token"
+	}
+
+	return env
+}
+
+// openTestDatabase opens the Postgres database TEST_DATABASE_URL points to and migrates it. Without
+// TEST_DATABASE_URL, ok is false and the returned database is unreachable: every query fails right
+// away.
+func openTestDatabase(t *testing.T) (database *sql.DB, ok bool) {
+	t.Helper()
+
+	databaseURL := os.Getenv("TEST_DATABASE_URL")
+	ok = databaseURL != ""
+	if !ok {
+		// Nothing listens on port 1.
+		databaseURL = "host=127.0.0.1 port=1 user=test dbname=test sslmode=disable connect_timeout=1"
+	}
+
+	database, err := sql.Open("postgres", databaseURL)
+	if err != nil {
+		t.Fatalf("Failed to open the database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	if ok {
+		if err, query := migrate.Migrate(database); err != nil {
+			t.Fatalf("Failed to migrate the test database: %v\n%s", err, query)
+		}
+	}
+	return database, ok
+}
+
+// seed creates an admin with two sessions and the trackers, share link and webhooks the cases
+// refer to.
+func (env *contractEnv) seed(t *testing.T, service *service) {
+	t.Helper()
+
+	ctx := context.Background()
+	suffix := time.Now().UnixNano()
+	user, err := service.repo.UpsertOIDCUser(ctx, model.OIDCIdentity{
+		Issuer:  "contract",
+		Subject: fmt.Sprint(suffix),
+		Email:   fmt.Sprintf("contract-%d@example.com", suffix),
+		Name:    "Contract",
+		Role:    model.RoleAdmin,
+	})
+	if err != nil {
+		t.Fatalf("Failed to seed user: %v", err)
+	}
+	env.fixtures["user"] = fmt.Sprint(user.ID)
+
+	for _, name := range []string{"user", "logout"} {
+		login, err := service.createSession(ctx, user)
+		if err != nil {
+			t.Fatalf("Failed to seed session: %v", err)
+		}
+		env.tokens[name] = login.Token
+	}
+
+	for _, name := range []string{"tracker", "deleted"} {
+		tracker, err := service.CreateTrackerService(ctx, model.CreateTrackerRequest{
+			Task:      "Contract fixture",
+			StartTime: time.Date(2025, 10, 11, 9, 0, 0, 0, time.UTC),
+			UserID:    &user.ID,
+		})
+		if err != nil {
+			t.Fatalf("Failed to seed tracker: %v", err)
+		}
+		env.fixtures[name] = fmt.Sprint(tracker.ID)
+	}
+
+	link, err := service.CreateShareLinkService(ctx, user, model.CreateShareLinkRequest{ExpiresInHours: 1})
+	if err != nil {
+		t.Fatalf("Failed to seed share link: %v", err)
+	}
+	env.fixtures["share"] = fmt.Sprint(link.ID)
+	env.fixtures["share_token"] = link.URL[strings.LastIndex(link.URL, "/")+1:]
+
+	for _, name := range []string{"webhook", "deleted_webhook"} {
+		// Nothing listens on port 1, so deliveries to the webhook fail right away.
+		webhook, err := service.CreateWebhookService(ctx, user, model.CreateWebhookRequest{
+			URL:        "http://127.0.0.1:1/timetracker",
+			EventTypes: []string{model.WebhookEventTrackerStopped},
+		})
+		if err != nil {
+			t.Fatalf("Failed to seed webhook: %v", err)
+		}
+		env.fixtures[name] = fmt.Sprint(webhook.ID)
+	}
+}
+
+func (env *contractEnv) expand(s string) string {
+	for name, value := range env.fixtures {
+		s = strings.ReplaceAll(s, "{"+name+"}", value)
+	}
+	return s
+}
+
+func (env *contractEnv) newRequest(c contractCase) *http.Request {
+	var body io.Reader
+	if c.body != "" {
+		body = strings.NewReader(env.expand(c.body))
+	}
+	req := httptest.NewRequest(c.method, env.expand(c.path), body)
+	if c.body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, value := range c.header {
+		req.Header.Set(name, env.expand(value))
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+env.tokens[c.token])
+	}
+	return req
+}
+
+func TestContract(t *testing.T) {
+	env := newContractEnv(t)
+	options := &openapi3filter.Options{
+		AuthenticationFunc:    openapi3filter.NoopAuthenticationFunc,
+		IncludeResponseStatus: true,
+	}
+
+	for _, c := range contractCases {
+		t.Run(c.name, func(t *testing.T) {
+			req := env.newRequest(c)
+			route, pathParams, err := env.spec.FindRoute(req)
+			if err != nil {
+				t.Fatalf("%s %s is not in the OpenAPI document: %v", c.method, req.URL.Path, err)
+			}
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    req,
+				PathParams: pathParams,
+				Route:      route,
+				Options:    options,
+			}
+			err = openapi3filter.ValidateRequest(context.Background(), input)
+			if err != nil && !c.invalid {
+				t.Fatalf("The request does not match the OpenAPI document: %v", err)
+			}
+			if err == nil && c.invalid {
+				t.Fatalf("The request is marked invalid but matches the OpenAPI document")
+			}
+
+			serveReq := env.newRequest(c)
+			if c.stream {
+				ctx, cancel := context.WithTimeout(serveReq.Context(), 200*time.Millisecond)
+				defer cancel()
+				serveReq = serveReq.WithContext(ctx)
+			}
+			rec := httptest.NewRecorder()
+			env.handler.ServeHTTP(rec, serveReq)
+
+			if env.withDB && rec.Code != c.status {
+				t.Errorf("Status %d, want %d: %s", rec.Code, c.status, rec.Body.String())
+			}
+
+			err = openapi3filter.ValidateResponse(context.Background(), &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: input,
+				Status:                 rec.Code,
+				Header:                 rec.Header(),
+				Body:                   io.NopCloser(bytes.NewReader(rec.Body.Bytes())),
+				Options:                options,
+			})
+			if err != nil {
+				t.Errorf("The %d response does not match the OpenAPI document: %v\n%s", rec.Code, err, rec.Body.String())
+			}
+		})
+	}
+}
+
+// TestContractCoversRoutes fails when a route is added without documenting it and without a
+// contract case, or when the document lists an operation no route serves.
+func TestContractCoversRoutes(t *testing.T) {
+	env := newContractEnv(t)
+
+	var routes []route
+	for _, rt := range env.router.v1Routes() {
+		routes = append(routes, route{prefixPattern("/v1", rt.pattern), rt.handler})
+	}
+	routes = append(routes, env.router.unversionedRoutes()...)
+
+	mux := http.NewServeMux()
+	for _, rt := range routes {
+		mux.HandleFunc(rt.pattern, rt.handler)
+	}
+
+	covered := map[string]bool{}
+	for _, c := range contractCases {
+		_, pattern := mux.Handler(env.newRequest(c))
+		covered[pattern] = true
+	}
+
+	for _, rt := range routes {
+		method, path, ok := strings.Cut(rt.pattern, " ")
+		if !ok {
+			method, path = http.MethodGet, rt.pattern
+		}
+		if item := env.doc.Paths.Find(path); item == nil || item.GetOperation(method) == nil {
+			t.Errorf("%s is not in the OpenAPI document", rt.pattern)
+		}
+		if !covered[rt.pattern] {
+			t.Errorf("%s has no contract case", rt.pattern)
+		}
+	}
+
+	for path, item := range env.doc.Paths.Map() {
+		for method := range item.Operations() {
+			target := strings.NewReplacer("{id}", "1", "{token}", "t").Replace(path)
+			if _, pattern := mux.Handler(httptest.NewRequest(method, target, nil)); pattern == "" {
+				t.Errorf("The OpenAPI document lists %s %s, which no route serves", method, path)
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// cors wraps next with the CORS policy from the config. Preflight requests are answered
+// here and never reach the routes, so they are not counted by the rate limiter.
+func (r *router) cors(next http.Handler) http.Handler {
+	cfg := r.cfg.CORS
+	if !cfg.Enabled {
+		r.logger.Infof("CORS disabled")
+		return next
+	}
+
+	allowAnyOrigin := slices.Contains(cfg.AllowedOrigins, "*")
+	allowedMethods := strings.Join(cfg.AllowedMethods, ", ")
+	allowedHeaders := strings.Join(cfg.AllowedHeaders, ", ")
+	exposedHeaders := strings.Join(cfg.ExposedHeaders, ", ")
+	r.logger.Infof("CORS enabled for origins: %s", strings.Join(cfg.AllowedOrigins, ", "))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		origin := req.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+		preflight := req.Method == http.MethodOptions && req.Header.Get("Access-Control-Request-Method") != ""
+
+		if !allowAnyOrigin && !slices.Contains(cfg.AllowedOrigins, origin) {
+			if preflight {
+				r.handler.sendErrorResponse(w, req, http.StatusForbidden,
+					"Origin not allowed",
+					fmt.Sprintf("Cross-origin requests from %s are not allowed", origin),
+					"CORS_ORIGIN_NOT_ALLOWED")
+				return
+			}
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		// Credentialed requests may not use the "*" wildcard, so the origin is echoed back instead.
+		if allowAnyOrigin && !cfg.AllowCredentials {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+		if cfg.AllowCredentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if exposedHeaders != "" {
+				w.Header().Set("Access-Control-Expose-Headers", exposedHeaders)
+			}
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		w.Header().Add("Vary", "Access-Control-Request-Method")
+		w.Header().Add("Vary", "Access-Control-Request-Headers")
+		w.Header().Set("Access-Control-Allow-Methods", allowedMethods)
+		if allowedHeaders != "" {
+			w.Header().Set("Access-Control-Allow-Headers", allowedHeaders)
+		}
+		if cfg.MaxAgeSeconds > 0 {
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(cfg.MaxAgeSeconds))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,145 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"testing"
+	"timetracker/internal/config"
+	"timetracker/logger"
+)
+
+func newCORSRouter(t *testing.T, cfg *config.Config) *router {
+	t.Helper()
+
+	log := logger.NewLogger("cors", filepath.Join(t.TempDir(), "api.log"))
+	return Router(log, Handler(Service(Repository(nil, log), cfg), log, cfg), cfg)
+}
+
+func corsConfig(origins []string, credentials bool) config.CORSConfig {
+	return config.CORSConfig{
+		Enabled:          true,
+		AllowedOrigins:   origins,
+		AllowedMethods:   []string{"GET", "POST", "PATCH"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		ExposedHeaders:   []string{"ETag", "X-Request-ID"},
+		AllowCredentials: credentials,
+		MaxAgeSeconds:    600,
+	}
+}
+
+func TestCORS(t *testing.T) {
+	const dashboard = "http://localhost:5173"
+
+	tests := []struct {
+		name      string
+		cors      config.CORSConfig
+		origin    string
+		preflight bool
+		status    int
+		header    map[string]string
+	}{
+		{"preflight from allowed origin", corsConfig([]string{dashboard}, true), dashboard, true, http.StatusNoContent, map[string]string{
+			"Access-Control-Allow-Origin":      dashboard,
+			"Access-Control-Allow-Credentials": "true",
+			"Access-Control-Allow-Methods":     "GET, POST, PATCH",
+			"Access-Control-Allow-Headers":     "Content-Type, Authorization",
+			"Access-Control-Max-Age":           "600",
+			"Access-Control-Expose-Headers":    "",
+		}},
+		{"request from allowed origin", corsConfig([]string{dashboard}, true), dashboard, false, http.StatusOK, map[string]string{
+			"Access-Control-Allow-Origin":   dashboard,
+			"Access-Control-Expose-Headers": "ETag, X-Request-ID",
+			"Access-Control-Allow-Methods":  "",
+		}},
+		{"preflight from disallowed origin", corsConfig([]string{dashboard}, true), "https://evil.example", true, http.StatusForbidden, map[string]string{
+			"Access-Control-Allow-Origin":      "",
+			"Access-Control-Allow-Credentials": "",
+		}},
+		{"request from disallowed origin", corsConfig([]string{dashboard}, true), "https://evil.example", false, http.StatusOK, map[string]string{
+			"Access-Control-Allow-Origin": "",
+		}},
+		{"wildcard without credentials", corsConfig([]string{"*"}, false), "https://any.example", false, http.StatusOK, map[string]string{
+			"Access-Control-Allow-Origin":      "*",
+			"Access-Control-Allow-Credentials": "",
+		}},
+		{"wildcard with credentials echoes the origin", corsConfig([]string{"*"}, true), "https://any.example", true, http.StatusNoContent, map[string]string{
+			"Access-Control-Allow-Origin":      "https://any.example",
+			"Access-Control-Allow-Credentials": "true",
+		}},
+		{"no origin", corsConfig([]string{dashboard}, true), "", false, http.StatusOK, map[string]string{
+			"Access-Control-Allow-Origin": "",
+		}},
+	}
+
+	for _, tt := range tests {
+		r := newCORSRouter(t, &config.Config{CORS: tt.cors})
+		handler := r.cors(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+		method := http.MethodGet
+		if tt.preflight {
+			method = http.MethodOptions
+		}
+		req := httptest.NewRequest(method, "/v1/trackers", nil)
+		if tt.origin != "" {
+			req.Header.Set("Origin", tt.origin)
+		}
+		if tt.preflight {
+			req.Header.Set("Access-Control-Request-Method", "PATCH")
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != tt.status {
+			t.Errorf("%s: answered %d, want %d", tt.name, w.Code, tt.status)
+		}
+		for name, want := range tt.header {
+			if got := w.Header().Get(name); got != want {
+				t.Errorf("%s: %s is %q, want %q", tt.name, name, got, want)
+			}
+		}
+		// Caches must not serve the answer for one origin to another.
+		if vary := w.Header().Values("Vary"); tt.origin != "" && !slices.Contains(vary, "Origin") {
+			t.Errorf("%s: Vary %q lacks Origin", tt.name, vary)
+		}
+	}
+}
+
+// TestCORSPreflightBeforeRateLimit checks the order of SetRoutes: preflights are answered before
+// the rate limiter and do not use up the budget of the client.
+func TestCORSPreflightBeforeRateLimit(t *testing.T) {
+	cfg := &config.Config{
+		CORS:      corsConfig([]string{"http://localhost:5173"}, true),
+		RateLimit: config.RateLimitConfig{Enabled: true, RequestsPerSecond: 0.001, Burst: 1},
+	}
+	handler := newCORSRouter(t, cfg).SetRoutes()
+
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodOptions, "/v1/trackers", nil)
+		req.Header.Set("Origin", "http://localhost:5173")
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != http.StatusNoContent || w.Header().Get("X-RateLimit-Limit") != "" {
+			t.Fatalf("Preflight %d answered %d with X-RateLimit-Limit %q, want 204 before the rate limiter",
+				i+1, w.Code, w.Header().Get("X-RateLimit-Limit"))
+		}
+	}
+
+	// The only token of the burst is still there for the first real request.
+	req := httptest.NewRequest(http.MethodGet, "/v1/unknown", nil)
+	req.Header.Set("Origin", "http://localhost:5173")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code == http.StatusTooManyRequests || w.Header().Get("X-RateLimit-Remaining") != "0" {
+		t.Errorf("Request after the preflights answered %d with X-RateLimit-Remaining %q, want it to pass with 0 left",
+			w.Code, w.Header().Get("X-RateLimit-Remaining"))
+	}
+}
@@ -0,0 +1,95 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"timetracker/api/model"
+)
+
+// trackerETag is the strong entity tag of a tracker, derived from its row version.
+func trackerETag(tracker *model.Tracker) string {
+	return fmt.Sprintf(`"%d"`, tracker.Version)
+}
+
+// ifMatchVersions parses the If-Match header into the tracker versions it accepts.
+// It returns nil when the header is absent or "*", which makes the write unconditional.
+// Weak or foreign tags can never match a strong comparison and are dropped, so a header
+// holding only those yields an empty slice and the write fails its precondition. A member
+// that is no entity tag at all is an error.
+func ifMatchVersions(req *http.Request) ([]int, error) {
+	header := strings.TrimSpace(req.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+
+	versions := []int{}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		opaque, weak := strings.CutPrefix(tag, "W/")
+		if len(opaque) < 2 || opaque[0] != '"' || opaque[len(opaque)-1] != '"' || strings.Contains(opaque[1:len(opaque)-1], `"`) {
+			return nil, fmt.Errorf("If-Match must be * or a list of entity tags such as \"3\", got %s", tag)
+		}
+		if weak {
+			continue
+		}
+		if version, err := strconv.Atoi(opaque[1 : len(opaque)-1]); err == nil {
+			versions = append(versions, version)
+		}
+	}
+	return versions, nil
+}
+
+// ifMatch returns the versions of the If-Match header, see ifMatchVersions. A malformed header
+// is answered with 400 and ok is false.
+func (h *handler) ifMatch(w http.ResponseWriter, req *http.Request) (versions []int, ok bool) {
+	versions, err := ifMatchVersions(req)
+	if err != nil {
+		h.log(req).Warnf("ifMatch: Invalid If-Match header - %v", err)
+		h.sendErrorResponse(w, req, http.StatusBadRequest,
+			"Invalid If-Match",
+			err.Error(),
+			"INVALID_IF_MATCH")
+		return nil, false
+	}
+	return versions, true
+}
+
+// sendPreconditionFailed answers a failed If-Match with 412 and the current representation,
+// so the client can merge its change and retry with the new ETag.
+func (h *handler) sendPreconditionFailed(w http.ResponseWriter, req *http.Request, id int) {
+	tracker, err := h.service.GetTrackerByIDService(req.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			h.sendErrorResponse(w, req, http.StatusNotFound,
+				"Tracker not found",
+				fmt.Sprintf("No tracker exists with ID %d", id),
+				"NOT_FOUND")
+			return
+		}
+
+		h.log(req).Errorf("sendPreconditionFailed: Failed to load tracker ID %d - %v", id, err)
+		h.sendErrorResponse(w, req, http.StatusPreconditionFailed,
+			"Precondition failed",
+			"The tracker was modified since it was last fetched",
+			"PRECONDITION_FAILED")
+		return
+	}
+
+	h.log(req).Warnf("sendPreconditionFailed: If-Match did not match tracker ID %d at version %d", id, tracker.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", trackerETag(tracker))
+	w.WriteHeader(http.StatusPreconditionFailed)
+	json.NewEncoder(w).Encode(tracker)
+}
@@ -0,0 +1,92 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"timetracker/api/model"
+	"timetracker/internal/config"
+	"timetracker/logger"
+)
+
+func TestIfMatchVersions(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    []int
+		invalid bool
+	}{
+		{"absent", "", nil, false},
+		{"any", "*", nil, false},
+		{"any with spaces", "  *  ", nil, false},
+		{"strong tag", `"3"`, []int{3}, false},
+		{"weak tag never matches", `W/"3"`, []int{}, false},
+		{"list", `"3", W/"4" ,"5"`, []int{3, 5}, false},
+		{"empty list members", `"3",,  ,"5"`, []int{3, 5}, false},
+		{"foreign tag", `"abc"`, []int{}, false},
+		{"unquoted", `3`, nil, true},
+		{"unterminated", `"3`, nil, true},
+		{"quote inside", `"3"4"`, nil, true},
+		{"malformed member of a list", `"3", 4`, nil, true},
+		{"lower case weak prefix", `w/"3"`, nil, true},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPut, "/v1/trackers/42", nil)
+		if tt.header != "" {
+			req.Header.Set("If-Match", tt.header)
+		}
+		got, err := ifMatchVersions(req)
+		if (err != nil) != tt.invalid {
+			t.Errorf("%s: error %v, want invalid %v", tt.name, err, tt.invalid)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: versions %#v, want %#v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTrackerETag(t *testing.T) {
+	tracker := &model.Tracker{Version: 7}
+	req := httptest.NewRequest(http.MethodPut, "/v1/trackers/42", nil)
+	req.Header.Set("If-Match", trackerETag(tracker))
+
+	if got, err := ifMatchVersions(req); err != nil || !reflect.DeepEqual(got, []int{7}) {
+		t.Errorf("ETag %s reads back as %v, %v", trackerETag(tracker), got, err)
+	}
+}
+
+// TestMalformedIfMatchIsRejected checks that the writes answer 400 before they touch the tracker.
+func TestMalformedIfMatchIsRejected(t *testing.T) {
+	log := logger.NewLogger("etag", filepath.Join(t.TempDir(), "api.log"))
+	cfg := &config.Config{}
+	h := Handler(Service(Repository(nil, log), cfg), log, cfg)
+
+	for name, handle := range map[string]http.HandlerFunc{
+		http.MethodPut:    h.UpdateTrackerHandler,
+		http.MethodPatch:  h.PatchTrackerHandler,
+		http.MethodDelete: h.DeleteTrackerHandler,
+	} {
+		req := httptest.NewRequest(name, "/v1/trackers/42", nil)
+		req.SetPathValue("id", "42")
+		req.Header.Set("If-Match", "3")
+		w := httptest.NewRecorder()
+		handle(w, req)
+
+		var problem model.Problem
+		json.NewDecoder(w.Body).Decode(&problem)
+		if w.Code != http.StatusBadRequest || problem.Code != "INVALID_IF_MATCH" {
+			t.Errorf("%s: answered %d %s, want 400 INVALID_IF_MATCH", name, w.Code, problem.Code)
+		}
+	}
+}
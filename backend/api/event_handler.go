@@ -0,0 +1,202 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+	"timetracker/api/model"
+)
+
+// trackerEventRetryMillis is the reconnect delay EventSource clients are told to use.
+const trackerEventRetryMillis = 3000
+
+func writeTrackerEvent(w io.Writer, event model.TrackerEvent) error {
+	_, err := fmt.Fprintf(w, "id: %d\nevent: tracker.%s\ndata: %s\n\n", event.ID, event.Type, event.Data)
+	return err
+}
+
+// lastEventID reads the id a reconnecting client resumes from. Browsers send the Last-Event-ID
+// header by themselves, the last_event_id query parameter serves clients that cannot set headers.
+func lastEventID(req *http.Request) (int64, bool, error) {
+	value := req.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = req.URL.Query().Get("last_event_id")
+	}
+	if value == "" {
+		return 0, false, nil
+	}
+
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return 0, false, fmt.Errorf("Last-Event-ID must be a non-negative integer")
+	}
+	return id, true, nil
+}
+
+// TrackerEventsHandler streams tracker changes as Server-Sent Events.
+// Every create, update and delete of a tracker is sent as a tracker.created, tracker.updated or
+// tracker.deleted event whose data is the tracker after the change, or {"id": ...} for deletions.
+// A client that reconnects with Last-Event-ID first receives the events it missed. When those were
+// already pruned it receives a resync event instead and should refetch GET /trackers. Events are
+// sent once their transaction and all older ones ended, so a change committed late is not skipped,
+// but wait for events.max_lag_seconds at most.
+// A comment line is sent as heartbeat while no events occur.
+//
+// Returns:
+//   - 200 OK: An open text/event-stream
+//   - 400 Bad Request: Invalid Last-Event-ID
+//   - 404 Not Found: The event stream is disabled in the configuration
+//   - 500 Internal Server Error: The missed events could not be loaded
+func (h *handler) TrackerEventsHandler(w http.ResponseWriter, req *http.Request) {
+	h.log(req).Infof("TrackerEventsHandler: Processing request from %s", req.RemoteAddr)
+
+	if !h.cfg.Events.Enabled {
+		h.sendErrorResponse(w, req, http.StatusNotFound,
+			"Event stream disabled",
+			"Tracker events are not enabled on this server",
+			"EVENTS_DISABLED")
+		return
+	}
+
+	lastSent, resume, err := lastEventID(req)
+	if err != nil {
+		h.sendErrorResponse(w, req, http.StatusBadRequest,
+			"Invalid Last-Event-ID",
+			err.Error(),
+			"INVALID_LAST_EVENT_ID")
+		return
+	}
+
+	// Subscribe before placing the cursor, so no event falls between replay and live stream.
+	sub := h.service.SubscribeTrackerEventsService()
+	defer h.service.UnsubscribeTrackerEventsService(sub)
+
+	cursor, resync, err := h.service.TrackerEventCursorService(req.Context(), lastSent, resume)
+	if err != nil {
+		h.log(req).Errorf("TrackerEventsHandler: Service error - %v", err)
+		h.sendErrorResponse(w, req, http.StatusInternalServerError,
+			"Failed to load events",
+			"An error occurred while loading the missed events",
+			"FETCH_ERROR")
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	// The stream outlives the read and write timeouts of the server, which are meant for requests
+	// that end; a stuck client is noticed by the failing heartbeat instead.
+	rc.SetReadDeadline(time.Time{})
+	rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", trackerEventRetryMillis)
+
+	if resync {
+		h.log(req).Warnf("TrackerEventsHandler: Events after %d were pruned, asking client to resync", lastSent)
+		fmt.Fprintf(w, "id: %d\nevent: resync\ndata: {}\n\n", cursor.id)
+	}
+
+	// sendEvents writes the committed events after the cursor page by page. Subscription events
+	// only signal that there are new ones: they arrive in commit order, while the stream is sent
+	// in the order of ListTrackerEventsAfter so a client can resume from any event id.
+	sendEvents := func() error {
+		for {
+			events, err := h.service.TrackerEventsAfterService(req.Context(), cursor, trackerEventPage)
+			if err != nil {
+				h.log(req).Errorf("TrackerEventsHandler: Failed to load events - %v", err)
+				return err
+			}
+			for _, event := range events {
+				if err := writeTrackerEvent(w, event); err != nil {
+					return err
+				}
+				cursor = cursorOf(event)
+			}
+			if err := rc.Flush(); err != nil {
+				return err
+			}
+			if len(events) < trackerEventPage {
+				return nil
+			}
+		}
+	}
+
+	if resume {
+		if err := sendEvents(); err != nil {
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		h.log(req).Errorf("TrackerEventsHandler: Streaming not supported - %v", err)
+		return
+	}
+
+	heartbeat := time.Duration(h.cfg.Events.HeartbeatSeconds) * time.Second
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	// An event of a transaction that committed while an older one is still running is held back
+	// until that one ended or events.max_lag_seconds passed, which is checked for every second.
+	newest := cursor
+	var retry <-chan time.Time
+
+	for {
+		select {
+		case <-req.Context().Done():
+			h.log(req).Infof("TrackerEventsHandler: Client %s disconnected", req.RemoteAddr)
+			return
+
+		case <-serverClosing(req.Context()):
+			// The client reconnects with Last-Event-ID and misses nothing.
+			h.log(req).Infof("TrackerEventsHandler: Closing stream of %s for shutdown", req.RemoteAddr)
+			return
+
+		case event, ok := <-sub.events:
+			if !ok {
+				h.log(req).Warnf("TrackerEventsHandler: Client %s fell behind, closing stream", req.RemoteAddr)
+				return
+			}
+			if !cursor.before(cursorOf(event)) {
+				continue
+			}
+			if newest.before(cursorOf(event)) {
+				newest = cursorOf(event)
+			}
+			if err := sendEvents(); err != nil {
+				return
+			}
+
+		case <-retry:
+			if err := sendEvents(); err != nil {
+				return
+			}
+
+		case <-ticker.C:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+
+		retry = nil
+		if cursor.before(newest) {
+			retry = time.After(time.Second)
+		}
+	}
+}
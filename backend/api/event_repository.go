@@ -0,0 +1,161 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+package api
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"time"
+	"timetracker/api/model"
+	"timetracker/errorutil"
+)
+
+const trackerEventColumns = `id, txid::TEXT, tracker_id, type, version, payload, created_at`
+
+func scanTrackerEvent(row interface{ Scan(...any) error }, event *model.TrackerEvent) error {
+	return row.Scan(&event.ID, &event.TxID, &event.TrackerID, &event.Type, &event.Version, &event.Data, &event.CreatedAt)
+}
+
+func (r *repository) GetTrackerEvent(ctx context.Context, id int64) (*model.TrackerEvent, error) {
+	ctx, span := startChildSpan(ctx, "repository.GetTrackerEvent")
+	defer span.End()
+
+	query := `
+		SELECT ` + trackerEventColumns + `
+		FROM tracker_events
+		WHERE id = $1`
+
+	var event model.TrackerEvent
+	err := scanTrackerEvent(r.db.QueryRowContext(ctx, query, id), &event)
+
+	if err == sql.ErrNoRows {
+		return nil, errorutil.New("tracker event not found")
+	}
+	if err != nil {
+		return nil, errorutil.Wrap(err, "Failed to get tracker event")
+	}
+
+	return &event, nil
+}
+
+// ListTrackerEventsAfter returns up to limit committed events after the event afterID of transaction
+// afterTxID, ordered by transaction and id. Ids are taken when an event is inserted but become visible
+// on commit, so a later id may be visible first; events of transactions from the oldest one still
+// running on are therefore held back until it ended, and a transaction that commits late can never
+// sort before an event that was already returned. The oldest running transaction may be one that
+// never touches trackers, e.g. a migration or an idle session, so events are held back for maxLag
+// at most: an event of a tracker write that stays open longer than that may sort before events
+// already returned and is skipped.
+func (r *repository) ListTrackerEventsAfter(ctx context.Context, afterTxID uint64, afterID int64, limit int, maxLag time.Duration) ([]model.TrackerEvent, error) {
+	ctx, span := startChildSpan(ctx, "repository.ListTrackerEventsAfter")
+	defer span.End()
+
+	query := `
+		SELECT ` + trackerEventColumns + `
+		FROM tracker_events
+		WHERE (txid, id) > ($1::xid8, $2)
+		  AND (txid < pg_snapshot_xmin(pg_current_snapshot()) OR created_at < NOW() - make_interval(secs => $4))
+		ORDER BY txid ASC, id ASC
+		LIMIT $3`
+
+	return r.listTrackerEvents(ctx, query, strconv.FormatUint(afterTxID, 10), afterID, limit, maxLag.Seconds())
+}
+
+// ListTrackerEventsSince returns up to limit events of transactions from horizon on with an id
+// greater than afterID, oldest first, whether or not older transactions are still running.
+func (r *repository) ListTrackerEventsSince(ctx context.Context, horizon uint64, afterID int64, limit int) ([]model.TrackerEvent, error) {
+	ctx, span := startChildSpan(ctx, "repository.ListTrackerEventsSince")
+	defer span.End()
+
+	query := `
+		SELECT ` + trackerEventColumns + `
+		FROM tracker_events
+		WHERE txid >= $1::xid8 AND id > $2
+		ORDER BY id ASC
+		LIMIT $3`
+
+	return r.listTrackerEvents(ctx, query, strconv.FormatUint(horizon, 10), afterID, limit)
+}
+
+func (r *repository) listTrackerEvents(ctx context.Context, query string, args ...any) ([]model.TrackerEvent, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errorutil.Wrap(err, "Failed to execute query")
+	}
+
+	defer rows.Close()
+
+	events := []model.TrackerEvent{}
+
+	for rows.Next() {
+		var event model.TrackerEvent
+		if err := scanTrackerEvent(rows, &event); err != nil {
+			return nil, errorutil.Wrap(err, "scanning tracker event row")
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errorutil.Wrap(err, "iterating tracker event rows")
+	}
+
+	return events, nil
+}
+
+// LastCommittedTrackerEvent returns the last event ListTrackerEventsAfter returns from the start
+// with the same maxLag, or nil when there is none yet.
+func (r *repository) LastCommittedTrackerEvent(ctx context.Context, maxLag time.Duration) (*model.TrackerEvent, error) {
+	ctx, span := startChildSpan(ctx, "repository.LastCommittedTrackerEvent")
+	defer span.End()
+
+	query := `
+		SELECT ` + trackerEventColumns + `
+		FROM tracker_events
+		WHERE txid < pg_snapshot_xmin(pg_current_snapshot()) OR created_at < NOW() - make_interval(secs => $1)
+		ORDER BY txid DESC, id DESC
+		LIMIT 1`
+
+	var event model.TrackerEvent
+	err := scanTrackerEvent(r.db.QueryRowContext(ctx, query, maxLag.Seconds()), &event)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errorutil.Wrap(err, "Failed to get last tracker event")
+	}
+
+	return &event, nil
+}
+
+// TrackerEventBounds returns the ids of the oldest and newest retained event, both 0 when there are none.
+func (r *repository) TrackerEventBounds(ctx context.Context) (int64, int64, error) {
+	ctx, span := startChildSpan(ctx, "repository.TrackerEventBounds")
+	defer span.End()
+
+	var oldest, newest int64
+	err := r.db.QueryRowContext(ctx, `SELECT COALESCE(MIN(id), 0), COALESCE(MAX(id), 0) FROM tracker_events`).Scan(&oldest, &newest)
+	if err != nil {
+		return 0, 0, errorutil.Wrap(err, "Failed to get tracker event bounds")
+	}
+	return oldest, newest, nil
+}
+
+// DeleteTrackerEventsBefore prunes the events older than cutoff.
+func (r *repository) DeleteTrackerEventsBefore(ctx context.Context, cutoff time.Time) error {
+	ctx, span := startChildSpan(ctx, "repository.DeleteTrackerEventsBefore")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM tracker_events WHERE created_at < $1`, cutoff)
+	if err != nil {
+		return errorutil.Wrap(err, "Failed to delete tracker events")
+	}
+
+	if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected > 0 {
+		r.logger.Infof("Pruned %d tracker events older than %s", rowsAffected, cutoff.Format(time.RFC3339))
+	}
+	return nil
+}
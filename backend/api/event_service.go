@@ -0,0 +1,254 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+package api
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"timetracker/api/model"
+
+	"github.com/lib/pq"
+)
+
+const (
+	// TrackerEventsChannel is the NOTIFY channel the tracker trigger publishes event ids on.
+	TrackerEventsChannel = "tracker_events"
+	// trackerEventBuffer is how many events a subscriber may lag behind before it is dropped.
+	trackerEventBuffer = 64
+	trackerEventPage   = 500
+)
+
+// trackerEventCursor is the position of an event stream: the transaction and id of the last event
+// sent. Streams send events ordered by transaction and id, see ListTrackerEventsAfter.
+type trackerEventCursor struct {
+	txID uint64
+	id   int64
+}
+
+func cursorOf(event model.TrackerEvent) trackerEventCursor {
+	return trackerEventCursor{txID: event.TxID, id: event.ID}
+}
+
+// before tells whether c sorts before other, i.e. other was not sent yet by a stream at c.
+func (c trackerEventCursor) before(other trackerEventCursor) bool {
+	return c.txID < other.txID || c.txID == other.txID && c.id < other.id
+}
+
+type trackerEventSubscription struct {
+	events chan model.TrackerEvent
+}
+
+// trackerEventHub fans the events received from Postgres out to all subscribed streams.
+type trackerEventHub struct {
+	mu          sync.Mutex
+	subscribers map[*trackerEventSubscription]struct{}
+}
+
+func newTrackerEventHub() *trackerEventHub {
+	return &trackerEventHub{subscribers: make(map[*trackerEventSubscription]struct{})}
+}
+
+func (h *trackerEventHub) subscribe() *trackerEventSubscription {
+	sub := &trackerEventSubscription{events: make(chan model.TrackerEvent, trackerEventBuffer)}
+
+	h.mu.Lock()
+	h.subscribers[sub] = struct{}{}
+	h.mu.Unlock()
+
+	return sub
+}
+
+func (h *trackerEventHub) unsubscribe(sub *trackerEventSubscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subscribers[sub]; ok {
+		delete(h.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// broadcast never blocks on a slow subscriber. A subscriber whose buffer is full is dropped,
+// which ends its stream so the client reconnects and resumes from its last event id.
+func (h *trackerEventHub) broadcast(event model.TrackerEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			delete(h.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// ListenTrackerEvents feeds the notifications of listener into the event hub until ctx is done.
+// Notifications arrive in commit order, which is not the order of the event ids. It also prunes
+// events older than the configured retention.
+func (s *service) ListenTrackerEvents(ctx context.Context, listener *pq.Listener) {
+	logger := s.repo.logger
+
+	horizon, err := s.repo.SyncHorizon(ctx)
+	if err != nil {
+		logger.Errorf("ListenTrackerEvents: Failed to get transaction horizon - %v", err)
+	}
+
+	retention := time.Duration(s.cfg.Events.RetentionHours) * time.Hour
+	if retention <= 0 {
+		retention = 7 * 24 * time.Hour
+	}
+	s.pruneTrackerEvents(ctx, retention)
+
+	pruneTicker := time.NewTicker(time.Hour)
+	defer pruneTicker.Stop()
+	pingTicker := time.NewTicker(90 * time.Second)
+	defer pingTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case notification, ok := <-listener.Notify:
+			if !ok {
+				return
+			}
+
+			// A nil notification follows a reconnect, notifications sent meanwhile are lost.
+			if notification == nil {
+				horizon = s.catchUpTrackerEvents(ctx, horizon)
+				continue
+			}
+
+			id, err := strconv.ParseInt(notification.Extra, 10, 64)
+			if err != nil {
+				logger.Warnf("ListenTrackerEvents: Invalid notification payload %q", notification.Extra)
+				continue
+			}
+
+			event, err := s.repo.GetTrackerEvent(ctx, id)
+			if err != nil {
+				logger.Errorf("ListenTrackerEvents: Failed to load event %d - %v", id, err)
+				continue
+			}
+
+			s.events.broadcast(*event)
+
+		case <-pingTicker.C:
+			go func() {
+				if err := listener.Ping(); err != nil {
+					logger.Warnf("ListenTrackerEvents: Listener ping failed - %v", err)
+				}
+			}()
+
+		case <-pruneTicker.C:
+			s.pruneTrackerEvents(ctx, retention)
+		}
+	}
+}
+
+// catchUpTrackerEvents broadcasts the events of the transactions from horizon on again, which
+// includes every event committed while the listener was disconnected, and returns the horizon
+// for the next reconnect. Events broadcast twice are skipped by the streams.
+func (s *service) catchUpTrackerEvents(ctx context.Context, horizon uint64) uint64 {
+	next, err := s.repo.SyncHorizon(ctx)
+	if err != nil {
+		s.repo.logger.Errorf("catchUpTrackerEvents: Failed to get transaction horizon - %v", err)
+		return horizon
+	}
+
+	var lastID int64
+	for {
+		events, err := s.repo.ListTrackerEventsSince(ctx, horizon, lastID, trackerEventPage)
+		if err != nil {
+			s.repo.logger.Errorf("catchUpTrackerEvents: Failed to load events since %d - %v", horizon, err)
+			return horizon
+		}
+
+		for _, event := range events {
+			s.events.broadcast(event)
+			lastID = event.ID
+		}
+		if len(events) < trackerEventPage {
+			return next
+		}
+	}
+}
+
+func (s *service) pruneTrackerEvents(ctx context.Context, retention time.Duration) {
+	if err := s.repo.DeleteTrackerEventsBefore(ctx, time.Now().Add(-retention)); err != nil {
+		s.repo.logger.Errorf("pruneTrackerEvents: %v", err)
+	}
+}
+
+func (s *service) SubscribeTrackerEventsService() *trackerEventSubscription {
+	return s.events.subscribe()
+}
+
+func (s *service) UnsubscribeTrackerEventsService(sub *trackerEventSubscription) {
+	s.events.unsubscribe(sub)
+}
+
+// trackerEventMaxLag is how long an event is held back for older transactions that are still running.
+func (s *service) trackerEventMaxLag() time.Duration {
+	maxLag := time.Duration(s.cfg.Events.MaxLagSeconds) * time.Second
+	if maxLag <= 0 {
+		maxLag = 30 * time.Second
+	}
+	return maxLag
+}
+
+// TrackerEventsAfterService returns up to limit committed events after cursor, see ListTrackerEventsAfter.
+func (s *service) TrackerEventsAfterService(ctx context.Context, cursor trackerEventCursor, limit int) ([]model.TrackerEvent, error) {
+	ctx, span := tracer.Start(ctx, "service.TrackerEventsAfterService")
+	defer span.End()
+
+	return s.repo.ListTrackerEventsAfter(ctx, cursor.txID, cursor.id, limit, s.trackerEventMaxLag())
+}
+
+// TrackerEventCursorService returns the cursor of a stream that continues after the event with
+// lastEventID, where 0 stands for the start of the retained events. A new stream, and one whose
+// last event was already pruned (resync), continues after the last committed event instead.
+func (s *service) TrackerEventCursorService(ctx context.Context, lastEventID int64, resume bool) (trackerEventCursor, bool, error) {
+	ctx, span := tracer.Start(ctx, "service.TrackerEventCursorService")
+	defer span.End()
+
+	resync := false
+	if resume {
+		if lastEventID == 0 {
+			oldest, _, err := s.repo.TrackerEventBounds(ctx)
+			if err != nil {
+				return trackerEventCursor{}, false, err
+			}
+			if oldest <= 1 {
+				return trackerEventCursor{}, false, nil
+			}
+		} else {
+			event, err := s.repo.GetTrackerEvent(ctx, lastEventID)
+			if err == nil {
+				return cursorOf(*event), false, nil
+			}
+			if !strings.Contains(err.Error(), "not found") {
+				return trackerEventCursor{}, false, err
+			}
+		}
+		resync = true
+	}
+
+	last, err := s.repo.LastCommittedTrackerEvent(ctx, s.trackerEventMaxLag())
+	if err != nil {
+		return trackerEventCursor{}, false, err
+	}
+	if last == nil {
+		return trackerEventCursor{}, resync, nil
+	}
+	return cursorOf(*last), resync, nil
+}
@@ -0,0 +1,150 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+	"timetracker/api/model"
+	"timetracker/internal/config"
+	"timetracker/logger"
+)
+
+// TestTrackerEventsOfLateCommits writes a tracker in a transaction that commits after a later
+// one, as a slow batch does, and checks that the event stream neither skips it live nor on
+// resume. It needs TEST_DATABASE_URL.
+func TestTrackerEventsOfLateCommits(t *testing.T) {
+	database, withDB := openTestDatabase(t)
+	if !withDB {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	log := logger.NewLogger("events", filepath.Join(t.TempDir(), "api.log"))
+	s := Service(Repository(database, log), &config.Config{})
+	ctx := context.Background()
+
+	start, _, err := s.TrackerEventCursorService(ctx, 0, false)
+	if err != nil {
+		t.Fatalf("Failed to place the cursor: %v", err)
+	}
+
+	late, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+	defer late.Rollback()
+	var lateID int
+	if err := late.QueryRowContext(ctx, `INSERT INTO tracker (task, start_time) VALUES ('Late', NOW()) RETURNING id`).Scan(&lateID); err != nil {
+		t.Fatalf("Failed to create tracker: %v", err)
+	}
+
+	early, err := s.repo.CreateTracker(ctx, model.CreateTrackerRequest{Task: "Early", StartTime: time.Now()})
+	if err != nil {
+		t.Fatalf("Failed to create tracker: %v", err)
+	}
+
+	events, err := s.TrackerEventsAfterService(ctx, start, trackerEventPage)
+	if err != nil {
+		t.Fatalf("Failed to list events: %v", err)
+	}
+	for _, event := range events {
+		if event.TrackerID == early.ID {
+			t.Fatalf("Event of tracker %d was sent while an older transaction is still running", early.ID)
+		}
+	}
+
+	if err := late.Commit(); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	events, err = s.TrackerEventsAfterService(ctx, start, trackerEventPage)
+	if err != nil {
+		t.Fatalf("Failed to list events: %v", err)
+	}
+	var lateEvent, earlyEvent *model.TrackerEvent
+	for i, event := range events {
+		switch event.TrackerID {
+		case lateID:
+			lateEvent = &events[i]
+		case early.ID:
+			if lateEvent == nil {
+				t.Fatalf("Event of tracker %d was sent before the one of the older transaction", early.ID)
+			}
+			earlyEvent = &events[i]
+		}
+	}
+	if lateEvent == nil || earlyEvent == nil {
+		t.Fatalf("Events after commit miss tracker %d or %d: %v", lateID, early.ID, events)
+	}
+	if lateEvent.ID > earlyEvent.ID {
+		t.Fatalf("Event ids %d and %d are not in insert order", lateEvent.ID, earlyEvent.ID)
+	}
+
+	// A client that received the event of the late transaction resumes with the early one.
+	cursor, resync, err := s.TrackerEventCursorService(ctx, lateEvent.ID, true)
+	if err != nil || resync {
+		t.Fatalf("Failed to resume: resync %v, %v", resync, err)
+	}
+	resumed, err := s.TrackerEventsAfterService(ctx, cursor, trackerEventPage)
+	if err != nil {
+		t.Fatalf("Failed to list events: %v", err)
+	}
+	if len(resumed) == 0 || resumed[0].TrackerID != early.ID {
+		t.Fatalf("Resumed stream does not start with tracker %d: %v", early.ID, resumed)
+	}
+}
+
+// TestTrackerEventsNotBlockedByUnrelatedTransaction keeps a transaction open that never touches
+// trackers, as a migration or an idle session does, and checks that a tracker event is still
+// sent once it is older than events.max_lag_seconds. It needs TEST_DATABASE_URL.
+func TestTrackerEventsNotBlockedByUnrelatedTransaction(t *testing.T) {
+	database, withDB := openTestDatabase(t)
+	if !withDB {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	log := logger.NewLogger("events", filepath.Join(t.TempDir(), "api.log"))
+	s := Service(Repository(database, log), &config.Config{Events: config.EventsConfig{MaxLagSeconds: 1}})
+	ctx := context.Background()
+
+	start, _, err := s.TrackerEventCursorService(ctx, 0, false)
+	if err != nil {
+		t.Fatalf("Failed to place the cursor: %v", err)
+	}
+
+	idle, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+	defer idle.Rollback()
+	// Taking a transaction id makes it count for pg_snapshot_xmin.
+	if _, err := idle.ExecContext(ctx, `SELECT pg_current_xact_id()`); err != nil {
+		t.Fatalf("Failed to take a transaction id: %v", err)
+	}
+
+	tracker, err := s.repo.CreateTracker(ctx, model.CreateTrackerRequest{Task: "Blocked", StartTime: time.Now()})
+	if err != nil {
+		t.Fatalf("Failed to create tracker: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		events, err := s.TrackerEventsAfterService(ctx, start, trackerEventPage)
+		if err != nil {
+			t.Fatalf("Failed to list events: %v", err)
+		}
+		for _, event := range events {
+			if event.TrackerID == tracker.ID {
+				return
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Event of tracker %d is still held back by an unrelated open transaction", tracker.ID)
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
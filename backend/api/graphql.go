@@ -0,0 +1,103 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"timetracker/api/model"
+	"timetracker/logger"
+
+	"github.com/graph-gophers/graphql-go"
+	graphqllog "github.com/graph-gophers/graphql-go/log"
+)
+
+//go:embed schema.graphql
+var graphqlSchema string
+
+const (
+	// maxGraphQLBodyBytes bounds the size of a GraphQL request, query and variables together.
+	maxGraphQLBodyBytes = 1 << 20
+	maxGraphQLDepth     = 8
+)
+
+type graphqlRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// newGraphQLSchema parses the embedded schema with the resolvers of h. Panics in resolvers are
+// logged and reported to the client as an error of the field.
+func newGraphQLSchema(h *handler) *graphql.Schema {
+	return graphql.MustParseSchema(graphqlSchema, &graphqlResolver{h: h},
+		graphql.UseStringDescriptions(),
+		graphql.MaxDepth(maxGraphQLDepth),
+		graphql.Logger(graphqlPanicLogger(h.logger)))
+}
+
+func graphqlPanicLogger(l *logger.Logger) graphqllog.Logger {
+	return graphqllog.LoggerFunc(func(ctx context.Context, value any) {
+		l.Ctx(ctx).Errorf("GraphQLHandler: Panic while resolving query - %v", value)
+	})
+}
+
+// GraphQLHandler executes a GraphQL query or mutation against the schema in schema.graphql.
+// It expects a JSON payload containing:
+//   - query: string (required)
+//   - operationName: string (optional)
+//   - variables: object (optional)
+//
+// Errors of single fields do not fail the request, they are listed in the errors array of the
+// response next to the data that could be resolved. Each error carries the code the REST endpoint
+// would answer with, e.g. VALIDATION_ERROR, NOT_FOUND or PRECONDITION_FAILED, in extensions.code.
+//
+// Returns:
+//   - 200 OK: The result of the query, including field errors
+//   - 400 Bad Request: Invalid JSON payload or missing query
+//   - 413 Request Entity Too Large: The payload exceeds 1 MB
+func (h *handler) GraphQLHandler(w http.ResponseWriter, req *http.Request) {
+	h.log(req).Infof("GraphQLHandler: Processing request from %s", req.RemoteAddr)
+
+	var request graphqlRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxGraphQLBodyBytes)).Decode(&request); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.sendErrorResponse(w, req, http.StatusRequestEntityTooLarge,
+				"Request body too large",
+				"GraphQL requests cannot exceed 1 MB",
+				"BODY_TOO_LARGE")
+			return
+		}
+		h.log(req).Warnf("GraphQLHandler: Failed to decode JSON - %v", err)
+		h.sendErrorResponse(w, req, http.StatusBadRequest,
+			"Invalid JSON payload",
+			"Request body must be a JSON object with query, operationName and variables",
+			"INVALID_JSON")
+		return
+	}
+
+	if request.Query == "" {
+		h.sendValidationErrorResponse(w, req, []model.FieldError{
+			fieldError("query", model.FieldErrorRequired, "query is required"),
+		})
+		return
+	}
+
+	response := h.graphql.Exec(req.Context(), request.Query, request.OperationName, request.Variables)
+	if len(response.Errors) > 0 {
+		h.log(req).Warnf("GraphQLHandler: Query finished with %d errors, first: %s",
+			len(response.Errors), response.Errors[0].Message)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,544 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"timetracker/api/model"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+const maxGraphQLPageSize = 200
+
+// graphqlError is a resolver error with the error code of the matching REST response,
+// reported in extensions.code. Validation errors list the invalid fields in extensions.errors.
+type graphqlError struct {
+	message string
+	code    string
+	errors  []model.FieldError
+}
+
+func (e *graphqlError) Error() string {
+	return e.message
+}
+
+func (e *graphqlError) Extensions() map[string]any {
+	extensions := map[string]any{"code": e.code}
+	if len(e.errors) > 0 {
+		extensions["errors"] = e.errors
+	}
+	return extensions
+}
+
+func graphqlValidationError(errs []model.FieldError) error {
+	return &graphqlError{message: fieldErrorMessages(errs), code: "VALIDATION_ERROR", errors: errs}
+}
+
+// trackerError maps a service error to the code the REST endpoint answers with. Internal errors
+// are logged and replaced by a generic message.
+func (r *graphqlResolver) trackerError(field string, id int, err error) error {
+	status, opErr := trackerOperationError(id, err)
+	if status >= http.StatusInternalServerError {
+		r.h.logger.Errorf("GraphQLHandler: %s failed - %v", field, err)
+		return &graphqlError{message: "An error occurred while accessing the database", code: "INTERNAL_ERROR"}
+	}
+	return &graphqlError{message: opErr.Message, code: opErr.Code, errors: opErr.Errors}
+}
+
+func parseGraphQLID(id graphql.ID) (int, error) {
+	value, err := strconv.Atoi(string(id))
+	if err != nil || value <= 0 {
+		return 0, graphqlValidationError([]model.FieldError{
+			fieldError("id", model.FieldErrorOutOfRange, "id must be a positive integer"),
+		})
+	}
+	return value, nil
+}
+
+func graphqlVersions(version *int32) []int {
+	if version == nil {
+		return nil
+	}
+	return []int{int(*version)}
+}
+
+func timePtr(t *graphql.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	return &t.Time
+}
+
+// graphqlResolver resolves the Query and Mutation types.
+type graphqlResolver struct {
+	h *handler
+}
+
+type trackerFilterInput struct {
+	Project *string
+	From    *graphql.Time
+	To      *graphql.Time
+	UserID  *graphql.ID
+	Running *bool
+}
+
+// toFilter checks f and turns it into a filter. userId is limited like owner: users may filter
+// by their own id, admins by any.
+func (f *trackerFilterInput) toFilter(ctx context.Context) (model.TrackerFilter, error) {
+	var filter model.TrackerFilter
+	if f == nil {
+		return filter, nil
+	}
+
+	filter.Project = f.Project
+	filter.From = timePtr(f.From)
+	filter.To = timePtr(f.To)
+	if f.UserID != nil {
+		userID, err := parseGraphQLID(*f.UserID)
+		if err != nil {
+			return filter, err
+		}
+		if !mayFilterByUser(ctx, userID) {
+			return filter, &graphqlError{message: "Only admins can filter by the trackers of other users", code: "FORBIDDEN"}
+		}
+		filter.UserID = &userID
+	}
+	filter.Running = f.Running != nil && *f.Running
+	return filter, nil
+}
+
+func (r *graphqlResolver) Tracker(ctx context.Context, args struct{ ID graphql.ID }) (*trackerResolver, error) {
+	id, err := parseGraphQLID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	tracker, err := r.h.service.GetTrackerByIDService(ctx, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, nil
+		}
+		return nil, r.trackerError("tracker", id, err)
+	}
+	return r.newTrackerResolver(tracker, nil), nil
+}
+
+func (r *graphqlResolver) Trackers(ctx context.Context, args struct {
+	Filter *trackerFilterInput
+	Limit  int32
+	Offset int32
+}) (*trackerConnectionResolver, error) {
+	filter, err := args.Filter.toFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, offset := int(args.Limit), int(args.Offset)
+
+	var errs []model.FieldError
+	if limit < 1 || limit > maxGraphQLPageSize {
+		errs = append(errs, fieldError("limit", model.FieldErrorOutOfRange,
+			fmt.Sprintf("limit must be between 1 and %d", maxGraphQLPageSize)))
+	}
+	if offset < 0 {
+		errs = append(errs, fieldError("offset", model.FieldErrorOutOfRange, "offset cannot be negative"))
+	}
+	if len(errs) > 0 {
+		return nil, graphqlValidationError(errs)
+	}
+
+	// One tracker more than requested tells whether there is a next page.
+	filter.Limit, filter.Offset = limit+1, offset
+	trackers, err := r.h.service.FindTrackersService(ctx, filter)
+	if err != nil {
+		return nil, r.trackerError("trackers", 0, err)
+	}
+
+	connection := &trackerConnectionResolver{r: r, filter: filter, limit: limit, offset: offset}
+	if len(trackers) > limit {
+		connection.hasNextPage = true
+		trackers = trackers[:limit]
+	}
+
+	owners := newOwnerLoader(r.h, trackers)
+	for i := range trackers {
+		connection.nodes = append(connection.nodes, r.newTrackerResolver(&trackers[i], owners))
+	}
+	return connection, nil
+}
+
+func (r *graphqlResolver) Summary(ctx context.Context, args struct{ Filter *trackerFilterInput }) (*summaryResolver, error) {
+	filter, err := args.Filter.toFilter(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := r.h.service.SummarizeTrackersService(ctx, filter)
+	if err != nil {
+		return nil, r.trackerError("summary", 0, err)
+	}
+	return &summaryResolver{summary: summary}, nil
+}
+
+func (r *graphqlResolver) Me(ctx context.Context) *userResolver {
+	user, ok := verifiedUserFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	return &userResolver{user: user}
+}
+
+type createTrackerInput struct {
+	Task      string
+	Project   *string
+	StartTime graphql.Time
+	EndTime   *graphql.Time
+}
+
+func (r *graphqlResolver) CreateTracker(ctx context.Context, args struct{ Input createTrackerInput }) (*trackerResolver, error) {
+	req := model.CreateTrackerRequest{
+		Task:      args.Input.Task,
+		Project:   args.Input.Project,
+		StartTime: args.Input.StartTime.Time,
+		EndTime:   timePtr(args.Input.EndTime),
+	}
+	if user, ok := verifiedUserFromContext(ctx); ok {
+		req.UserID = &user.ID
+	}
+
+	if errs := validateStruct(&req); len(errs) > 0 {
+		return nil, graphqlValidationError(errs)
+	}
+
+	tracker, err := r.h.service.CreateTrackerService(ctx, req)
+	if err != nil {
+		return nil, r.trackerError("createTracker", 0, err)
+	}
+
+	r.h.logger.Infof("GraphQLHandler: Successfully created tracker with ID: %d", tracker.ID)
+	return r.newTrackerResolver(tracker, nil), nil
+}
+
+func (r *graphqlResolver) UpdateTracker(ctx context.Context, args struct {
+	ID      graphql.ID
+	Input   createTrackerInput
+	Version *int32
+}) (*trackerResolver, error) {
+	id, err := parseGraphQLID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	req := model.UpdateTrackerRequest{
+		Task:      args.Input.Task,
+		Project:   args.Input.Project,
+		StartTime: args.Input.StartTime.Time,
+		EndTime:   timePtr(args.Input.EndTime),
+	}
+	if errs := validateStruct(&req); len(errs) > 0 {
+		return nil, graphqlValidationError(errs)
+	}
+
+	tracker, err := r.h.service.UpdateTrackerService(ctx, id, req, graphqlVersions(args.Version))
+	if err != nil {
+		return nil, r.trackerError("updateTracker", id, err)
+	}
+
+	r.h.logger.Infof("GraphQLHandler: Successfully updated tracker with ID: %d", id)
+	return r.newTrackerResolver(tracker, nil), nil
+}
+
+type trackerPatchInput struct {
+	Task      graphql.NullString
+	Project   graphql.NullString
+	StartTime graphql.NullTime
+	EndTime   graphql.NullTime
+}
+
+func nullableString(value graphql.NullString) model.Nullable[string] {
+	if !value.Set {
+		return model.Nullable[string]{}
+	}
+	return model.NullableFromPtr(value.Value)
+}
+
+func nullableTime(value graphql.NullTime) model.Nullable[time.Time] {
+	if !value.Set {
+		return model.Nullable[time.Time]{}
+	}
+	if value.Value == nil {
+		return model.NullableFromPtr[time.Time](nil)
+	}
+	return model.NullableValue(value.Value.Time)
+}
+
+func (r *graphqlResolver) PatchTracker(ctx context.Context, args struct {
+	ID      graphql.ID
+	Input   trackerPatchInput
+	Version *int32
+}) (*trackerResolver, error) {
+	id, err := parseGraphQLID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	patch := model.TrackerPatch{
+		Task:      nullableString(args.Input.Task),
+		Project:   nullableString(args.Input.Project),
+		StartTime: nullableTime(args.Input.StartTime),
+		EndTime:   nullableTime(args.Input.EndTime),
+	}
+	if errs := r.h.validateTrackerPatch(&patch); len(errs) > 0 {
+		return nil, graphqlValidationError(errs)
+	}
+
+	tracker, err := r.h.service.PatchTrackerService(ctx, id, patch, graphqlVersions(args.Version))
+	if err != nil {
+		return nil, r.trackerError("patchTracker", id, err)
+	}
+
+	r.h.logger.Infof("GraphQLHandler: Successfully patched tracker with ID: %d", id)
+	return r.newTrackerResolver(tracker, nil), nil
+}
+
+func (r *graphqlResolver) DeleteTracker(ctx context.Context, args struct {
+	ID      graphql.ID
+	Version *int32
+}) (graphql.ID, error) {
+	id, err := parseGraphQLID(args.ID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := r.h.service.DeleteTrackerService(ctx, id, graphqlVersions(args.Version)); err != nil {
+		return "", r.trackerError("deleteTracker", id, err)
+	}
+
+	r.h.logger.Infof("GraphQLHandler: Successfully deleted tracker with ID: %d", id)
+	return args.ID, nil
+}
+
+type trackerConnectionResolver struct {
+	r           *graphqlResolver
+	filter      model.TrackerFilter
+	nodes       []*trackerResolver
+	limit       int
+	offset      int
+	hasNextPage bool
+
+	summaryOnce sync.Once
+	summary     *model.TrackerSummary
+	summaryErr  error
+}
+
+func (c *trackerConnectionResolver) Nodes() []*trackerResolver {
+	return c.nodes
+}
+
+// loadSummary aggregates all pages once in the database, for both totalCount and summary.
+func (c *trackerConnectionResolver) loadSummary(ctx context.Context) (*model.TrackerSummary, error) {
+	c.summaryOnce.Do(func() {
+		c.summary, c.summaryErr = c.r.h.service.SummarizeTrackersService(ctx, c.filter)
+		if c.summaryErr != nil {
+			c.summaryErr = c.r.trackerError("trackers", 0, c.summaryErr)
+		}
+	})
+	return c.summary, c.summaryErr
+}
+
+func (c *trackerConnectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	summary, err := c.loadSummary(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return int32(summary.Count), nil
+}
+
+func (c *trackerConnectionResolver) PageInfo() *pageInfoResolver {
+	return &pageInfoResolver{limit: c.limit, offset: c.offset, hasNextPage: c.hasNextPage}
+}
+
+func (c *trackerConnectionResolver) Summary(ctx context.Context) (*summaryResolver, error) {
+	summary, err := c.loadSummary(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &summaryResolver{summary: summary}, nil
+}
+
+type pageInfoResolver struct {
+	limit       int
+	offset      int
+	hasNextPage bool
+}
+
+func (p *pageInfoResolver) Limit() int32      { return int32(p.limit) }
+func (p *pageInfoResolver) Offset() int32     { return int32(p.offset) }
+func (p *pageInfoResolver) HasNextPage() bool { return p.hasNextPage }
+
+type trackerResolver struct {
+	r       *graphqlResolver
+	tracker *model.Tracker
+	owners  *ownerLoader
+}
+
+// newTrackerResolver resolves tracker. owners batches the owner lookups of a page of trackers,
+// a single tracker loads its owner on its own when owners is nil.
+func (r *graphqlResolver) newTrackerResolver(tracker *model.Tracker, owners *ownerLoader) *trackerResolver {
+	if owners == nil {
+		owners = newOwnerLoader(r.h, []model.Tracker{*tracker})
+	}
+	return &trackerResolver{r: r, tracker: tracker, owners: owners}
+}
+
+func (t *trackerResolver) ID() graphql.ID {
+	return graphql.ID(strconv.Itoa(t.tracker.ID))
+}
+
+func (t *trackerResolver) Task() string {
+	return t.tracker.Task
+}
+
+func (t *trackerResolver) Project() *string {
+	return t.tracker.Project
+}
+
+func (t *trackerResolver) StartTime() graphql.Time {
+	return graphql.Time{Time: t.tracker.StartTime}
+}
+
+func (t *trackerResolver) EndTime() *graphql.Time {
+	if t.tracker.EndTime == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *t.tracker.EndTime}
+}
+
+func (t *trackerResolver) Running() bool {
+	return t.tracker.EndTime == nil
+}
+
+func (t *trackerResolver) DurationSeconds() int32 {
+	return int32(trackerDuration(*t.tracker, time.Now()))
+}
+
+func (t *trackerResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: t.tracker.CreatedAt}
+}
+
+func (t *trackerResolver) UpdatedAt() graphql.Time {
+	return graphql.Time{Time: t.tracker.UpdatedAt}
+}
+
+func (t *trackerResolver) Version() int32 {
+	return int32(t.tracker.Version)
+}
+
+// Owner is only resolved for the owner and for admins, other clients get null.
+func (t *trackerResolver) Owner(ctx context.Context) (*userResolver, error) {
+	if t.tracker.UserID == nil {
+		return nil, nil
+	}
+
+	user, ok := verifiedUserFromContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+	if user.ID == *t.tracker.UserID {
+		return &userResolver{user: user}, nil
+	}
+	if user.Role != model.RoleAdmin {
+		return nil, nil
+	}
+
+	owner, err := t.owners.load(ctx, *t.tracker.UserID)
+	if err != nil {
+		t.r.h.logger.Errorf("GraphQLHandler: Failed to load owner of tracker %d - %v", t.tracker.ID, err)
+		return nil, &graphqlError{message: "An error occurred while loading the owner", code: "INTERNAL_ERROR"}
+	}
+	if owner == nil {
+		return nil, nil
+	}
+	return &userResolver{user: owner}, nil
+}
+
+// ownerLoader loads the owners of a page of trackers with a single query, when the first
+// owner is requested.
+type ownerLoader struct {
+	h     *handler
+	ids   []int
+	once  sync.Once
+	users map[int]*model.User
+	err   error
+}
+
+func newOwnerLoader(h *handler, trackers []model.Tracker) *ownerLoader {
+	loader := &ownerLoader{h: h}
+	seen := map[int]bool{}
+	for _, t := range trackers {
+		if t.UserID != nil && !seen[*t.UserID] {
+			seen[*t.UserID] = true
+			loader.ids = append(loader.ids, *t.UserID)
+		}
+	}
+	return loader
+}
+
+func (l *ownerLoader) load(ctx context.Context, id int) (*model.User, error) {
+	l.once.Do(func() {
+		l.users, l.err = l.h.service.GetUsersByIDsService(ctx, l.ids)
+	})
+	if l.err != nil {
+		return nil, l.err
+	}
+	return l.users[id], nil
+}
+
+type summaryResolver struct {
+	summary *model.TrackerSummary
+}
+
+func (s *summaryResolver) Count() int32          { return int32(s.summary.Count) }
+func (s *summaryResolver) Running() int32        { return int32(s.summary.Running) }
+func (s *summaryResolver) TotalSeconds() float64 { return float64(s.summary.TotalSeconds) }
+func (s *summaryResolver) TotalHours() float64   { return s.summary.TotalHours }
+
+func (s *summaryResolver) Projects() []*projectSummaryResolver {
+	projects := make([]*projectSummaryResolver, len(s.summary.Projects))
+	for i := range s.summary.Projects {
+		projects[i] = &projectSummaryResolver{project: &s.summary.Projects[i]}
+	}
+	return projects
+}
+
+type projectSummaryResolver struct {
+	project *model.ProjectSummary
+}
+
+func (p *projectSummaryResolver) Project() *string      { return p.project.Project }
+func (p *projectSummaryResolver) Count() int32          { return int32(p.project.Count) }
+func (p *projectSummaryResolver) TotalSeconds() float64 { return float64(p.project.TotalSeconds) }
+func (p *projectSummaryResolver) TotalHours() float64   { return p.project.TotalHours }
+
+type userResolver struct {
+	user *model.User
+}
+
+func (u *userResolver) ID() graphql.ID {
+	return graphql.ID(strconv.Itoa(u.user.ID))
+}
+
+func (u *userResolver) Email() string { return u.user.Email }
+func (u *userResolver) Name() string  { return u.user.Name }
+func (u *userResolver) Role() string  { return u.user.Role }
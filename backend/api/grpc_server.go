@@ -0,0 +1,461 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+	"timetracker/api/model"
+	"timetracker/api/trackerpb"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultGRPCPageSize = 100
+	maxGRPCPageSize     = 500
+)
+
+// trackerServer implements trackerpb.TrackerServiceServer on top of the same service, and the same
+// validation, as the REST handlers.
+type trackerServer struct {
+	trackerpb.UnimplementedTrackerServiceServer
+	h *handler
+}
+
+// GRPCServer returns a gRPC server with the tracker service and the standard health service
+// registered. Session tokens sent as authorization metadata are resolved like for HTTP requests.
+func GRPCServer(h *handler) *grpc.Server {
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(h.grpcRecover, h.grpcAuthenticate))
+
+	trackerpb.RegisterTrackerServiceServer(server, &trackerServer{h: h})
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	if h.cfg.GRPC.Reflection {
+		reflection.Register(server)
+	}
+
+	return server
+}
+
+// grpcRecover logs every call and turns a panic in a method into an Internal error, so a single
+// bad call cannot take down the server.
+func (h *handler) grpcRecover(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (resp any, err error) {
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			h.logger.Errorf("grpc: Panic in %s - %v\n%s", info.FullMethod, r, debug.Stack())
+			err = status.Error(codes.Internal, "an internal error occurred")
+		}
+		h.logger.Infof("grpc: %s finished with %s in %s", info.FullMethod, status.Code(err), time.Since(start))
+	}()
+
+	return next(ctx, req)
+}
+
+// grpcAuthenticate resolves the "authorization: Bearer <token>" metadata of the call, if any, and
+// stores the session user in the context. Like the HTTP middleware it never rejects a call by itself.
+func (h *handler) grpcAuthenticate(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return next(ctx, req)
+	}
+
+	scheme, token, ok := strings.Cut(values[0], " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return next(ctx, req)
+	}
+
+	session, user, err := h.service.AuthenticateService(ctx, strings.TrimSpace(token))
+	if err != nil {
+		if !strings.Contains(err.Error(), "not found") {
+			h.logger.Errorf("grpc: Failed to resolve session - %v", err)
+		}
+		return next(ctx, req)
+	}
+
+	ctx = context.WithValue(ctx, userContextKey, user)
+	ctx = context.WithValue(ctx, sessionContextKey, session)
+	return next(ctx, req)
+}
+
+// grpcError maps a service error to a gRPC status. Internal errors are logged and replaced by a
+// generic message.
+func (s *trackerServer) grpcError(method string, err error) error {
+	switch {
+	case errors.Is(err, errVersionMismatch):
+		return status.Error(codes.Aborted, "the tracker was modified since the given version")
+	case errors.Is(err, errPatchConflict):
+		return status.Error(codes.Aborted, "the tracker kept changing while the patch was applied")
+	case errors.Is(err, errInvalidPatch):
+		return invalidArgument([]model.FieldError{
+			fieldError("end_time", model.FieldErrorOutOfOrder, "end_time cannot be before start_time"),
+		})
+	case errors.Is(err, errTrackerNotAllowed):
+		return status.Error(codes.PermissionDenied, "the tracker belongs to another user")
+	case errors.Is(err, errTimerNotRunning):
+		return status.Error(codes.FailedPrecondition, "the timer is not running")
+	case strings.Contains(err.Error(), "not found"):
+		return status.Error(codes.NotFound, "tracker not found")
+	default:
+		s.h.logger.Errorf("grpc: %s failed - %v", method, err)
+		return status.Error(codes.Internal, "an error occurred while accessing the database")
+	}
+}
+
+// invalidArgument reports the invalid fields as a BadRequest detail, next to the joined messages.
+func invalidArgument(errs []model.FieldError) error {
+	badRequest := &errdetails.BadRequest{}
+	for _, err := range errs {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       err.Field,
+			Description: err.Message,
+			Reason:      strings.ToUpper(err.Code),
+		})
+	}
+
+	st, err := status.New(codes.InvalidArgument, fieldErrorMessages(errs)).WithDetails(badRequest)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, fieldErrorMessages(errs))
+	}
+	return st.Err()
+}
+
+func grpcTrackerID(id int64) (int, error) {
+	if id <= 0 {
+		return 0, invalidArgument([]model.FieldError{
+			fieldError("id", model.FieldErrorOutOfRange, "id must be a positive integer"),
+		})
+	}
+	return int(id), nil
+}
+
+func grpcVersions(version *int32) []int {
+	if version == nil {
+		return nil
+	}
+	return []int{int(*version)}
+}
+
+// grpcUser returns the verified user of the call, timer methods cannot be called anonymously.
+func grpcUser(ctx context.Context) (*model.User, error) {
+	user, ok := verifiedUserFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "a valid session token is required")
+	}
+	return user, nil
+}
+
+func timestampPtr(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+func timestampTime(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+func toProtoTracker(t *model.Tracker) *trackerpb.Tracker {
+	tracker := &trackerpb.Tracker{
+		Id:         int64(t.ID),
+		Task:       t.Task,
+		Project:    t.Project,
+		StartTime:  timestamppb.New(t.StartTime),
+		CreateTime: timestamppb.New(t.CreatedAt),
+		UpdateTime: timestamppb.New(t.UpdatedAt),
+		Version:    int32(t.Version),
+	}
+	if t.EndTime != nil {
+		tracker.EndTime = timestamppb.New(*t.EndTime)
+	}
+	if t.UserID != nil {
+		userID := int64(*t.UserID)
+		tracker.UserId = &userID
+	}
+	return tracker
+}
+
+func toProtoTrackers(trackers []model.Tracker) []*trackerpb.Tracker {
+	result := make([]*trackerpb.Tracker, len(trackers))
+	for i := range trackers {
+		result[i] = toProtoTracker(&trackers[i])
+	}
+	return result
+}
+
+// Page tokens carry the offset of the next page. They are opaque to clients.
+func encodePageToken(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodePageToken(token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, invalidArgument([]model.FieldError{
+			fieldError("page_token", model.FieldErrorInvalid, "page_token is invalid"),
+		})
+	}
+	offset, err := strconv.Atoi(string(raw))
+	if err != nil || offset < 0 {
+		return 0, invalidArgument([]model.FieldError{
+			fieldError("page_token", model.FieldErrorInvalid, "page_token is invalid"),
+		})
+	}
+	return offset, nil
+}
+
+func (s *trackerServer) GetTracker(ctx context.Context, req *trackerpb.GetTrackerRequest) (*trackerpb.Tracker, error) {
+	id, err := grpcTrackerID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	tracker, err := s.h.service.GetTrackerByIDService(ctx, id)
+	if err != nil {
+		return nil, s.grpcError("GetTracker", err)
+	}
+	return toProtoTracker(tracker), nil
+}
+
+func (s *trackerServer) ListTrackers(ctx context.Context, req *trackerpb.ListTrackersRequest) (*trackerpb.ListTrackersResponse, error) {
+	pageSize := int(req.GetPageSize())
+	if pageSize == 0 {
+		pageSize = defaultGRPCPageSize
+	}
+	if pageSize < 0 || pageSize > maxGRPCPageSize {
+		return nil, invalidArgument([]model.FieldError{
+			fieldError("page_size", model.FieldErrorOutOfRange, "page_size must be between 1 and 500"),
+		})
+	}
+	offset, err := decodePageToken(req.GetPageToken())
+	if err != nil {
+		return nil, err
+	}
+
+	filter := model.TrackerFilter{
+		Project: req.Project,
+		From:    timestampPtr(req.GetFrom()),
+		To:      timestampPtr(req.GetTo()),
+		Running: req.GetRunning(),
+		// One tracker more than requested tells whether there is a next page.
+		Limit:  pageSize + 1,
+		Offset: offset,
+	}
+	if req.UserId != nil {
+		userID := int(req.GetUserId())
+		if !mayFilterByUser(ctx, userID) {
+			return nil, status.Error(codes.PermissionDenied, "only admins can list the trackers of other users")
+		}
+		filter.UserID = &userID
+	}
+
+	trackers, err := s.h.service.FindTrackersService(ctx, filter)
+	if err != nil {
+		return nil, s.grpcError("ListTrackers", err)
+	}
+
+	response := &trackerpb.ListTrackersResponse{}
+	if len(trackers) > pageSize {
+		trackers = trackers[:pageSize]
+		response.NextPageToken = encodePageToken(offset + pageSize)
+	}
+	response.Trackers = toProtoTrackers(trackers)
+	return response, nil
+}
+
+func (s *trackerServer) CreateTracker(ctx context.Context, req *trackerpb.CreateTrackerRequest) (*trackerpb.Tracker, error) {
+	create := model.CreateTrackerRequest{
+		Task:      req.GetTask(),
+		Project:   req.Project,
+		StartTime: timestampTime(req.GetStartTime()),
+		EndTime:   timestampPtr(req.GetEndTime()),
+	}
+	if user, ok := verifiedUserFromContext(ctx); ok {
+		create.UserID = &user.ID
+	}
+
+	if errs := validateStruct(&create); len(errs) > 0 {
+		return nil, invalidArgument(errs)
+	}
+
+	tracker, err := s.h.service.CreateTrackerService(ctx, create)
+	if err != nil {
+		return nil, s.grpcError("CreateTracker", err)
+	}
+
+	s.h.logger.Infof("grpc: Successfully created tracker with ID: %d", tracker.ID)
+	return toProtoTracker(tracker), nil
+}
+
+func (s *trackerServer) UpdateTracker(ctx context.Context, req *trackerpb.UpdateTrackerRequest) (*trackerpb.Tracker, error) {
+	id, err := grpcTrackerID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	update := model.UpdateTrackerRequest{
+		Task:      req.GetTask(),
+		Project:   req.Project,
+		StartTime: timestampTime(req.GetStartTime()),
+		EndTime:   timestampPtr(req.GetEndTime()),
+	}
+	if errs := validateStruct(&update); len(errs) > 0 {
+		return nil, invalidArgument(errs)
+	}
+
+	tracker, err := s.h.service.UpdateTrackerService(ctx, id, update, grpcVersions(req.Version))
+	if err != nil {
+		return nil, s.grpcError("UpdateTracker", err)
+	}
+
+	s.h.logger.Infof("grpc: Successfully updated tracker with ID: %d", id)
+	return toProtoTracker(tracker), nil
+}
+
+// trackerPatchFromMask builds a merge patch of the fields listed in mask. Listed fields that are
+// unset in tracker become null and so are cleared.
+func trackerPatchFromMask(tracker *trackerpb.Tracker, paths []string) (model.TrackerPatch, []model.FieldError) {
+	var patch model.TrackerPatch
+	var errs []model.FieldError
+
+	for _, path := range paths {
+		switch path {
+		case "task":
+			patch.Task = model.NullableValue(tracker.GetTask())
+		case "project":
+			patch.Project = model.NullableFromPtr(tracker.Project)
+		case "start_time":
+			patch.StartTime = model.NullableFromPtr(timestampPtr(tracker.GetStartTime()))
+		case "end_time":
+			patch.EndTime = model.NullableFromPtr(timestampPtr(tracker.GetEndTime()))
+		default:
+			errs = append(errs, fieldError("update_mask", model.FieldErrorInvalid,
+				"update_mask can only contain task, project, start_time and end_time, not "+path))
+		}
+	}
+	return patch, errs
+}
+
+func (s *trackerServer) PatchTracker(ctx context.Context, req *trackerpb.PatchTrackerRequest) (*trackerpb.Tracker, error) {
+	id, err := grpcTrackerID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	patch, errs := trackerPatchFromMask(req.GetTracker(), req.GetUpdateMask().GetPaths())
+	if len(errs) > 0 {
+		return nil, invalidArgument(errs)
+	}
+	if errs := s.h.validateTrackerPatch(&patch); len(errs) > 0 {
+		return nil, invalidArgument(errs)
+	}
+
+	tracker, err := s.h.service.PatchTrackerService(ctx, id, patch, grpcVersions(req.Version))
+	if err != nil {
+		return nil, s.grpcError("PatchTracker", err)
+	}
+
+	s.h.logger.Infof("grpc: Successfully patched tracker with ID: %d", id)
+	return toProtoTracker(tracker), nil
+}
+
+func (s *trackerServer) DeleteTracker(ctx context.Context, req *trackerpb.DeleteTrackerRequest) (*emptypb.Empty, error) {
+	id, err := grpcTrackerID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.h.service.DeleteTrackerService(ctx, id, grpcVersions(req.Version)); err != nil {
+		return nil, s.grpcError("DeleteTracker", err)
+	}
+
+	s.h.logger.Infof("grpc: Successfully deleted tracker with ID: %d", id)
+	return &emptypb.Empty{}, nil
+}
+
+func (s *trackerServer) StartTimer(ctx context.Context, req *trackerpb.StartTimerRequest) (*trackerpb.Tracker, error) {
+	user, err := grpcUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	start := model.CreateTrackerRequest{
+		Task:      req.GetTask(),
+		Project:   req.Project,
+		StartTime: time.Now(),
+	}
+	if errs := validateStruct(&start); len(errs) > 0 {
+		return nil, invalidArgument(errs)
+	}
+
+	tracker, err := s.h.service.StartTimerService(ctx, user, start)
+	if err != nil {
+		return nil, s.grpcError("StartTimer", err)
+	}
+
+	s.h.logger.Infof("grpc: User ID %d started tracker ID %d", user.ID, tracker.ID)
+	return toProtoTracker(tracker), nil
+}
+
+func (s *trackerServer) StopTimer(ctx context.Context, req *trackerpb.StopTimerRequest) (*trackerpb.Tracker, error) {
+	user, err := grpcUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id, err := grpcTrackerID(req.GetTrackerId())
+	if err != nil {
+		return nil, err
+	}
+
+	tracker, err := s.h.service.StopTimerService(ctx, user, id)
+	if err != nil {
+		return nil, s.grpcError("StopTimer", err)
+	}
+
+	s.h.logger.Infof("grpc: User ID %d stopped tracker ID %d", user.ID, tracker.ID)
+	return toProtoTracker(tracker), nil
+}
+
+func (s *trackerServer) ListRunningTimers(ctx context.Context, req *trackerpb.ListRunningTimersRequest) (*trackerpb.ListRunningTimersResponse, error) {
+	user, err := grpcUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	timers, err := s.h.service.RunningTimersService(ctx, user.ID)
+	if err != nil {
+		return nil, s.grpcError("ListRunningTimers", err)
+	}
+
+	return &trackerpb.ListRunningTimersResponse{
+		Timers:     toProtoTrackers(timers),
+		ServerTime: timestamppb.Now(),
+	}, nil
+}
@@ -0,0 +1,53 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"timetracker/api/model"
+	"timetracker/api/trackerpb"
+	"timetracker/internal/config"
+	"timetracker/logger"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestListTrackersFilterByUser checks that gRPC limits user_id like the GraphQL filter: only
+// admins may list the trackers of other users.
+func TestListTrackersFilterByUser(t *testing.T) {
+	database, _ := openTestDatabase(t)
+	log := logger.NewLogger("grpc", filepath.Join(t.TempDir(), "api.log"))
+	cfg := &config.Config{}
+	server := &trackerServer{h: Handler(Service(Repository(database, log), cfg), log, cfg)}
+
+	signedIn := func(user *model.User, session *model.Session) context.Context {
+		ctx := context.WithValue(context.Background(), userContextKey, user)
+		return context.WithValue(ctx, sessionContextKey, session)
+	}
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		denied bool
+	}{
+		{"anonymous", context.Background(), true},
+		{"other user", signedIn(&model.User{ID: 8, Role: model.RoleUser}, &model.Session{UserID: 8}), true},
+		{"second factor pending", signedIn(&model.User{ID: 7, Role: model.RoleUser}, &model.Session{UserID: 7, MFAPending: true}), true},
+		{"same user", signedIn(&model.User{ID: 7, Role: model.RoleUser}, &model.Session{UserID: 7}), false},
+		{"admin", signedIn(&model.User{ID: 1, Role: model.RoleAdmin}, &model.Session{UserID: 1}), false},
+	}
+
+	userID := int64(7)
+	for _, tt := range tests {
+		_, err := server.ListTrackers(tt.ctx, &trackerpb.ListTrackersRequest{UserId: &userID})
+		if denied := status.Code(err) == codes.PermissionDenied; denied != tt.denied {
+			t.Errorf("%s: ListTrackers answered %v, want denied %v", tt.name, err, tt.denied)
+		}
+	}
+}
@@ -8,103 +8,69 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"mime"
 	"net/http"
 	"strconv"
 	"strings"
-	"time"
 	"timetracker/api/model"
+	"timetracker/internal/config"
 	"timetracker/logger"
+
+	"github.com/graph-gophers/graphql-go"
 )
 
 type handler struct {
 	service *service
 	logger  *logger.Logger
-}
+	cfg     *config.Config
+	live    *liveHub
+	graphql *graphql.Schema
 
-type ErrorResponse struct {
-	Error     string `json:"error"`
-	Message   string `json:"message,omitempty"`
-	Code      string `json:"code,omitempty"`
-	Timestamp string `json:"timestamp"`
+	metricsHandler http.Handler
 }
 
-func Handler(s *service, l *logger.Logger) *handler {
-	return &handler{
+func Handler(s *service, l *logger.Logger, cfg *config.Config) *handler {
+	h := &handler{
 		service: s,
 		logger:  l,
+		cfg:     cfg,
+		live:    newLiveHub(),
 	}
+	h.graphql = newGraphQLSchema(h)
+	h.metricsHandler = newMetricsHandler(s.repo.metrics, l)
+	return h
 }
 
-func (h *handler) sendErrorResponse(w http.ResponseWriter, statusCode int, errorMsg, userMsg, code string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-
-	response := ErrorResponse{
-		Error:     errorMsg,
-		Message:   userMsg,
-		Code:      code,
-		Timestamp: time.Now().Format(time.RFC3339),
-	}
-
-	if statusCode >= 500 {
-		h.logger.Errorf("[%s] %s - %s", code, errorMsg, userMsg)
-	} else if statusCode >= 400 {
-		h.logger.Warnf("[%s] %s - %s", code, errorMsg, userMsg)
-	} else {
-		h.logger.Infof("[%s] %s - %s", code, errorMsg, userMsg)
-	}
-
-	json.NewEncoder(w).Encode(response)
+// log returns the logger for req, whose records carry the request ID and the signed in user.
+func (h *handler) log(req *http.Request) *logger.Logger {
+	return h.logger.Ctx(req.Context())
 }
 
-func (h *handler) validateCreateTrackerRequest(req *model.CreateTrackerRequest) []string {
-	var errors []string
-
-	if req.Task == "" {
-		errors = append(errors, "task is required and cannot be empty")
-	} else if len(strings.TrimSpace(req.Task)) == 0 {
-		errors = append(errors, "task cannot contain only whitespace")
-	} else if len(req.Task) > 500 {
-		errors = append(errors, "task cannot exceed 500 characters")
-	}
-
-	if req.StartTime.IsZero() {
-		errors = append(errors, "start_time is required")
-	}
-
-	if req.EndTime != nil && !req.EndTime.IsZero() && req.EndTime.Before(req.StartTime) {
-		errors = append(errors, "end_time must be after start_time")
-	}
-
-	return errors
+// sendErrorResponse answers with an application/problem+json body. title is the short summary
+// of the problem, detail explains this occurrence to the user.
+func (h *handler) sendErrorResponse(w http.ResponseWriter, req *http.Request, statusCode int, title, detail, code string) {
+	h.sendProblem(w, model.Problem{
+		Type:      problemType(code),
+		Title:     title,
+		Status:    statusCode,
+		Detail:    detail,
+		Instance:  req.URL.Path,
+		Code:      code,
+		RequestID: requestID(req),
+	})
 }
 
-func (h *handler) validateUpdateTrackerRequest(req *model.UpdateTrackerRequest) []string {
-	var errors []string
-
-	if req.Task == nil && req.StartTime == nil && req.EndTime == nil {
-		errors = append(errors, "at least one field (task, start_time, or end_time) must be provided for update")
-		return errors
-	}
-
-	if req.Task != nil {
-		if *req.Task == "" {
-			errors = append(errors, "task cannot be empty")
-		} else if len(strings.TrimSpace(*req.Task)) == 0 {
-			errors = append(errors, "task cannot contain only whitespace")
-		} else if len(*req.Task) > 500 {
-			errors = append(errors, "task cannot exceed 500 characters")
+// validateTrackerPatch checks a merge patch. The rules of the fields are declared on TrackerPatch,
+// a patch must in addition change at least one field.
+func (h *handler) validateTrackerPatch(patch *model.TrackerPatch) []model.FieldError {
+	if patch.IsEmpty() {
+		return []model.FieldError{
+			fieldError("", model.FieldErrorRequired, "at least one field (task, project, start_time, or end_time) must be provided for patch"),
 		}
 	}
-
-	if req.StartTime != nil && req.EndTime != nil &&
-		!req.StartTime.IsZero() && !req.EndTime.IsZero() &&
-		req.EndTime.Before(*req.StartTime) {
-		errors = append(errors, "end_time must be after start_time")
-	}
-
-	return errors
+	return validateStruct(patch)
 }
 
 func (h *handler) extractIDFromPath(req *http.Request) (int, error) {
@@ -127,26 +93,26 @@ func (h *handler) extractIDFromPath(req *http.Request) (int, error) {
 
 // GetAllTrackersHandler retrieves all time tracking entries from the database.
 // It returns a JSON array of tracker objects ordered by creation date (newest first).
-// Each tracker contains: id, task, start_time, end_time, created_at, updated_at.
+// Each tracker contains: id, task, project, start_time, end_time, created_at, updated_at, version.
 // No request parameters are required.
 //
 // Returns:
 //   - 200 OK: Successfully retrieved trackers with array of tracker data (may be empty)
 //   - 500 Internal Server Error: Database or server errors
 func (h *handler) GetAllTrackersHandler(w http.ResponseWriter, r *http.Request) {
-	h.logger.Infof("GetAllTrackersHandler: Processing request from %s", r.RemoteAddr)
+	h.log(r).Infof("GetAllTrackersHandler: Processing request from %s", r.RemoteAddr)
 
-	trackers, err := h.service.GetAllTrackersService()
+	trackers, err := h.service.GetAllTrackersService(r.Context())
 	if err != nil {
-		h.logger.Errorf("GetAllTrackersHandler: Service error - %v", err)
-		h.sendErrorResponse(w, http.StatusInternalServerError,
+		h.log(r).Errorf("GetAllTrackersHandler: Service error - %v", err)
+		h.sendErrorResponse(w, r, http.StatusInternalServerError,
 			"Failed to fetch trackers",
 			"An error occurred while retrieving trackers from database",
 			"FETCH_ERROR")
 		return
 	}
 
-	h.logger.Infof("GetAllTrackersHandler: Successfully retrieved %d trackers", len(trackers))
+	h.log(r).Infof("GetAllTrackersHandler: Successfully retrieved %d trackers", len(trackers))
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(trackers)
@@ -155,170 +121,302 @@ func (h *handler) GetAllTrackersHandler(w http.ResponseWriter, r *http.Request)
 // CreateTrackerHandler creates a new time tracking entry in the database.
 // It expects a JSON payload containing:
 //   - task: string (required, 1-500 characters, cannot be empty/whitespace only)
+//   - project: string (optional, up to 200 characters)
 //   - start_time: timestamp (required, cannot be zero time)
-//   - end_time: timestamp (optional, must be after start_time if provided)
+//   - end_time: timestamp (optional, cannot be before start_time if provided)
+//
+// When the request carries a session the tracker is owned by its user.
 //
 // Returns:
 //   - 201 Created: Successfully created tracker with tracker data
 //   - 400 Bad Request: Invalid JSON payload or validation errors
 //   - 500 Internal Server Error: Database or server errors
 func (h *handler) CreateTrackerHandler(w http.ResponseWriter, req *http.Request) {
-	h.logger.Infof("CreateTrackerHandler: Processing request from %s", req.RemoteAddr)
+	h.log(req).Infof("CreateTrackerHandler: Processing request from %s", req.RemoteAddr)
 
 	var request model.CreateTrackerRequest
 
 	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
-		h.logger.Warnf("CreateTrackerHandler: Failed to decode JSON - %v", err)
-		h.sendErrorResponse(w, http.StatusBadRequest,
+		h.log(req).Warnf("CreateTrackerHandler: Failed to decode JSON - %v", err)
+		h.sendErrorResponse(w, req, http.StatusBadRequest,
 			"Invalid JSON payload",
 			"Request body must be valid JSON matching CreateTrackerRequest schema",
 			"INVALID_JSON")
 		return
 	}
 
-	if validationErrors := h.validateCreateTrackerRequest(&request); len(validationErrors) > 0 {
-		h.logger.Warnf("CreateTrackerHandler: Validation failed - %s", strings.Join(validationErrors, "; "))
-		h.sendErrorResponse(w, http.StatusBadRequest,
-			"Validation failed",
-			strings.Join(validationErrors, "; "),
-			"VALIDATION_ERROR")
+	if validationErrors := validateStruct(&request); len(validationErrors) > 0 {
+		h.log(req).Warnf("CreateTrackerHandler: Validation failed - %s", fieldErrorMessages(validationErrors))
+		h.sendValidationErrorResponse(w, req, validationErrors)
 		return
 	}
 
-	h.logger.Debugf("CreateTrackerHandler: Creating tracker with task: %s", request.Task)
-	tracker, err := h.service.CreateTrackerService(request)
+	if user, ok := verifiedUserFromContext(req.Context()); ok {
+		request.UserID = &user.ID
+	}
+
+	h.log(req).Debugf("CreateTrackerHandler: Creating tracker with task: %s", request.Task)
+	tracker, err := h.service.CreateTrackerService(req.Context(), request)
 	if err != nil {
-		h.logger.Errorf("CreateTrackerHandler: Service error - %v", err)
-		h.sendErrorResponse(w, http.StatusInternalServerError,
+		h.log(req).Errorf("CreateTrackerHandler: Service error - %v", err)
+		h.sendErrorResponse(w, req, http.StatusInternalServerError,
 			"Failed to create tracker",
 			"An error occurred while saving the tracker to database",
 			"CREATE_ERROR")
 		return
 	}
 
-	h.logger.Infof("CreateTrackerHandler: Successfully created tracker with ID: %d", tracker.ID)
+	h.log(req).With(logger.TrackerID(tracker.ID)).Infof("CreateTrackerHandler: Successfully created tracker with ID: %d", tracker.ID)
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", trackerETag(tracker))
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(tracker)
 }
 
-// UpdateTrackerHandler updates an existing time tracking entry by ID.
-// It extracts the tracker ID from the URL path parameter and expects a JSON payload with fields to update.
-// At least one field must be provided. All fields are optional in the request:
-//   - task: string (optional, 1-500 characters if provided, cannot be empty/whitespace only)
-//   - start_time: timestamp (optional, cannot be zero time if provided)
-//   - end_time: timestamp (optional, must be after start_time if both times are provided)
+// UpdateTrackerHandler replaces an existing time tracking entry by ID.
+// It extracts the tracker ID from the URL path parameter and expects the complete tracker as JSON payload.
+// Optional fields that are left out are cleared, use PATCH to change single fields:
+//   - task: string (required, 1-500 characters, cannot be empty/whitespace only)
+//   - project: string (optional, up to 200 characters)
+//   - start_time: timestamp (required, cannot be zero time)
+//   - end_time: timestamp (optional, cannot be before start_time if provided, absent means running)
+//
+// An If-Match header with the ETag of a previous read makes the update conditional, so concurrent
+// edits from another client are detected instead of silently overwritten.
 //
 // Returns:
-//   - 200 OK: Successfully updated tracker with updated tracker data
-//   - 400 Bad Request: Invalid ID parameter, JSON payload, or validation errors
+//   - 200 OK: Successfully updated tracker with updated tracker data and its new ETag
+//   - 400 Bad Request: Invalid ID parameter, If-Match header, JSON payload, or validation errors
 //   - 404 Not Found: Tracker with specified ID does not exist
+//   - 412 Precondition Failed: If-Match does not match, the body holds the current tracker
 //   - 500 Internal Server Error: Database or server errors
 func (h *handler) UpdateTrackerHandler(w http.ResponseWriter, req *http.Request) {
-	h.logger.Infof("UpdateTrackerHandler: Processing request from %s", req.RemoteAddr)
+	h.log(req).Infof("UpdateTrackerHandler: Processing request from %s", req.RemoteAddr)
 
 	id, err := h.extractIDFromPath(req)
 	if err != nil {
-		h.logger.Warnf("UpdateTrackerHandler: Invalid ID parameter - %v", err)
-		h.sendErrorResponse(w, http.StatusBadRequest,
+		h.log(req).Warnf("UpdateTrackerHandler: Invalid ID parameter - %v", err)
+		h.sendErrorResponse(w, req, http.StatusBadRequest,
 			"Invalid ID parameter",
 			err.Error(),
 			"INVALID_ID")
 		return
 	}
 
+	versions, ok := h.ifMatch(w, req)
+	if !ok {
+		return
+	}
+
 	var request model.UpdateTrackerRequest
 	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
-		h.logger.Warnf("UpdateTrackerHandler: Failed to decode JSON - %v", err)
-		h.sendErrorResponse(w, http.StatusBadRequest,
+		h.log(req).Warnf("UpdateTrackerHandler: Failed to decode JSON - %v", err)
+		h.sendErrorResponse(w, req, http.StatusBadRequest,
 			"Invalid JSON payload",
 			"Request body must be valid JSON matching UpdateTrackerRequest schema",
 			"INVALID_JSON")
 		return
 	}
 
-	if validationErrors := h.validateUpdateTrackerRequest(&request); len(validationErrors) > 0 {
-		h.logger.Warnf("UpdateTrackerHandler: Validation failed - %s", strings.Join(validationErrors, "; "))
-		h.sendErrorResponse(w, http.StatusBadRequest,
-			"Validation failed",
-			strings.Join(validationErrors, "; "),
-			"VALIDATION_ERROR")
+	if validationErrors := validateStruct(&request); len(validationErrors) > 0 {
+		h.log(req).Warnf("UpdateTrackerHandler: Validation failed - %s", fieldErrorMessages(validationErrors))
+		h.sendValidationErrorResponse(w, req, validationErrors)
 		return
 	}
 
-	h.logger.Debugf("UpdateTrackerHandler: Updating tracker ID: %d", id)
-	tracker, err := h.service.UpdateTrackerService(id, request)
+	h.log(req).Debugf("UpdateTrackerHandler: Updating tracker ID: %d", id)
+	tracker, err := h.service.UpdateTrackerService(req.Context(), id, request, versions)
 	if err != nil {
-		h.logger.Errorf("UpdateTrackerHandler: Service error for ID %d - %v", id, err)
+		h.log(req).Errorf("UpdateTrackerHandler: Service error for ID %d - %v", id, err)
+		if errors.Is(err, errVersionMismatch) {
+			h.sendPreconditionFailed(w, req, id)
+			return
+		}
 		if strings.Contains(err.Error(), "not found") {
-			h.sendErrorResponse(w, http.StatusNotFound,
+			h.sendErrorResponse(w, req, http.StatusNotFound,
 				"Tracker not found",
 				fmt.Sprintf("No tracker exists with ID %d", id),
 				"NOT_FOUND")
 			return
 		}
 
-		h.sendErrorResponse(w, http.StatusInternalServerError,
+		h.sendErrorResponse(w, req, http.StatusInternalServerError,
 			"Failed to update tracker",
 			"An error occurred while updating the tracker in database",
 			"UPDATE_ERROR")
 		return
 	}
 
-	h.logger.Infof("UpdateTrackerHandler: Successfully updated tracker ID: %d", tracker.ID)
+	h.log(req).With(logger.TrackerID(tracker.ID)).Infof("UpdateTrackerHandler: Successfully updated tracker ID: %d", tracker.ID)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", trackerETag(tracker))
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(tracker)
+}
+
+// PatchTrackerHandler partially updates an existing time tracking entry by ID.
+// It expects an RFC 7396 JSON merge patch (Content-Type application/merge-patch+json or application/json)
+// holding only the fields to change. Members set to null are cleared:
+//   - task: string (optional, 1-500 characters, cannot be null)
+//   - project: string or null (optional, up to 200 characters, null removes the project)
+//   - start_time: timestamp (optional, cannot be null)
+//   - end_time: timestamp or null (optional, null sets the tracker back to running)
+//
+// The end_time of the merged tracker must still be after its start_time. Like PUT, PATCH honors If-Match.
+//
+// Returns:
+//   - 200 OK: Successfully patched tracker with updated tracker data and its new ETag
+//   - 400 Bad Request: Invalid ID parameter, If-Match header, JSON payload, or validation errors
+//   - 404 Not Found: Tracker with specified ID does not exist
+//   - 412 Precondition Failed: If-Match does not match, the body holds the current tracker
+//   - 415 Unsupported Media Type: The body is not a JSON merge patch
+//   - 500 Internal Server Error: Database or server errors
+func (h *handler) PatchTrackerHandler(w http.ResponseWriter, req *http.Request) {
+	h.log(req).Infof("PatchTrackerHandler: Processing request from %s", req.RemoteAddr)
+
+	id, err := h.extractIDFromPath(req)
+	if err != nil {
+		h.log(req).Warnf("PatchTrackerHandler: Invalid ID parameter - %v", err)
+		h.sendErrorResponse(w, req, http.StatusBadRequest,
+			"Invalid ID parameter",
+			err.Error(),
+			"INVALID_ID")
+		return
+	}
+
+	versions, ok := h.ifMatch(w, req)
+	if !ok {
+		return
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type")); err != nil ||
+		(mediaType != "application/merge-patch+json" && mediaType != "application/json") {
+		w.Header().Set("Accept-Patch", "application/merge-patch+json")
+		h.sendErrorResponse(w, req, http.StatusUnsupportedMediaType,
+			"Unsupported media type",
+			"PATCH requires Content-Type application/merge-patch+json",
+			"UNSUPPORTED_MEDIA_TYPE")
+		return
+	}
+
+	var patch model.TrackerPatch
+	if err := json.NewDecoder(req.Body).Decode(&patch); err != nil {
+		h.log(req).Warnf("PatchTrackerHandler: Failed to decode JSON - %v", err)
+		h.sendErrorResponse(w, req, http.StatusBadRequest,
+			"Invalid JSON payload",
+			"Request body must be a JSON merge patch object of a tracker",
+			"INVALID_JSON")
+		return
+	}
+
+	if validationErrors := h.validateTrackerPatch(&patch); len(validationErrors) > 0 {
+		h.log(req).Warnf("PatchTrackerHandler: Validation failed - %s", fieldErrorMessages(validationErrors))
+		h.sendValidationErrorResponse(w, req, validationErrors)
+		return
+	}
+
+	h.log(req).Debugf("PatchTrackerHandler: Patching tracker ID: %d", id)
+	tracker, err := h.service.PatchTrackerService(req.Context(), id, patch, versions)
+	if err != nil {
+		h.log(req).Errorf("PatchTrackerHandler: Service error for ID %d - %v", id, err)
+		if errors.Is(err, errVersionMismatch) {
+			h.sendPreconditionFailed(w, req, id)
+			return
+		}
+		if errors.Is(err, errPatchConflict) {
+			h.sendErrorResponse(w, req, http.StatusConflict,
+				"Tracker changed concurrently",
+				"The tracker kept changing while the patch was applied, retry it or send If-Match",
+				"CONFLICT")
+			return
+		}
+		if errors.Is(err, errInvalidPatch) {
+			h.sendValidationErrorResponse(w, req, []model.FieldError{
+				fieldError("end_time", model.FieldErrorOutOfOrder, "end_time cannot be before start_time"),
+			})
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			h.sendErrorResponse(w, req, http.StatusNotFound,
+				"Tracker not found",
+				fmt.Sprintf("No tracker exists with ID %d", id),
+				"NOT_FOUND")
+			return
+		}
+
+		h.sendErrorResponse(w, req, http.StatusInternalServerError,
+			"Failed to patch tracker",
+			"An error occurred while updating the tracker in database",
+			"UPDATE_ERROR")
+		return
+	}
+
+	h.log(req).With(logger.TrackerID(tracker.ID)).Infof("PatchTrackerHandler: Successfully patched tracker ID: %d", tracker.ID)
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", trackerETag(tracker))
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(tracker)
 }
 
 // DeleteTrackerHandler removes a time tracking entry from the database by ID.
 // It extracts the tracker ID from the URL path parameter and permanently deletes the record.
-// This operation cannot be undone.
+// This operation cannot be undone. Like updates, it honors an If-Match header.
 //
 // Returns:
 //   - 204 No Content: Successfully deleted tracker (no response body)
-//   - 400 Bad Request: Invalid ID parameter
+//   - 400 Bad Request: Invalid ID parameter or If-Match header
 //   - 404 Not Found: Tracker with specified ID does not exist
+//   - 412 Precondition Failed: If-Match does not match, the body holds the current tracker
 //   - 500 Internal Server Error: Database or server errors
 func (h *handler) DeleteTrackerHandler(w http.ResponseWriter, req *http.Request) {
-	h.logger.Infof("DeleteTrackerHandler: Processing request from %s", req.RemoteAddr)
+	h.log(req).Infof("DeleteTrackerHandler: Processing request from %s", req.RemoteAddr)
 
 	id, err := h.extractIDFromPath(req)
 	if err != nil {
-		h.logger.Warnf("DeleteTrackerHandler: Invalid ID parameter - %v", err)
-		h.sendErrorResponse(w, http.StatusBadRequest,
+		h.log(req).Warnf("DeleteTrackerHandler: Invalid ID parameter - %v", err)
+		h.sendErrorResponse(w, req, http.StatusBadRequest,
 			"Invalid ID parameter",
 			err.Error(),
 			"INVALID_ID")
 		return
 	}
 
-	h.logger.Debugf("DeleteTrackerHandler: Deleting tracker ID: %d", id)
-	err = h.service.DeleteTrackerService(id)
+	versions, ok := h.ifMatch(w, req)
+	if !ok {
+		return
+	}
+
+	h.log(req).Debugf("DeleteTrackerHandler: Deleting tracker ID: %d", id)
+	err = h.service.DeleteTrackerService(req.Context(), id, versions)
 	if err != nil {
-		h.logger.Errorf("DeleteTrackerHandler: Service error for ID %d - %v", id, err)
+		h.log(req).Errorf("DeleteTrackerHandler: Service error for ID %d - %v", id, err)
+		if errors.Is(err, errVersionMismatch) {
+			h.sendPreconditionFailed(w, req, id)
+			return
+		}
 		if strings.Contains(err.Error(), "not found") {
-			h.sendErrorResponse(w, http.StatusNotFound,
+			h.sendErrorResponse(w, req, http.StatusNotFound,
 				"Tracker not found",
 				fmt.Sprintf("No tracker exists with ID %d", id),
 				"NOT_FOUND")
 			return
 		}
 
-		h.sendErrorResponse(w, http.StatusInternalServerError,
+		h.sendErrorResponse(w, req, http.StatusInternalServerError,
 			"Failed to delete tracker",
 			"An error occurred while deleting the tracker from database",
 			"DELETE_ERROR")
 		return
 	}
 
-	h.logger.Infof("DeleteTrackerHandler: Successfully deleted tracker ID: %d", id)
+	h.log(req).With(logger.TrackerID(id)).Infof("DeleteTrackerHandler: Successfully deleted tracker ID: %d", id)
 	w.WriteHeader(http.StatusNoContent)
 }
 
 // FindTrackerByIDHandler retrieves a specific time tracking entry by its ID.
 // It extracts the tracker ID from the URL path parameter and returns the matching record as JSON.
-// The response includes all tracker fields: id, task, start_time, end_time, created_at, updated_at.
+// The response includes all tracker fields: id, task, project, start_time, end_time, created_at, updated_at, version.
+// The ETag header carries the version for conditional updates and deletes.
 //
 // Returns:
 //   - 200 OK: Successfully retrieved tracker with tracker data
@@ -326,39 +424,40 @@ func (h *handler) DeleteTrackerHandler(w http.ResponseWriter, req *http.Request)
 //   - 404 Not Found: Tracker with specified ID does not exist
 //   - 500 Internal Server Error: Database or server errors
 func (h *handler) FindTrackerByIDHandler(w http.ResponseWriter, req *http.Request) {
-	h.logger.Infof("FindTrackerByIDHandler: Processing request from %s", req.RemoteAddr)
+	h.log(req).Infof("FindTrackerByIDHandler: Processing request from %s", req.RemoteAddr)
 
 	id, err := h.extractIDFromPath(req)
 	if err != nil {
-		h.logger.Warnf("FindTrackerByIDHandler: Invalid ID parameter - %v", err)
-		h.sendErrorResponse(w, http.StatusBadRequest,
+		h.log(req).Warnf("FindTrackerByIDHandler: Invalid ID parameter - %v", err)
+		h.sendErrorResponse(w, req, http.StatusBadRequest,
 			"Invalid ID parameter",
 			err.Error(),
 			"INVALID_ID")
 		return
 	}
 
-	h.logger.Debugf("FindTrackerByIDHandler: Fetching tracker ID: %d", id)
-	tracker, err := h.service.GetTrackerByIDService(id)
+	h.log(req).Debugf("FindTrackerByIDHandler: Fetching tracker ID: %d", id)
+	tracker, err := h.service.GetTrackerByIDService(req.Context(), id)
 	if err != nil {
-		h.logger.Errorf("FindTrackerByIDHandler: Service error for ID %d - %v", id, err)
+		h.log(req).Errorf("FindTrackerByIDHandler: Service error for ID %d - %v", id, err)
 		if strings.Contains(err.Error(), "not found") {
-			h.sendErrorResponse(w, http.StatusNotFound,
+			h.sendErrorResponse(w, req, http.StatusNotFound,
 				"Tracker not found",
 				fmt.Sprintf("No tracker exists with ID %d", id),
 				"NOT_FOUND")
 			return
 		}
 
-		h.sendErrorResponse(w, http.StatusInternalServerError,
+		h.sendErrorResponse(w, req, http.StatusInternalServerError,
 			"Failed to fetch tracker",
 			"An error occurred while retrieving the tracker from database",
 			"FETCH_ERROR")
 		return
 	}
 
-	h.logger.Infof("FindTrackerByIDHandler: Successfully retrieved tracker ID: %d", tracker.ID)
+	h.log(req).With(logger.TrackerID(tracker.ID)).Infof("FindTrackerByIDHandler: Successfully retrieved tracker ID: %d", tracker.ID)
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", trackerETag(tracker))
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(tracker)
 }
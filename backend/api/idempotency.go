@@ -0,0 +1,203 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	idempotencyKeyHeader    = "Idempotency-Key"
+	maxIdempotencyKeyLength = 255
+	// maxIdempotentBodyBytes bounds the request bodies that are buffered to compute the request hash.
+	maxIdempotentBodyBytes = 1 << 20
+)
+
+// replayedHeaders are the response headers stored with an idempotent response and sent again on replay.
+var replayedHeaders = []string{"Content-Type", "ETag", "Location", "Cache-Control", "Deprecation", "Sunset", "Link"}
+
+// idempotencyRecorder passes the response through to the client and keeps a copy for replays.
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *idempotencyRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *idempotencyRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
+
+func (rec *idempotencyRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// idempotency makes retries of mutating requests safe. The first request with an Idempotency-Key
+// is executed and its response stored; repeating the key with the same method, path and body
+// replays that response instead of executing the request again. Reusing a key for a different
+// request is rejected with 422. Keys are scoped to the signed in user, see idempotencyScope.
+// Responses with a 5xx status are not stored so those can be retried. The response is stored, or the key released, even when the client hung up meanwhile,
+// as that is exactly when it will retry.
+func (r *router) idempotency(next http.Handler) http.Handler {
+	if !r.cfg.Idempotency.Enabled {
+		r.logger.Infof("Idempotency keys disabled")
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		key := req.Header.Get(idempotencyKeyHeader)
+		if key == "" || !isMutatingMethod(req.Method) {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			r.handler.sendErrorResponse(w, req, http.StatusBadRequest,
+				"Invalid Idempotency-Key",
+				fmt.Sprintf("Idempotency-Key cannot exceed %d characters", maxIdempotencyKeyLength),
+				"INVALID_IDEMPOTENCY_KEY")
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(req.Body, maxIdempotentBodyBytes+1))
+		if err != nil {
+			r.handler.sendErrorResponse(w, req, http.StatusBadRequest,
+				"Invalid request body",
+				"The request body could not be read",
+				"INVALID_BODY")
+			return
+		}
+		if len(body) > maxIdempotentBodyBytes {
+			r.handler.sendErrorResponse(w, req, http.StatusRequestEntityTooLarge,
+				"Request body too large",
+				fmt.Sprintf("Requests with an Idempotency-Key cannot exceed %d bytes", maxIdempotentBodyBytes),
+				"BODY_TOO_LARGE")
+			return
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		scope := r.idempotencyScope(req)
+		requestHash := idempotencyRequestHash(req, body)
+
+		record, lockToken, err := r.handler.service.BeginIdempotentRequestService(req.Context(), scope, key, requestHash)
+		switch {
+		case errors.Is(err, errIdempotencyKeyReused):
+			r.handler.sendErrorResponse(w, req, http.StatusUnprocessableEntity,
+				"Idempotency-Key reused",
+				"This Idempotency-Key was already used for a different request",
+				"IDEMPOTENCY_KEY_REUSED")
+			return
+		case errors.Is(err, errIdempotencyKeyInProgress):
+			w.Header().Set("Retry-After", "1")
+			r.handler.sendErrorResponse(w, req, http.StatusConflict,
+				"Request in progress",
+				"A request with this Idempotency-Key is still being processed",
+				"IDEMPOTENCY_KEY_IN_PROGRESS")
+			return
+		case err != nil:
+			r.logger.Errorf("idempotency: Failed to reserve key - %v", err)
+			r.handler.sendErrorResponse(w, req, http.StatusInternalServerError,
+				"Failed to process Idempotency-Key",
+				"An error occurred while checking the Idempotency-Key",
+				"IDEMPOTENCY_ERROR")
+			return
+		}
+
+		if record != nil {
+			r.logger.Infof("idempotency: Replaying response %d for %s %s", *record.StatusCode, req.Method, req.URL.Path)
+			for name, values := range record.ResponseHeaders {
+				w.Header()[name] = values
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(*record.StatusCode)
+			w.Write(record.ResponseBody)
+			return
+		}
+
+		// The request context is cancelled once the client disconnects, but the key still has to
+		// be completed or released.
+		ctx := context.WithoutCancel(req.Context())
+		stored := false
+		defer func() {
+			// Free the key when the response is not stored, including when the handler panics,
+			// so the client is not locked out of retrying until the key expires.
+			if !stored {
+				if err := r.handler.service.ReleaseIdempotentRequestService(ctx, scope, key, lockToken); err != nil {
+					r.logger.Errorf("idempotency: Failed to release key - %v", err)
+				}
+			}
+		}()
+
+		rec := &idempotencyRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, req)
+
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		if status >= 500 {
+			return
+		}
+
+		headers := map[string][]string{}
+		for _, name := range replayedHeaders {
+			if values := w.Header().Values(name); len(values) > 0 {
+				headers[name] = values
+			}
+		}
+		if err := r.handler.service.CompleteIdempotentRequestService(ctx, scope, key, lockToken, status, headers, rec.body.Bytes()); err != nil {
+			r.logger.Errorf("idempotency: Failed to store response - %v", err)
+			return
+		}
+		stored = true
+	})
+}
+
+// idempotencyScope is the namespace of the keys of req: the user of a session that passed
+// two-factor verification, otherwise the client IP, so anonymous apps can retry creating a
+// tracker as well. Sessions still waiting for their second factor count as anonymous, like in the
+// handlers, and never see the stored responses of their user.
+func (r *router) idempotencyScope(req *http.Request) string {
+	if user, ok := verifiedUserFromContext(req.Context()); ok {
+		return fmt.Sprintf("user:%d", user.ID)
+	}
+	return "anon:" + clientIP(req, r.cfg.RateLimit.TrustProxyHeaders)
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// idempotencyRequestHash identifies a request by method, path and body, so a key sent again with
+// anything else is detected as reuse.
+func idempotencyRequestHash(req *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(req.Method + " " + req.URL.Path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
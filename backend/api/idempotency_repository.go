@@ -0,0 +1,123 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+	"timetracker/api/model"
+	"timetracker/errorutil"
+)
+
+// ReserveIdempotencyKey claims key for a new request, locked by lockToken. It returns nil when
+// the key was free, or held by the same request for longer than lockTimeout without finishing,
+// otherwise the record of the earlier request with the same key. Expired keys are freed first.
+func (r *repository) ReserveIdempotencyKey(ctx context.Context, scope, key, requestHash, lockToken string, expiresAt time.Time, lockTimeout time.Duration) (*model.IdempotencyRecord, error) {
+	ctx, span := startChildSpan(ctx, "repository.ReserveIdempotencyKey")
+	defer span.End()
+
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at < NOW()`); err != nil {
+		return nil, errorutil.Wrap(err, "Failed to delete expired idempotency keys")
+	}
+
+	// A request still in progress after lockTimeout is assumed dead, e.g. the server stopped while
+	// handling it, and a retry of the same request takes the key over.
+	query := `
+		INSERT INTO idempotency_keys (scope, key, request_hash, lock_token, locked_at, expires_at)
+		VALUES ($1, $2, $3, $4, NOW(), $5)
+		ON CONFLICT (scope, key) DO UPDATE
+		SET lock_token = EXCLUDED.lock_token, locked_at = NOW(), expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.status_code IS NULL
+			AND idempotency_keys.request_hash = EXCLUDED.request_hash
+			AND idempotency_keys.locked_at < NOW() - make_interval(secs => $6)`
+
+	result, err := r.db.ExecContext(ctx, query, scope, key, requestHash, lockToken, expiresAt, lockTimeout.Seconds())
+	if err != nil {
+		return nil, errorutil.Wrap(err, "Failed to reserve idempotency key")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, errorutil.Wrap(err, "Failed to get rows affected")
+	}
+	if rowsAffected == 1 {
+		return nil, nil
+	}
+
+	query = `
+		SELECT scope, key, request_hash, status_code, response_headers, response_body, expires_at, created_at
+		FROM idempotency_keys
+		WHERE scope = $1 AND key = $2`
+
+	var record model.IdempotencyRecord
+	var headers []byte
+	err = r.db.QueryRowContext(ctx, query, scope, key).Scan(&record.Scope, &record.Key, &record.RequestHash, &record.StatusCode,
+		&headers, &record.ResponseBody, &record.ExpiresAt, &record.CreatedAt)
+
+	if err == sql.ErrNoRows {
+		// The earlier request was released between the insert and the select.
+		return nil, errorutil.New("idempotency key not found")
+	}
+	if err != nil {
+		return nil, errorutil.Wrap(err, "Failed to get idempotency key")
+	}
+
+	if len(headers) > 0 {
+		if err := json.Unmarshal(headers, &record.ResponseHeaders); err != nil {
+			return nil, errorutil.Wrap(err, "Failed to decode stored response headers")
+		}
+	}
+
+	return &record, nil
+}
+
+// CompleteIdempotencyKey stores the response of the request that reserved key with lockToken.
+// It fails when a retry took the key over in the meantime.
+func (r *repository) CompleteIdempotencyKey(ctx context.Context, scope, key, lockToken string, statusCode int, headers map[string][]string, body []byte) error {
+	ctx, span := startChildSpan(ctx, "repository.CompleteIdempotencyKey")
+	defer span.End()
+
+	encodedHeaders, err := json.Marshal(headers)
+	if err != nil {
+		return errorutil.Wrap(err, "Failed to encode response headers")
+	}
+
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = $4, response_headers = $5, response_body = $6
+		WHERE scope = $1 AND key = $2 AND lock_token = $3 AND status_code IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, scope, key, lockToken, statusCode, string(encodedHeaders), body)
+	if err != nil {
+		return errorutil.Wrap(err, "Failed to store idempotent response")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errorutil.Wrap(err, "Failed to get rows affected")
+	}
+	if rowsAffected == 0 {
+		return errorutil.New("idempotency key was taken over by a retry")
+	}
+
+	r.logger.Debugf("Stored response %d for idempotency key %s", statusCode, key)
+	return nil
+}
+
+// ReleaseIdempotencyKey frees key again, so a retry of a request that failed is executed anew.
+// A key taken over by a retry in the meantime is left alone.
+func (r *repository) ReleaseIdempotencyKey(ctx context.Context, scope, key, lockToken string) error {
+	ctx, span := startChildSpan(ctx, "repository.ReleaseIdempotencyKey")
+	defer span.End()
+
+	query := `DELETE FROM idempotency_keys WHERE scope = $1 AND key = $2 AND lock_token = $3 AND status_code IS NULL`
+	if _, err := r.db.ExecContext(ctx, query, scope, key, lockToken); err != nil {
+		return errorutil.Wrap(err, "Failed to release idempotency key")
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"time"
+	"timetracker/api/model"
+	"timetracker/errorutil"
+)
+
+var (
+	errIdempotencyKeyReused     = errorutil.New("idempotency key was used with a different request")
+	errIdempotencyKeyInProgress = errorutil.New("request with this idempotency key is still in progress")
+)
+
+// BeginIdempotentRequestService reserves key for the request identified by requestHash. It returns
+// the stored record when the request was already completed and its response should be replayed.
+// Otherwise the request has to be executed, and the returned lock token is to be passed on to
+// CompleteIdempotentRequestService or ReleaseIdempotentRequestService.
+func (s *service) BeginIdempotentRequestService(ctx context.Context, scope, key, requestHash string) (*model.IdempotencyRecord, string, error) {
+	ctx, span := tracer.Start(ctx, "service.BeginIdempotentRequestService")
+	defer span.End()
+
+	ttl := time.Duration(s.cfg.Idempotency.TTLHours) * time.Hour
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	lockTimeout := time.Duration(s.cfg.Idempotency.LockTimeoutSeconds) * time.Second
+	if lockTimeout <= 0 {
+		lockTimeout = time.Minute
+	}
+
+	lockToken := rand.Text()
+	record, err := s.repo.ReserveIdempotencyKey(ctx, scope, key, requestHash, lockToken, time.Now().Add(ttl), lockTimeout)
+	if err != nil {
+		return nil, "", err
+	}
+	if record == nil {
+		return nil, lockToken, nil
+	}
+
+	if record.RequestHash != requestHash {
+		return nil, "", errIdempotencyKeyReused
+	}
+	if record.StatusCode == nil {
+		return nil, "", errIdempotencyKeyInProgress
+	}
+	return record, "", nil
+}
+
+func (s *service) CompleteIdempotentRequestService(ctx context.Context, scope, key, lockToken string, statusCode int, headers map[string][]string, body []byte) error {
+	ctx, span := tracer.Start(ctx, "service.CompleteIdempotentRequestService")
+	defer span.End()
+
+	return s.repo.CompleteIdempotencyKey(ctx, scope, key, lockToken, statusCode, headers, body)
+}
+
+func (s *service) ReleaseIdempotentRequestService(ctx context.Context, scope, key, lockToken string) error {
+	ctx, span := tracer.Start(ctx, "service.ReleaseIdempotentRequestService")
+	defer span.End()
+
+	return s.repo.ReleaseIdempotencyKey(ctx, scope, key, lockToken)
+}
@@ -0,0 +1,604 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"timetracker/api/model"
+
+	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	liveWriteTimeout = 10 * time.Second
+	// livePongTimeout closes connections whose client stopped answering the pings sent every livePingInterval.
+	livePongTimeout   = 60 * time.Second
+	livePingInterval  = 30 * time.Second
+	liveMaxMessage    = 4096
+	liveClientBacklog = 32
+)
+
+// liveClient is one socket connection. Only its write loop writes to the connection, everything
+// else queues messages on send. acked holds the version of each tracker the client changed itself
+// and was already answered for, so the broadcast of that change is not sent to it again.
+type liveClient struct {
+	userID     int
+	send       chan []byte
+	subscribed bool
+	acked      map[int]int
+}
+
+// liveHub keeps the live sockets per user together with the running timers of users that are
+// connected. It is fed with the tracker events of all instances, see RunLiveTimers.
+type liveHub struct {
+	mu      sync.Mutex
+	clients map[int]map[*liveClient]struct{}
+	running map[int][]model.Tracker
+}
+
+func newLiveHub() *liveHub {
+	return &liveHub{
+		clients: make(map[int]map[*liveClient]struct{}),
+		running: make(map[int][]model.Tracker),
+	}
+}
+
+func (hub *liveHub) register(client *liveClient) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	if hub.clients[client.userID] == nil {
+		hub.clients[client.userID] = make(map[*liveClient]struct{})
+	}
+	hub.clients[client.userID][client] = struct{}{}
+}
+
+func (hub *liveHub) unregister(client *liveClient) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	hub.removeLocked(client)
+}
+
+func (hub *liveHub) removeLocked(client *liveClient) {
+	clients := hub.clients[client.userID]
+	if _, ok := clients[client]; !ok {
+		return
+	}
+
+	delete(clients, client)
+	close(client.send)
+	if len(clients) == 0 {
+		delete(hub.clients, client.userID)
+		delete(hub.running, client.userID)
+	}
+}
+
+// subscribe marks client as subscribed and stores the running timers of its user.
+func (hub *liveHub) subscribe(client *liveClient, running []model.Tracker) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	client.subscribed = true
+	hub.running[client.userID] = running
+}
+
+// connected tells whether userID has an open socket.
+func (hub *liveHub) connected(userID int) bool {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	_, ok := hub.clients[userID]
+	return ok
+}
+
+// users returns the users with an open socket.
+func (hub *liveHub) users() []int {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	users := make([]int, 0, len(hub.clients))
+	for userID := range hub.clients {
+		users = append(users, userID)
+	}
+	return users
+}
+
+// change replaces the running timers of userID by running after tracker was changed, and sends the
+// subscribed clients a started or stopped message when the change started or stopped it, or the
+// new state when it changed a running timer.
+func (hub *liveHub) change(userID int, running []model.Tracker, tracker model.Tracker, deleted bool) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	if _, ok := hub.clients[userID]; !ok {
+		return
+	}
+	previous := hub.running[userID]
+	hub.running[userID] = running
+
+	isRunning := func(trackers []model.Tracker) (*model.Tracker, bool) {
+		i := slices.IndexFunc(trackers, func(t model.Tracker) bool { return t.ID == tracker.ID })
+		if i < 0 {
+			return nil, false
+		}
+		return &trackers[i], true
+	}
+	before, wasRunning := isRunning(previous)
+	_, nowRunning := isRunning(running)
+
+	msg := model.LiveMessage{Tracker: &tracker, ServerTime: time.Now().UTC()}
+	switch {
+	case nowRunning && !wasRunning:
+		msg.Type = model.LiveStarted
+	case wasRunning && !nowRunning:
+		msg.Type = model.LiveStopped
+		if deleted {
+			msg.Tracker = before
+		}
+	case nowRunning:
+		msg.Type = model.LiveState
+		msg.Tracker = nil
+	default:
+		return
+	}
+
+	msg.Running = liveTimers(running, msg.ServerTime)
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+
+	for client := range hub.clients[userID] {
+		if version, ok := client.acked[tracker.ID]; ok && version == tracker.Version {
+			delete(client.acked, tracker.ID)
+			continue
+		}
+		if client.subscribed {
+			hub.queueLocked(client, payload)
+		}
+	}
+}
+
+// refresh replaces the running timers of userID and sends them to its subscribed clients as state,
+// after changes may have been missed.
+func (hub *liveHub) refresh(userID int, running []model.Tracker) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	if _, ok := hub.clients[userID]; !ok {
+		return
+	}
+	hub.running[userID] = running
+
+	now := time.Now().UTC()
+	payload, err := json.Marshal(model.LiveMessage{Type: model.LiveState, Running: liveTimers(running, now), ServerTime: now})
+	if err != nil {
+		return
+	}
+	for client := range hub.clients[userID] {
+		if client.subscribed {
+			hub.queueLocked(client, payload)
+		}
+	}
+}
+
+// ack answers client for the change it made to tracker. The broadcast of the same change is then
+// not sent to client again.
+func (hub *liveHub) ack(client *liveClient, tracker *model.Tracker, msg model.LiveMessage) {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	if client.subscribed {
+		client.acked[tracker.ID] = tracker.Version
+	}
+	hub.queueLocked(client, payload)
+}
+
+// reply sends msg to client only.
+func (hub *liveHub) reply(client *liveClient, msg model.LiveMessage) {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	hub.queueLocked(client, payload)
+}
+
+// queueLocked never blocks. A client that cannot keep up is disconnected and has to subscribe again.
+func (hub *liveHub) queueLocked(client *liveClient, payload []byte) {
+	if _, ok := hub.clients[client.userID][client]; !ok {
+		return
+	}
+
+	select {
+	case client.send <- payload:
+	default:
+		hub.removeLocked(client)
+	}
+}
+
+// tick sends the elapsed time of the running timers to the subscribed clients of every user with
+// a running timer.
+func (hub *liveHub) tick(now time.Time) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	for userID, running := range hub.running {
+		if len(running) == 0 {
+			continue
+		}
+
+		payload, err := json.Marshal(model.LiveMessage{
+			Type:       model.LiveTick,
+			Running:    liveTimers(running, now),
+			ServerTime: now,
+		})
+		if err != nil {
+			continue
+		}
+
+		for client := range hub.clients[userID] {
+			if client.subscribed {
+				hub.queueLocked(client, payload)
+			}
+		}
+	}
+}
+
+// RunLiveTimers feeds the live sockets until ctx is done: every tracker change recorded by the
+// database, whichever API or instance made it, is sent to the sockets of the tracker's user, and
+// the elapsed time of running timers is sent every tick. The changes come from the tracker event
+// listener, see ListenTrackerEvents.
+func (h *handler) RunLiveTimers(ctx context.Context) {
+	interval := time.Duration(h.cfg.WebSocket.TickSeconds) * time.Second
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	sub := h.service.SubscribeTrackerEventsService()
+	defer func() { h.service.UnsubscribeTrackerEventsService(sub) }()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case event, ok := <-sub.events:
+			if !ok {
+				// Dropped for falling behind, changes may be lost: subscribe again and reload.
+				h.logger.Warnf("RunLiveTimers: Fell behind the tracker events, reloading running timers")
+				sub = h.service.SubscribeTrackerEventsService()
+				for _, userID := range h.live.users() {
+					if running, err := h.service.RunningTimersService(ctx, userID); err == nil {
+						h.live.refresh(userID, running)
+					}
+				}
+				continue
+			}
+			h.applyLiveEvent(ctx, event)
+
+		case now := <-ticker.C:
+			h.live.tick(now.UTC())
+		}
+	}
+}
+
+// applyLiveEvent sends a tracker change to the sockets of the tracker's user, if it has any.
+func (h *handler) applyLiveEvent(ctx context.Context, event model.TrackerEvent) {
+	var tracker model.Tracker
+	if err := json.Unmarshal(event.Data, &tracker); err != nil {
+		h.logger.Warnf("applyLiveEvent: Invalid payload of event %d - %v", event.ID, err)
+		return
+	}
+	if tracker.UserID == nil || !h.live.connected(*tracker.UserID) {
+		return
+	}
+	tracker.Version = event.Version
+
+	running, err := h.service.RunningTimersService(ctx, *tracker.UserID)
+	if err != nil {
+		h.logger.Errorf("applyLiveEvent: Failed to load running timers for user ID %d - %v", *tracker.UserID, err)
+		return
+	}
+	h.live.change(*tracker.UserID, running, tracker, event.Type == model.TrackerEventDeleted)
+}
+
+func liveTimers(running []model.Tracker, now time.Time) []model.LiveTimer {
+	timers := make([]model.LiveTimer, 0, len(running))
+	for _, tracker := range running {
+		timers = append(timers, model.LiveTimer{
+			Tracker:        tracker,
+			ElapsedSeconds: int64(max(now.Sub(tracker.StartTime), 0) / time.Second),
+		})
+	}
+	return timers
+}
+
+// checkLiveOrigin accepts clients without an Origin header, such as the mobile app, same origin
+// pages and the origins allowed for CORS. Other browser pages could otherwise use the session
+// cookie of a signed in user to open a socket.
+func (h *handler) checkLiveOrigin(req *http.Request) bool {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, req.Host) {
+		return true
+	}
+
+	return slices.ContainsFunc(h.cfg.CORS.AllowedOrigins, func(allowed string) bool {
+		return allowed == "*" || strings.EqualFold(allowed, origin)
+	})
+}
+
+// LiveTimersHandler upgrades the request to a WebSocket for live timers. Messages are JSON objects
+// with a type field:
+//   - subscribe: receive the running timers (state) and from then on all changes and ticks
+//   - start: start a timer, with task (required) and project (optional)
+//   - stop: stop the running timer with tracker_id
+//
+// Starting and stopping a timer, through the socket or any other API, is broadcast as started or
+// stopped to all subscribed sockets of the user, a change of a running timer as state, and while
+// timers run every subscribed socket receives a tick with the elapsed seconds.
+// Invalid messages are answered with an error message, the socket stays open.
+//
+// Returns:
+//   - 101 Switching Protocols: The socket is open
+//   - 401 Unauthorized: No valid session
+//   - 403 Forbidden: The Origin is not allowed
+//   - 404 Not Found: The live socket is disabled in the configuration
+func (h *handler) LiveTimersHandler(w http.ResponseWriter, req *http.Request) {
+	h.log(req).Infof("LiveTimersHandler: Processing request from %s", req.RemoteAddr)
+
+	if !h.cfg.WebSocket.Enabled {
+		h.sendErrorResponse(w, req, http.StatusNotFound,
+			"Live timers disabled",
+			"The live timer socket is not enabled on this server",
+			"WEBSOCKET_DISABLED")
+		return
+	}
+
+	if !h.checkLiveOrigin(req) {
+		h.sendErrorResponse(w, req, http.StatusForbidden,
+			"Origin not allowed",
+			"This origin may not open a live timer socket",
+			"ORIGIN_NOT_ALLOWED")
+		return
+	}
+
+	user, _ := userFromContext(req.Context())
+
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		// The origin was checked above with the error response of the API.
+		CheckOrigin: func(*http.Request) bool { return true },
+	}
+	conn, err := upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		// The upgrader already answered the request.
+		h.log(req).Warnf("LiveTimersHandler: Upgrade failed - %v", err)
+		return
+	}
+
+	client := &liveClient{userID: user.ID, send: make(chan []byte, liveClientBacklog), acked: map[int]int{}}
+	h.live.register(client)
+	h.log(req).Infof("LiveTimersHandler: User ID %d connected from %s", user.ID, req.RemoteAddr)
+
+	// Shutdown does not wait for hijacked connections, so the socket is closed here and the
+	// client reconnects to a server that is up.
+	disconnected := make(chan struct{})
+	defer close(disconnected)
+	go func() {
+		select {
+		case <-serverClosing(req.Context()):
+			conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"),
+				time.Now().Add(liveWriteTimeout))
+			conn.Close()
+		case <-disconnected:
+		}
+	}()
+
+	go h.writeLiveMessages(conn, client)
+	h.readLiveMessages(req.Context(), conn, client, user)
+
+	h.live.unregister(client)
+	h.log(req).Infof("LiveTimersHandler: User ID %d disconnected from %s", user.ID, req.RemoteAddr)
+}
+
+func (h *handler) writeLiveMessages(conn *websocket.Conn, client *liveClient) {
+	ping := time.NewTicker(livePingInterval)
+	defer func() {
+		ping.Stop()
+		conn.Close()
+	}()
+
+	for {
+		select {
+		case payload, ok := <-client.send:
+			conn.SetWriteDeadline(time.Now().Add(liveWriteTimeout))
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+				return
+			}
+			if err := conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+				return
+			}
+
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(liveWriteTimeout)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readLiveMessages handles the requests of a socket. Each request is traced on its own, linked to
+// the span of the connection, since the connection lives for hours.
+func (h *handler) readLiveMessages(ctx context.Context, conn *websocket.Conn, client *liveClient, user *model.User) {
+	conn.SetReadLimit(liveMaxMessage)
+	conn.SetReadDeadline(time.Now().Add(livePongTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(livePongTimeout))
+	})
+
+	for {
+		var request model.LiveRequest
+		if err := conn.ReadJSON(&request); err != nil {
+			var syntaxErr *json.SyntaxError
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+				h.sendLiveError(client, "", "INVALID_JSON", "Messages must be JSON objects matching LiveRequest schema")
+				continue
+			}
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				h.logger.Warnf("readLiveMessages: Read failed for user ID %d - %v", user.ID, err)
+			}
+			return
+		}
+
+		// Messages count as liveness as well, not only pongs.
+		conn.SetReadDeadline(time.Now().Add(livePongTimeout))
+		requestCtx, span := tracer.Start(ctx, "live "+request.Type,
+			trace.WithNewRoot(), trace.WithLinks(trace.LinkFromContext(ctx)))
+		h.handleLiveRequest(requestCtx, client, user, request)
+		span.End()
+	}
+}
+
+func (h *handler) handleLiveRequest(ctx context.Context, client *liveClient, user *model.User, request model.LiveRequest) {
+	switch request.Type {
+	case model.LiveSubscribe:
+		running, err := h.service.RunningTimersService(ctx, user.ID)
+		if err != nil {
+			h.logger.Errorf("handleLiveRequest: Failed to load running timers for user ID %d - %v", user.ID, err)
+			h.sendLiveError(client, request.Ref, "FETCH_ERROR", "An error occurred while loading the running timers")
+			return
+		}
+
+		now := time.Now().UTC()
+		h.live.subscribe(client, running)
+		h.live.reply(client, model.LiveMessage{
+			Type:       model.LiveState,
+			Ref:        request.Ref,
+			Running:    liveTimers(running, now),
+			ServerTime: now,
+		})
+
+	case model.LiveStart:
+		create := model.CreateTrackerRequest{Task: request.Task, Project: request.Project, StartTime: time.Now()}
+		if validationErrors := validateStruct(&create); len(validationErrors) > 0 {
+			h.sendLiveValidationError(client, request.Ref, validationErrors)
+			return
+		}
+
+		tracker, err := h.service.StartTimerService(ctx, user, create)
+		if err != nil {
+			h.logger.Errorf("handleLiveRequest: Failed to start timer for user ID %d - %v", user.ID, err)
+			h.sendLiveError(client, request.Ref, "CREATE_ERROR", "An error occurred while starting the timer")
+			return
+		}
+
+		h.logger.Infof("handleLiveRequest: User ID %d started tracker ID %d", user.ID, tracker.ID)
+		h.ackLiveChange(ctx, client, user, model.LiveStarted, request.Ref, tracker)
+
+	case model.LiveStop:
+		if request.TrackerID <= 0 {
+			h.sendLiveError(client, request.Ref, "VALIDATION_ERROR", "tracker_id must be a positive integer")
+			return
+		}
+
+		tracker, err := h.service.StopTimerService(ctx, user, request.TrackerID)
+		if err != nil {
+			h.logger.Warnf("handleLiveRequest: Failed to stop tracker ID %d for user ID %d - %v", request.TrackerID, user.ID, err)
+			switch {
+			case errors.Is(err, errTimerNotRunning):
+				h.sendLiveError(client, request.Ref, "NOT_RUNNING", "The timer is already stopped")
+			case errors.Is(err, errVersionMismatch):
+				h.sendLiveError(client, request.Ref, "PRECONDITION_FAILED", "The timer was changed meanwhile, subscribe again to refresh")
+			case errors.Is(err, errInvalidPatch):
+				h.sendLiveError(client, request.Ref, "VALIDATION_ERROR", "The timer starts in the future and cannot be stopped yet")
+			case errors.Is(err, errTrackerNotAllowed), strings.Contains(err.Error(), "not found"):
+				h.sendLiveError(client, request.Ref, "NOT_FOUND", fmt.Sprintf("No timer of yours exists with ID %d", request.TrackerID))
+			default:
+				h.sendLiveError(client, request.Ref, "UPDATE_ERROR", "An error occurred while stopping the timer")
+			}
+			return
+		}
+
+		h.logger.Infof("handleLiveRequest: User ID %d stopped tracker ID %d", user.ID, tracker.ID)
+		h.ackLiveChange(ctx, client, user, model.LiveStopped, request.Ref, tracker)
+
+	default:
+		h.sendLiveError(client, request.Ref, "INVALID_MESSAGE", "type must be one of subscribe, start or stop")
+	}
+}
+
+// ackLiveChange answers the socket that started or stopped a timer right away, with the ref of its
+// request. The other sockets of the user learn about the change from RunLiveTimers.
+func (h *handler) ackLiveChange(ctx context.Context, client *liveClient, user *model.User, msgType, ref string, tracker *model.Tracker) {
+	running, err := h.service.RunningTimersService(ctx, user.ID)
+	if err != nil {
+		h.logger.Errorf("ackLiveChange: Failed to load running timers for user ID %d - %v", user.ID, err)
+		h.sendLiveError(client, ref, "FETCH_ERROR", "The change was saved but the running timers could not be loaded")
+		return
+	}
+
+	now := time.Now().UTC()
+	h.live.ack(client, tracker, model.LiveMessage{
+		Type:       msgType,
+		Ref:        ref,
+		Tracker:    tracker,
+		Running:    liveTimers(running, now),
+		ServerTime: now,
+	})
+}
+
+func (h *handler) sendLiveError(client *liveClient, ref, code, message string) {
+	h.live.reply(client, model.LiveMessage{
+		Type:       model.LiveError,
+		Ref:        ref,
+		ServerTime: time.Now().UTC(),
+		Error:      &model.OperationError{Code: code, Message: message},
+	})
+}
+
+// sendLiveValidationError reports the invalid fields of a message, like the errors array of a REST
+// validation error.
+func (h *handler) sendLiveValidationError(client *liveClient, ref string, errs []model.FieldError) {
+	h.live.reply(client, model.LiveMessage{
+		Type:       model.LiveError,
+		Ref:        ref,
+		ServerTime: time.Now().UTC(),
+		Error: &model.OperationError{
+			Code:    "VALIDATION_ERROR",
+			Message: fieldErrorMessages(errs),
+			Errors:  errs,
+		},
+	})
+}
@@ -0,0 +1,57 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+package api
+
+import (
+	"context"
+	"time"
+	"timetracker/api/model"
+	"timetracker/errorutil"
+)
+
+var (
+	errTimerNotRunning   = errorutil.New("timer is not running")
+	errTrackerNotAllowed = errorutil.New("tracker belongs to another user")
+)
+
+// StartTimerService starts a new running tracker for user.
+func (s *service) StartTimerService(ctx context.Context, user *model.User, req model.CreateTrackerRequest) (*model.Tracker, error) {
+	ctx, span := tracer.Start(ctx, "service.StartTimerService")
+	defer span.End()
+
+	req.UserID = &user.ID
+	req.StartTime = time.Now().UTC().Truncate(time.Second)
+	req.EndTime = nil
+	return s.repo.CreateTracker(ctx, req)
+}
+
+// StopTimerService stops a running tracker of user. The stop is bound to the version that was
+// checked, so two devices stopping the same timer cannot overwrite each other's end time.
+func (s *service) StopTimerService(ctx context.Context, user *model.User, trackerID int) (*model.Tracker, error) {
+	ctx, span := tracer.Start(ctx, "service.StopTimerService")
+	defer span.End()
+
+	current, err := s.repo.GetTrackerByID(ctx, trackerID)
+	if err != nil {
+		return nil, err
+	}
+	if current.UserID == nil || *current.UserID != user.ID {
+		return nil, errTrackerNotAllowed
+	}
+	if current.EndTime != nil {
+		return nil, errTimerNotRunning
+	}
+
+	patch := model.TrackerPatch{EndTime: model.NullableValue(time.Now().UTC().Truncate(time.Second))}
+	return patchTracker(ctx, s.repo, trackerID, patch, []int{current.Version})
+}
+
+func (s *service) RunningTimersService(ctx context.Context, userID int) ([]model.Tracker, error) {
+	ctx, span := tracer.Start(ctx, "service.RunningTimersService")
+	defer span.End()
+
+	return s.repo.FindTrackers(ctx, model.TrackerFilter{UserID: &userID, Running: true})
+}
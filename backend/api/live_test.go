@@ -0,0 +1,78 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+	"timetracker/api/model"
+)
+
+// TestLiveHubChanges feeds the hub tracker changes as they come from the event listener and checks
+// the messages a subscribed socket and the socket that made the change receive.
+func TestLiveHubChanges(t *testing.T) {
+	hub := newLiveHub()
+	watcher := &liveClient{userID: 1, send: make(chan []byte, liveClientBacklog), acked: map[int]int{}}
+	origin := &liveClient{userID: 1, send: make(chan []byte, liveClientBacklog), acked: map[int]int{}}
+	hub.register(watcher)
+	hub.register(origin)
+	hub.subscribe(watcher, nil)
+	hub.subscribe(origin, nil)
+
+	received := func(client *liveClient) []string {
+		t.Helper()
+		var types []string
+		for {
+			select {
+			case payload := <-client.send:
+				var msg model.LiveMessage
+				if err := json.Unmarshal(payload, &msg); err != nil {
+					t.Fatalf("Invalid message %s: %v", payload, err)
+				}
+				types = append(types, msg.Type)
+			default:
+				return types
+			}
+		}
+	}
+
+	timer := model.Tracker{ID: 7, Task: "Review", StartTime: time.Now().Add(-time.Minute), Version: 1}
+	hub.ack(origin, &timer, model.LiveMessage{Type: model.LiveStarted, Ref: "a1"})
+	hub.change(1, []model.Tracker{timer}, timer, false)
+	if got := received(watcher); len(got) != 1 || got[0] != model.LiveStarted {
+		t.Errorf("Watcher received %v for a started timer, want started", got)
+	}
+	if got := received(origin); len(got) != 1 || got[0] != model.LiveStarted {
+		t.Errorf("Origin received %v, want only its answer", got)
+	}
+
+	renamed := timer
+	renamed.Task, renamed.Version = "Code review", 2
+	hub.change(1, []model.Tracker{renamed}, renamed, false)
+	for _, client := range []*liveClient{watcher, origin} {
+		if got := received(client); len(got) != 1 || got[0] != model.LiveState {
+			t.Errorf("Socket received %v for an edited timer, want state", got)
+		}
+	}
+
+	// A stop made elsewhere, e.g. with PATCH on another instance, reaches both sockets.
+	stopped := renamed
+	stopped.Version = 3
+	hub.change(1, nil, stopped, false)
+	for _, client := range []*liveClient{watcher, origin} {
+		if got := received(client); len(got) != 1 || got[0] != model.LiveStopped {
+			t.Errorf("Socket received %v for a stopped timer, want stopped", got)
+		}
+	}
+
+	other := model.Tracker{ID: 8, Task: "Done", StartTime: time.Now().Add(-time.Hour), Version: 1}
+	hub.change(1, nil, other, false)
+	if got := received(watcher); len(got) != 0 {
+		t.Errorf("Watcher received %v for a change of a stopped tracker", got)
+	}
+}
@@ -0,0 +1,253 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+	"timetracker/logger"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const metricsNamespace = "timetracker"
+
+// metrics holds the Prometheus collectors of one API instance. They live on their own registry
+// instead of the global one, so instances created side by side, as in tests, do not clash.
+type metrics struct {
+	registry        *prometheus.Registry
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	inFlight        prometheus.Gauge
+	queryDuration   *prometheus.HistogramVec
+	queryErrors     *prometheus.CounterVec
+}
+
+func newMetrics() *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests answered, by method, route pattern and status.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time until HTTP requests were answered, by method, route pattern and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_requests_in_flight",
+			Help:      "HTTP requests being served, including open event streams and live sockets.",
+		}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Time SQL statements took until their first row, by repository method.",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+		}, []string{"query"}),
+		queryErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "db_query_errors_total",
+			Help:      "SQL statements that failed, by repository method.",
+		}, []string{"query"}),
+	}
+
+	m.registry.MustRegister(m.requests, m.requestDuration, m.inFlight, m.queryDuration, m.queryErrors,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	return m
+}
+
+// registerDatabase adds the connection pool statistics of db and the business gauges read
+// through repo.
+func (m *metrics) registerDatabase(db *sql.DB, repo *repository) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, metricsNamespace), businessCollector{repo: repo})
+}
+
+func (m *metrics) observeQuery(method string, start time.Time, err error) {
+	m.queryDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.queryErrors.WithLabelValues(method).Inc()
+	}
+}
+
+// timedDB records the duration of every statement, labeled with the repository method running it,
+// and traces it as a child of the span of ctx. Queries are timed until their first row is
+// available, reading the rest is up to the caller.
+type timedDB struct {
+	dbtx
+	metrics *metrics
+}
+
+func (db timedDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	method := repositoryMethod()
+	ctx, span := startQuerySpan(ctx, method, query)
+	start := time.Now()
+	result, err := db.dbtx.ExecContext(ctx, query, args...)
+	db.metrics.observeQuery(method, start, err)
+	endSpan(span, err)
+	return result, err
+}
+
+func (db timedDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	method := repositoryMethod()
+	ctx, span := startQuerySpan(ctx, method, query)
+	start := time.Now()
+	rows, err := db.dbtx.QueryContext(ctx, query, args...)
+	db.metrics.observeQuery(method, start, err)
+	endSpan(span, err)
+	return rows, err
+}
+
+func (db timedDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	method := repositoryMethod()
+	ctx, span := startQuerySpan(ctx, method, query)
+	start := time.Now()
+	row := db.dbtx.QueryRowContext(ctx, query, args...)
+	// sql.ErrNoRows only shows up on Scan and is no failure of the statement.
+	db.metrics.observeQuery(method, start, row.Err())
+	endSpan(span, row.Err())
+	return row
+}
+
+// repositoryMethod names the function that called the timedDB method, e.g. GetWebhook for
+// timetracker/api.(*repository).GetWebhook and RecordWebhookAttempt for its InTx callback.
+func repositoryMethod() string {
+	pc, _, _, ok := runtime.Caller(2)
+	if !ok {
+		return "unknown"
+	}
+	name := runtime.FuncForPC(pc).Name()
+	name = name[strings.LastIndex(name, "/")+1:]
+	if _, rest, found := strings.Cut(name, "."); found {
+		name = rest
+	}
+	name = strings.TrimPrefix(name, "(*repository).")
+	if method, _, found := strings.Cut(name, "."); found {
+		name = method
+	}
+	return name
+}
+
+var (
+	runningTimersDesc = prometheus.NewDesc(metricsNamespace+"_running_timers",
+		"Trackers that have no end time yet.", nil, nil)
+	activeSessionsDesc = prometheus.NewDesc(metricsNamespace+"_active_sessions",
+		"Sessions that are neither expired nor revoked.", nil, nil)
+	webhookDeliveriesDesc = prometheus.NewDesc(metricsNamespace+"_webhook_deliveries",
+		"Webhook deliveries waiting for their next attempt (pending) or given up on (failed).",
+		[]string{"status"}, nil)
+)
+
+// businessCollector reads the business gauges from the database on every scrape, so they are
+// right no matter which instance changed the data.
+type businessCollector struct {
+	repo *repository
+}
+
+func (c businessCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- runningTimersDesc
+	ch <- activeSessionsDesc
+	ch <- webhookDeliveriesDesc
+}
+
+func (c businessCollector) Collect(ch chan<- prometheus.Metric) {
+	stats, err := c.repo.GetBusinessStats(context.Background())
+	if err != nil {
+		for _, desc := range []*prometheus.Desc{runningTimersDesc, activeSessionsDesc, webhookDeliveriesDesc} {
+			ch <- prometheus.NewInvalidMetric(desc, err)
+		}
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(runningTimersDesc, prometheus.GaugeValue, float64(stats.RunningTimers))
+	ch <- prometheus.MustNewConstMetric(activeSessionsDesc, prometheus.GaugeValue, float64(stats.ActiveSessions))
+	ch <- prometheus.MustNewConstMetric(webhookDeliveriesDesc, prometheus.GaugeValue, float64(stats.PendingWebhookDeliveries), "pending")
+	ch <- prometheus.MustNewConstMetric(webhookDeliveriesDesc, prometheus.GaugeValue, float64(stats.FailedWebhookDeliveries), "failed")
+}
+
+// instrument counts the requests and their duration per route pattern and status. Requests no
+// route matches are counted under the route "unmatched", so scans of random paths do not create
+// a series each.
+func (r *router) instrument(next http.Handler) http.Handler {
+	m := r.handler.service.repo.metrics
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		route := "unmatched"
+		if _, pattern := r.mux.Handler(req); pattern != "" {
+			if _, path, found := strings.Cut(pattern, " "); found {
+				pattern = path
+			}
+			route = pattern
+		}
+		method := req.Method
+		switch method {
+		case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+			http.MethodDelete, http.MethodOptions:
+		default:
+			method = "OTHER"
+		}
+
+		m.inFlight.Inc()
+		rec := &responseRecorder{ResponseWriter: w}
+
+		defer func() {
+			// Only aborted responses get here, recoverPanic answers the other panics.
+			aborted := recover()
+			m.inFlight.Dec()
+
+			status := "aborted"
+			if rec.status != 0 {
+				status = strconv.Itoa(rec.status)
+			} else if aborted == nil {
+				status = strconv.Itoa(http.StatusOK)
+			}
+			m.requests.WithLabelValues(method, route, status).Inc()
+			m.requestDuration.WithLabelValues(method, route, status).Observe(time.Since(start).Seconds())
+
+			if aborted != nil {
+				panic(aborted)
+			}
+		}()
+
+		next.ServeHTTP(rec, req)
+	})
+}
+
+// MetricsHandler serves the metrics in the Prometheus text format. A gauge that cannot be read
+// is left out and logged, the others are still served.
+func (h *handler) MetricsHandler(w http.ResponseWriter, req *http.Request) {
+	h.metricsHandler.ServeHTTP(w, req)
+}
+
+func newMetricsHandler(m *metrics, l *logger.Logger) http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{
+		ErrorLog:      metricsErrorLog{l},
+		ErrorHandling: promhttp.ContinueOnError,
+		Registry:      m.registry,
+	})
+}
+
+type metricsErrorLog struct {
+	logger *logger.Logger
+}
+
+func (l metricsErrorLog) Println(v ...any) {
+	l.logger.Errorf("MetricsHandler: %s", strings.TrimSuffix(fmt.Sprintln(v...), "\n"))
+}
@@ -0,0 +1,39 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+package api
+
+import (
+	"context"
+	"timetracker/errorutil"
+)
+
+// businessStats are the counts exported as gauges on /metrics.
+type businessStats struct {
+	RunningTimers            int
+	ActiveSessions           int
+	PendingWebhookDeliveries int
+	FailedWebhookDeliveries  int
+}
+
+func (r *repository) GetBusinessStats(ctx context.Context) (businessStats, error) {
+	ctx, span := startChildSpan(ctx, "repository.GetBusinessStats")
+	defer span.End()
+
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM tracker WHERE end_time IS NULL),
+			(SELECT COUNT(*) FROM sessions WHERE revoked_at IS NULL AND expires_at > CURRENT_TIMESTAMP),
+			(SELECT COUNT(*) FROM webhook_deliveries WHERE status = 'pending'),
+			(SELECT COUNT(*) FROM webhook_deliveries WHERE status = 'failed')`
+
+	var stats businessStats
+	err := r.db.QueryRowContext(ctx, query).Scan(&stats.RunningTimers, &stats.ActiveSessions,
+		&stats.PendingWebhookDeliveries, &stats.FailedWebhookDeliveries)
+	if err != nil {
+		return stats, errorutil.Wrap(err, "Failed to count business stats")
+	}
+	return stats, nil
+}
@@ -0,0 +1,98 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"timetracker/internal/config"
+	"timetracker/logger"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+	log := logger.NewLogger("metrics", filepath.Join(t.TempDir(), "api.log"))
+	cfg := &config.Config{Metrics: config.MetricsConfig{Enabled: true}}
+	repo := Repository(nil, log)
+	routes := Router(log, Handler(Service(repo, cfg), log, cfg), cfg).SetRoutes()
+
+	for _, path := range []string{"/v1/trackers/abc", "/v1/trackers/xyz", "/wp-login.php"} {
+		routes.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	requests := repo.metrics.requests
+	if got := testutil.ToFloat64(requests.WithLabelValues("GET", "/v1/trackers/{id}", "400")); got != 2 {
+		t.Errorf("requests of /v1/trackers/{id} = %v, want 2 under the route pattern", got)
+	}
+	if got := testutil.ToFloat64(requests.WithLabelValues("GET", "unmatched", "404")); got != 1 {
+		t.Errorf("unmatched requests = %v, want 1", got)
+	}
+
+	w := httptest.NewRecorder()
+	routes.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(w.Body)
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain") {
+		t.Fatalf("GET /metrics answered %d with %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	for _, want := range []string{
+		`timetracker_http_requests_total{method="GET",route="/v1/trackers/{id}",status="400"} 2`,
+		`timetracker_http_request_duration_seconds_bucket{method="GET",route="unmatched",status="404",le="+Inf"} 1`,
+		"go_goroutines ",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics lack %q", want)
+		}
+	}
+
+	cfg.Metrics.Enabled = false
+	routes = Router(log, Handler(Service(Repository(nil, log), cfg), log, cfg), cfg).SetRoutes()
+	w = httptest.NewRecorder()
+	routes.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("GET /metrics answered %d while disabled, want 404", w.Code)
+	}
+}
+
+// failingDB fails every statement.
+type failingDB struct{}
+
+func (failingDB) ExecContext(context.Context, string, ...any) (sql.Result, error) {
+	return nil, errors.New("connection refused")
+}
+
+func (failingDB) QueryContext(context.Context, string, ...any) (*sql.Rows, error) {
+	return nil, errors.New("connection refused")
+}
+
+func (failingDB) QueryRowContext(context.Context, string, ...any) *sql.Row {
+	panic("not used")
+}
+
+func TestQueryMetricsLabelRepositoryMethods(t *testing.T) {
+	m := newMetrics()
+	repo := &repository{db: timedDB{dbtx: failingDB{}, metrics: m}, metrics: m}
+
+	repo.DeleteWebhook(context.Background(), 1)
+	repo.Savepoint(context.Background(), "batch_item", func() error { return nil })
+
+	for method, want := range map[string]float64{"DeleteWebhook": 1, "Savepoint": 1} {
+		if got := testutil.ToFloat64(m.queryErrors.WithLabelValues(method)); got != want {
+			t.Errorf("errors of %s = %v, want %v", method, got, want)
+		}
+	}
+	if got := testutil.CollectAndCount(m.queryDuration); got != 2 {
+		t.Errorf("query duration series = %d, want one per repository method", got)
+	}
+}
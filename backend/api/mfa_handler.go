@@ -0,0 +1,254 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"timetracker/api/model"
+)
+
+// sendMFAErrorResponse maps the two-factor errors of the service layer to responses.
+func (h *handler) sendMFAErrorResponse(w http.ResponseWriter, req *http.Request, err error, defaultMsg string) {
+	switch {
+	case errors.Is(err, errInvalidMFACode):
+		h.sendErrorResponse(w, req, http.StatusUnauthorized,
+			"Invalid code",
+			"The two-factor code is invalid or was already used",
+			"INVALID_MFA_CODE")
+	case errors.Is(err, errMFALocked):
+		h.sendErrorResponse(w, req, http.StatusUnauthorized,
+			"Too many attempts",
+			"The session was revoked after too many invalid codes, please sign in again",
+			"MFA_LOCKED")
+	case errors.Is(err, errTOTPAlreadyEnabled):
+		h.sendErrorResponse(w, req, http.StatusConflict,
+			"Two-factor authentication already enabled",
+			"Ask an admin to reset two-factor authentication before enrolling again",
+			"MFA_ALREADY_ENABLED")
+	case errors.Is(err, errTOTPNotEnrolled):
+		h.sendErrorResponse(w, req, http.StatusConflict,
+			"Two-factor authentication not enrolled",
+			"Start the enrollment with POST /auth/2fa/enroll first",
+			"MFA_NOT_ENROLLED")
+	case errors.Is(err, errMFANotPending):
+		h.sendErrorResponse(w, req, http.StatusConflict,
+			"Two-factor verification not required",
+			"This session is already fully authenticated",
+			"MFA_NOT_PENDING")
+	default:
+		h.sendErrorResponse(w, req, http.StatusInternalServerError,
+			defaultMsg,
+			"An error occurred while updating two-factor authentication",
+			"MFA_ERROR")
+	}
+}
+
+func (h *handler) decodeTOTPCodeRequest(w http.ResponseWriter, req *http.Request, handlerName string) (*model.TOTPCodeRequest, bool) {
+	var request model.TOTPCodeRequest
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		h.log(req).Warnf("%s: Failed to decode JSON - %v", handlerName, err)
+		h.sendErrorResponse(w, req, http.StatusBadRequest,
+			"Invalid JSON payload",
+			"Request body must be valid JSON matching TOTPCodeRequest schema",
+			"INVALID_JSON")
+		return nil, false
+	}
+
+	if validationErrors := validateStruct(&request); len(validationErrors) > 0 {
+		h.sendValidationErrorResponse(w, req, validationErrors)
+		return nil, false
+	}
+
+	return &request, true
+}
+
+// EnrollTOTPHandler starts the two-factor enrollment of the signed in user.
+// It generates a new TOTP secret and returns it together with the otpauth:// URI that
+// authenticator apps import. The secret is inactive until it is confirmed.
+//
+// Returns:
+//   - 200 OK: Secret and otpauth URI
+//   - 401 Unauthorized: No valid session
+//   - 409 Conflict: Two-factor authentication is already enabled
+//   - 500 Internal Server Error: Database or server errors
+func (h *handler) EnrollTOTPHandler(w http.ResponseWriter, req *http.Request) {
+	h.log(req).Infof("EnrollTOTPHandler: Processing request from %s", req.RemoteAddr)
+
+	user, _ := userFromContext(req.Context())
+
+	enrollment, err := h.service.EnrollTOTPService(req.Context(), user)
+	if err != nil {
+		h.log(req).Errorf("EnrollTOTPHandler: Service error for user ID %d - %v", user.ID, err)
+		h.sendMFAErrorResponse(w, req, err, "Failed to enroll two-factor authentication")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(enrollment)
+}
+
+// ConfirmTOTPHandler enables two-factor authentication with the first code from the authenticator app.
+// It expects a JSON payload containing:
+//   - code: string (required, the current 6 digit code)
+//
+// Returns:
+//   - 200 OK: The recovery codes, shown only this once
+//   - 400 Bad Request: Invalid JSON payload or missing code
+//   - 401 Unauthorized: No valid session or invalid code
+//   - 409 Conflict: Not enrolled or already enabled
+//   - 500 Internal Server Error: Database or server errors
+func (h *handler) ConfirmTOTPHandler(w http.ResponseWriter, req *http.Request) {
+	h.log(req).Infof("ConfirmTOTPHandler: Processing request from %s", req.RemoteAddr)
+
+	user, _ := userFromContext(req.Context())
+
+	request, ok := h.decodeTOTPCodeRequest(w, req, "ConfirmTOTPHandler")
+	if !ok {
+		return
+	}
+
+	codes, err := h.service.ConfirmTOTPService(req.Context(), user, request.Code)
+	if err != nil {
+		h.log(req).Errorf("ConfirmTOTPHandler: Service error for user ID %d - %v", user.ID, err)
+		h.sendMFAErrorResponse(w, req, err, "Failed to confirm two-factor authentication")
+		return
+	}
+
+	h.log(req).Infof("ConfirmTOTPHandler: Two-factor authentication enabled for user ID: %d", user.ID)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(model.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// VerifyMFAHandler completes a login that requires a second factor.
+// It accepts sessions that are still pending and expects a JSON payload containing either:
+//   - code: string (the current 6 digit code), or
+//   - recovery_code: string (an unused recovery code, which is burned)
+//
+// Returns:
+//   - 200 OK: The now fully authenticated user
+//   - 400 Bad Request: Invalid JSON payload or neither field provided
+//   - 401 Unauthorized: No session, invalid code, or too many attempts
+//   - 409 Conflict: The session does not wait for a second factor
+//   - 500 Internal Server Error: Database or server errors
+func (h *handler) VerifyMFAHandler(w http.ResponseWriter, req *http.Request) {
+	h.log(req).Infof("VerifyMFAHandler: Processing request from %s", req.RemoteAddr)
+
+	user, _ := userFromContext(req.Context())
+	session, _ := sessionFromContext(req.Context())
+
+	var request model.MFAVerifyRequest
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		h.log(req).Warnf("VerifyMFAHandler: Failed to decode JSON - %v", err)
+		h.sendErrorResponse(w, req, http.StatusBadRequest,
+			"Invalid JSON payload",
+			"Request body must be valid JSON matching MFAVerifyRequest schema",
+			"INVALID_JSON")
+		return
+	}
+
+	if strings.TrimSpace(request.Code) == "" && strings.TrimSpace(request.RecoveryCode) == "" {
+		h.sendValidationErrorResponse(w, req, []model.FieldError{
+			fieldError("code", model.FieldErrorRequired, "either code or recovery_code is required"),
+		})
+		return
+	}
+
+	if err := h.service.VerifyMFAService(req.Context(), session, user, request); err != nil {
+		h.log(req).Warnf("VerifyMFAHandler: Verification failed for user ID %d - %v", user.ID, err)
+		h.sendMFAErrorResponse(w, req, err, "Failed to verify two-factor code")
+		return
+	}
+
+	h.log(req).Infof("VerifyMFAHandler: Session verified for user ID: %d", user.ID)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(user)
+}
+
+// RegenerateRecoveryCodesHandler replaces all recovery codes of the signed in user.
+// It expects a JSON payload containing:
+//   - code: string (required, the current 6 digit code)
+//
+// Returns:
+//   - 200 OK: The new recovery codes, the old ones stop working
+//   - 400 Bad Request: Invalid JSON payload or missing code
+//   - 401 Unauthorized: No valid session or invalid code
+//   - 409 Conflict: Two-factor authentication is not enabled
+//   - 500 Internal Server Error: Database or server errors
+func (h *handler) RegenerateRecoveryCodesHandler(w http.ResponseWriter, req *http.Request) {
+	h.log(req).Infof("RegenerateRecoveryCodesHandler: Processing request from %s", req.RemoteAddr)
+
+	user, _ := userFromContext(req.Context())
+
+	request, ok := h.decodeTOTPCodeRequest(w, req, "RegenerateRecoveryCodesHandler")
+	if !ok {
+		return
+	}
+
+	codes, err := h.service.RegenerateRecoveryCodesService(req.Context(), user, request.Code)
+	if err != nil {
+		h.log(req).Errorf("RegenerateRecoveryCodesHandler: Service error for user ID %d - %v", user.ID, err)
+		h.sendMFAErrorResponse(w, req, err, "Failed to regenerate recovery codes")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(model.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// ResetUserTOTPHandler lets an admin remove the second factor of a user by user ID,
+// for users who lost both their authenticator and their recovery codes.
+//
+// Returns:
+//   - 204 No Content: Two-factor authentication was reset
+//   - 400 Bad Request: Invalid ID parameter
+//   - 401 Unauthorized: No valid session
+//   - 403 Forbidden: The user is not an admin
+//   - 404 Not Found: User with specified ID does not exist
+//   - 500 Internal Server Error: Database or server errors
+func (h *handler) ResetUserTOTPHandler(w http.ResponseWriter, req *http.Request) {
+	h.log(req).Infof("ResetUserTOTPHandler: Processing request from %s", req.RemoteAddr)
+
+	admin, _ := userFromContext(req.Context())
+
+	id, err := h.extractIDFromPath(req)
+	if err != nil {
+		h.log(req).Warnf("ResetUserTOTPHandler: Invalid ID parameter - %v", err)
+		h.sendErrorResponse(w, req, http.StatusBadRequest,
+			"Invalid ID parameter",
+			err.Error(),
+			"INVALID_ID")
+		return
+	}
+
+	if err := h.service.ResetTOTPService(req.Context(), id); err != nil {
+		h.log(req).Errorf("ResetUserTOTPHandler: Service error for user ID %d - %v", id, err)
+		if strings.Contains(err.Error(), "not found") {
+			h.sendErrorResponse(w, req, http.StatusNotFound,
+				"User not found",
+				fmt.Sprintf("No user exists with ID %d", id),
+				"NOT_FOUND")
+			return
+		}
+
+		h.sendMFAErrorResponse(w, req, err, "Failed to reset two-factor authentication")
+		return
+	}
+
+	h.log(req).Warnf("ResetUserTOTPHandler: Admin ID %d reset two-factor authentication of user ID: %d", admin.ID, id)
+	w.WriteHeader(http.StatusNoContent)
+}
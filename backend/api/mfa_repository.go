@@ -0,0 +1,239 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+package api
+
+import (
+	"context"
+	"database/sql"
+	"timetracker/errorutil"
+)
+
+// GetTOTPSecret returns the stored TOTP secret of a user and whether it has been confirmed.
+func (r *repository) GetTOTPSecret(ctx context.Context, userID int) (string, bool, error) {
+	ctx, span := startChildSpan(ctx, "repository.GetTOTPSecret")
+	defer span.End()
+
+	query := `SELECT COALESCE(totp_secret, ''), totp_enabled FROM users WHERE id = $1`
+
+	var secret string
+	var enabled bool
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&secret, &enabled)
+
+	if err == sql.ErrNoRows {
+		return "", false, errorutil.New("user not found")
+	}
+	if err != nil {
+		return "", false, errorutil.Wrap(err, "Failed to get TOTP secret")
+	}
+
+	return secret, enabled, nil
+}
+
+// SetPendingTOTPSecret stores a new, not yet confirmed secret. It never replaces the
+// secret of a user whose two-factor authentication is already enabled.
+func (r *repository) SetPendingTOTPSecret(ctx context.Context, userID int, secret string) error {
+	ctx, span := startChildSpan(ctx, "repository.SetPendingTOTPSecret")
+	defer span.End()
+
+	query := `
+		UPDATE users
+		SET totp_secret = $2, totp_last_step = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND NOT totp_enabled`
+
+	result, err := r.db.ExecContext(ctx, query, userID, secret)
+	if err != nil {
+		return errorutil.Wrap(err, "Failed to store TOTP secret")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errorutil.Wrap(err, "Failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errorutil.New("TOTP already enabled")
+	}
+
+	return nil
+}
+
+// UseTOTPStep records step as the last accepted time step of the user. It returns false
+// when a code of this or a later step was already accepted, which rejects replayed codes.
+func (r *repository) UseTOTPStep(ctx context.Context, userID int, step int64) (bool, error) {
+	ctx, span := startChildSpan(ctx, "repository.UseTOTPStep")
+	defer span.End()
+
+	query := `
+		UPDATE users
+		SET totp_last_step = $2
+		WHERE id = $1 AND (totp_last_step IS NULL OR totp_last_step < $2)`
+
+	result, err := r.db.ExecContext(ctx, query, userID, step)
+	if err != nil {
+		return false, errorutil.Wrap(err, "Failed to record TOTP step")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, errorutil.Wrap(err, "Failed to get rows affected")
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// EnableTOTP confirms the pending secret and replaces the recovery codes in one transaction.
+func (r *repository) EnableTOTP(ctx context.Context, userID int, recoveryCodeHashes []string) error {
+	ctx, span := startChildSpan(ctx, "repository.EnableTOTP")
+	defer span.End()
+
+	err := r.InTx(ctx, func(tx *repository) error {
+		query := `UPDATE users SET totp_enabled = TRUE, updated_at = CURRENT_TIMESTAMP WHERE id = $1`
+		if _, err := tx.db.ExecContext(ctx, query, userID); err != nil {
+			return errorutil.Wrap(err, "Failed to enable TOTP")
+		}
+
+		return tx.replaceRecoveryCodes(ctx, userID, recoveryCodeHashes)
+	})
+	if err != nil {
+		return err
+	}
+
+	r.logger.Infof("Enabled two-factor authentication for user ID: %d", userID)
+	return nil
+}
+
+func (r *repository) ReplaceRecoveryCodes(ctx context.Context, userID int, recoveryCodeHashes []string) error {
+	ctx, span := startChildSpan(ctx, "repository.ReplaceRecoveryCodes")
+	defer span.End()
+
+	err := r.InTx(ctx, func(tx *repository) error {
+		return tx.replaceRecoveryCodes(ctx, userID, recoveryCodeHashes)
+	})
+	if err != nil {
+		return err
+	}
+
+	r.logger.Infof("Replaced recovery codes for user ID: %d", userID)
+	return nil
+}
+
+// replaceRecoveryCodes must be called on a repository passed to an InTx callback.
+func (r *repository) replaceRecoveryCodes(ctx context.Context, userID int, recoveryCodeHashes []string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return errorutil.Wrap(err, "Failed to delete recovery codes")
+	}
+
+	for _, hash := range recoveryCodeHashes {
+		if _, err := r.db.ExecContext(ctx, `INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, hash); err != nil {
+			return errorutil.Wrap(err, "Failed to store recovery code")
+		}
+	}
+
+	return nil
+}
+
+// ConsumeRecoveryCode marks an unused recovery code as used. It returns false when the
+// code does not exist or was used before.
+func (r *repository) ConsumeRecoveryCode(ctx context.Context, userID int, codeHash string) (bool, error) {
+	ctx, span := startChildSpan(ctx, "repository.ConsumeRecoveryCode")
+	defer span.End()
+
+	query := `
+		UPDATE recovery_codes
+		SET used_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, userID, codeHash)
+	if err != nil {
+		return false, errorutil.Wrap(err, "Failed to consume recovery code")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, errorutil.Wrap(err, "Failed to get rows affected")
+	}
+
+	if rowsAffected == 1 {
+		r.logger.Infof("Recovery code used by user ID: %d", userID)
+	}
+	return rowsAffected == 1, nil
+}
+
+func (r *repository) CompleteSessionMFA(ctx context.Context, sessionID int) error {
+	ctx, span := startChildSpan(ctx, "repository.CompleteSessionMFA")
+	defer span.End()
+
+	query := `UPDATE sessions SET mfa_pending = FALSE, mfa_failed_attempts = 0 WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, sessionID); err != nil {
+		return errorutil.Wrap(err, "Failed to complete session MFA")
+	}
+
+	return nil
+}
+
+// RecordFailedMFAAttempt counts a wrong second factor for a pending session and revokes the
+// session once maxAttempts is reached. It returns whether the session was revoked.
+func (r *repository) RecordFailedMFAAttempt(ctx context.Context, sessionID, maxAttempts int) (bool, error) {
+	ctx, span := startChildSpan(ctx, "repository.RecordFailedMFAAttempt")
+	defer span.End()
+
+	query := `
+		UPDATE sessions
+		SET mfa_failed_attempts = mfa_failed_attempts + 1,
+			revoked_at = CASE WHEN mfa_failed_attempts + 1 >= $2 THEN CURRENT_TIMESTAMP ELSE revoked_at END
+		WHERE id = $1
+		RETURNING revoked_at IS NOT NULL`
+
+	var revoked bool
+	if err := r.db.QueryRowContext(ctx, query, sessionID, maxAttempts).Scan(&revoked); err != nil {
+		return false, errorutil.Wrap(err, "Failed to record MFA attempt")
+	}
+
+	if revoked {
+		r.logger.Warnf("Revoked session %d after %d failed MFA attempts", sessionID, maxAttempts)
+	}
+	return revoked, nil
+}
+
+// ResetTOTP removes the second factor and all recovery codes of a user, so they can sign in
+// with their identity provider alone and enroll again.
+func (r *repository) ResetTOTP(ctx context.Context, userID int) error {
+	ctx, span := startChildSpan(ctx, "repository.ResetTOTP")
+	defer span.End()
+
+	err := r.InTx(ctx, func(tx *repository) error {
+		query := `
+			UPDATE users
+			SET totp_secret = NULL, totp_enabled = FALSE, totp_last_step = NULL, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1`
+
+		result, err := tx.db.ExecContext(ctx, query, userID)
+		if err != nil {
+			return errorutil.Wrap(err, "Failed to reset TOTP")
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return errorutil.Wrap(err, "Failed to get rows affected")
+		}
+
+		if rowsAffected == 0 {
+			return errorutil.New("user not found")
+		}
+
+		if _, err := tx.db.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+			return errorutil.Wrap(err, "Failed to delete recovery codes")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	r.logger.Infof("Reset two-factor authentication for user ID: %d", userID)
+	return nil
+}
@@ -0,0 +1,208 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"strings"
+	"time"
+	"timetracker/api/model"
+	"timetracker/errorutil"
+	"timetracker/internal/totp"
+)
+
+const (
+	recoveryCodeCount = 10
+	// totpSkew accepts codes from the previous and next 30 second step to absorb clock drift.
+	totpSkew = 1
+	// maxMFAAttempts wrong second factors revoke a pending session, which stops brute forcing the 6 digits.
+	maxMFAAttempts = 5
+)
+
+var (
+	errTOTPAlreadyEnabled = errorutil.New("two-factor authentication is already enabled")
+	errTOTPNotEnrolled    = errorutil.New("two-factor authentication is not enrolled")
+	errInvalidMFACode     = errorutil.New("invalid two-factor code")
+	errMFANotPending      = errorutil.New("session does not require two-factor verification")
+	errMFALocked          = errorutil.New("too many failed two-factor attempts")
+)
+
+// EnrollTOTPService generates a new secret for the user. It only becomes active once a
+// code generated from it is confirmed with ConfirmTOTPService.
+func (s *service) EnrollTOTPService(ctx context.Context, user *model.User) (*model.TOTPEnrollment, error) {
+	ctx, span := tracer.Start(ctx, "service.EnrollTOTPService")
+	defer span.End()
+
+	if user.TOTPEnabled {
+		return nil, errTOTPAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetPendingTOTPSecret(ctx, user.ID, secret); err != nil {
+		if strings.Contains(err.Error(), "already enabled") {
+			return nil, errTOTPAlreadyEnabled
+		}
+		return nil, err
+	}
+
+	return &model.TOTPEnrollment{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(s.cfg.Auth.TOTPIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTPService enables two-factor authentication when code matches the pending secret
+// and returns the recovery codes. They are only stored hashed and cannot be shown again.
+func (s *service) ConfirmTOTPService(ctx context.Context, user *model.User, code string) ([]string, error) {
+	ctx, span := tracer.Start(ctx, "service.ConfirmTOTPService")
+	defer span.End()
+
+	secret, enabled, err := s.repo.GetTOTPSecret(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, errTOTPAlreadyEnabled
+	}
+	if secret == "" {
+		return nil, errTOTPNotEnrolled
+	}
+
+	if err := s.checkTOTP(ctx, user.ID, secret, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes := newRecoveryCodes()
+
+	if err := s.repo.EnableTOTP(ctx, user.ID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// VerifyMFAService completes the login of a session that is waiting for its second factor,
+// using either a TOTP code or one of the recovery codes.
+func (s *service) VerifyMFAService(ctx context.Context, session *model.Session, user *model.User, req model.MFAVerifyRequest) error {
+	ctx, span := tracer.Start(ctx, "service.VerifyMFAService")
+	defer span.End()
+
+	if !session.MFAPending {
+		return errMFANotPending
+	}
+
+	var verifyErr error
+	if req.RecoveryCode != "" {
+		used, err := s.repo.ConsumeRecoveryCode(ctx, user.ID, hashRecoveryCode(req.RecoveryCode))
+		if err != nil {
+			return err
+		}
+		if !used {
+			verifyErr = errInvalidMFACode
+		}
+	} else {
+		secret, enabled, err := s.repo.GetTOTPSecret(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if !enabled {
+			return errTOTPNotEnrolled
+		}
+		verifyErr = s.checkTOTP(ctx, user.ID, secret, req.Code)
+	}
+
+	if verifyErr == errInvalidMFACode {
+		revoked, err := s.repo.RecordFailedMFAAttempt(ctx, session.ID, maxMFAAttempts)
+		if err != nil {
+			return err
+		}
+		if revoked {
+			return errMFALocked
+		}
+	}
+	if verifyErr != nil {
+		return verifyErr
+	}
+
+	return s.repo.CompleteSessionMFA(ctx, session.ID)
+}
+
+// RegenerateRecoveryCodesService replaces all recovery codes of the user after checking a current TOTP code.
+func (s *service) RegenerateRecoveryCodesService(ctx context.Context, user *model.User, code string) ([]string, error) {
+	ctx, span := tracer.Start(ctx, "service.RegenerateRecoveryCodesService")
+	defer span.End()
+
+	secret, enabled, err := s.repo.GetTOTPSecret(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return nil, errTOTPNotEnrolled
+	}
+
+	if err := s.checkTOTP(ctx, user.ID, secret, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes := newRecoveryCodes()
+
+	if err := s.repo.ReplaceRecoveryCodes(ctx, user.ID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// ResetTOTPService is the admin path for users who lost both their authenticator and recovery codes.
+func (s *service) ResetTOTPService(ctx context.Context, userID int) error {
+	ctx, span := tracer.Start(ctx, "service.ResetTOTPService")
+	defer span.End()
+
+	return s.repo.ResetTOTP(ctx, userID)
+}
+
+// checkTOTP validates code and burns its time step, so the same code cannot be used twice.
+func (s *service) checkTOTP(ctx context.Context, userID int, secret, code string) error {
+	step, ok := totp.Validate(secret, code, time.Now(), totpSkew)
+	if !ok {
+		return errInvalidMFACode
+	}
+
+	fresh, err := s.repo.UseTOTPStep(ctx, userID, step)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return errInvalidMFACode
+	}
+	return nil
+}
+
+// newRecoveryCodes returns recovery codes formatted as xxxxx-xxxxx together with their hashes.
+// The base32 alphabet of rand.Text leaves out 0, 1, 8 and 9, which are easily confused when read from paper.
+func newRecoveryCodes() ([]string, []string) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	for range recoveryCodeCount {
+		text := strings.ToLower(rand.Text())
+		code := text[:5] + "-" + text[5:10]
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	return codes, hashes
+}
+
+// hashRecoveryCode normalizes case and separators before hashing, so codes typed with
+// spaces or in upper case still match.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	return hashToken(normalized)
+}
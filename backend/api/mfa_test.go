@@ -0,0 +1,63 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+	"timetracker/api/model"
+	"timetracker/internal/config"
+	"timetracker/internal/totp"
+	"timetracker/logger"
+)
+
+// TestCheckTOTPRejectsReusedStep signs in twice with the same code and then with a code of an
+// earlier step. Only the first attempt may succeed. It needs TEST_DATABASE_URL.
+func TestCheckTOTPRejectsReusedStep(t *testing.T) {
+	database, withDB := openTestDatabase(t)
+	if !withDB {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	log := logger.NewLogger("mfa", filepath.Join(t.TempDir(), "api.log"))
+	s := Service(Repository(database, log), &config.Config{})
+	ctx := context.Background()
+
+	suffix := time.Now().UnixNano()
+	user, err := s.repo.UpsertOIDCUser(ctx, model.OIDCIdentity{
+		Issuer:  "mfa-test",
+		Subject: fmt.Sprint(suffix),
+		Email:   fmt.Sprintf("mfa-%d@example.com", suffix),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.repo.SetPendingTOTPSecret(ctx, user.ID, secret); err != nil {
+		t.Fatalf("Failed to store secret: %v", err)
+	}
+
+	step := totp.Step(time.Now())
+	code, _ := totp.Code(secret, step)
+	if err := s.checkTOTP(ctx, user.ID, secret, code); err != nil {
+		t.Fatalf("First use of the code failed: %v", err)
+	}
+	if err := s.checkTOTP(ctx, user.ID, secret, code); !errors.Is(err, errInvalidMFACode) {
+		t.Errorf("Reused code answered %v, want errInvalidMFACode", err)
+	}
+
+	earlier, _ := totp.Code(secret, step-1)
+	if err := s.checkTOTP(ctx, user.ID, secret, earlier); !errors.Is(err, errInvalidMFACode) {
+		t.Errorf("Code of an earlier step answered %v, want errInvalidMFACode", err)
+	}
+}
@@ -0,0 +1,153 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"bufio"
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"time"
+	"timetracker/logger"
+)
+
+// responseRecorder passes the response through and remembers its status and size. Flush and
+// Hijack reach the wrapped writer, so event streams and live sockets keep working behind it.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += int64(n)
+	return n, err
+}
+
+func (rec *responseRecorder) Flush() {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	http.NewResponseController(rec.ResponseWriter).Flush()
+}
+
+func (rec *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(rec.ResponseWriter).Hijack()
+	if err == nil && rec.status == 0 {
+		// The handler answers on the connection itself, e.g. with 101 Switching Protocols.
+		rec.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+func (rec *responseRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// withRequestID tags every request with an ID: the X-Request-ID the client or a proxy sent, or a
+// new random one. The ID is echoed in the response header, written with every log line of the
+// request and returned in problem responses, so a failed request reported by a user can be found
+// in the logs.
+func (r *router) withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		id := newRequestID(req)
+		w.Header().Set(requestIDHeader, id)
+
+		ctx := context.WithValue(req.Context(), requestIDContextKey, id)
+		ctx = logger.ContextWith(ctx, logger.RequestID(id))
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+// accessLog logs every request once it is answered, with its status, response size and duration.
+// Health checks are only logged at debug level.
+func (r *router) accessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		rec := &responseRecorder{ResponseWriter: w}
+
+		defer func() {
+			// Only aborted responses get here, recoverPanic answers the other panics.
+			aborted := recover()
+
+			level := slog.LevelInfo
+			if req.URL.Path == "/health" {
+				level = slog.LevelDebug
+			}
+			status := rec.status
+			if status == 0 && aborted == nil {
+				status = http.StatusOK
+			}
+			attrs := []slog.Attr{
+				slog.String("method", req.Method),
+				slog.String("path", req.URL.Path),
+				slog.Int("status", status),
+				slog.Int64("bytes", rec.bytes),
+				slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+				slog.String("remote_addr", req.RemoteAddr),
+				slog.String("user_agent", req.UserAgent()),
+			}
+			if aborted != nil {
+				level = slog.LevelError
+				attrs = append(attrs, slog.Bool("aborted", true))
+			}
+			r.logger.Slog().LogAttrs(req.Context(), level, "request", attrs...)
+
+			if aborted != nil {
+				panic(aborted)
+			}
+		}()
+
+		next.ServeHTTP(rec, req)
+	})
+}
+
+// recoverPanic turns a panic in a handler into a 500 problem response and logs it with the stack,
+// instead of dropping the connection. When the response was already started, the connection is
+// aborted so the client does not take the partial response for a complete one.
+func (r *router) recoverPanic(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rec := &responseRecorder{ResponseWriter: w}
+
+		defer func() {
+			value := recover()
+			if value == nil {
+				return
+			}
+			if value == http.ErrAbortHandler {
+				panic(value)
+			}
+
+			r.logger.Slog().LogAttrs(req.Context(), slog.LevelError, "recoverPanic: Panic while serving "+req.Method+" "+req.URL.Path,
+				slog.Any("panic", value),
+				slog.String("stack", string(debug.Stack())))
+
+			if rec.status != 0 {
+				panic(http.ErrAbortHandler)
+			}
+			r.handler.sendErrorResponse(w, req, http.StatusInternalServerError,
+				"Internal server error",
+				"An unexpected error occurred while processing the request",
+				"INTERNAL_ERROR")
+		}()
+
+		next.ServeHTTP(rec, req)
+	})
+}
@@ -0,0 +1,330 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"timetracker/api/model"
+	"timetracker/internal/config"
+	"timetracker/logger"
+)
+
+// middlewareEnv serves extra test routes through the middleware chain of SetRoutes and collects
+// the JSON log records.
+type middlewareEnv struct {
+	server  *httptest.Server
+	logPath string
+}
+
+func newMiddlewareEnv(t *testing.T, routes map[string]http.HandlerFunc) *middlewareEnv {
+	t.Helper()
+
+	env := &middlewareEnv{logPath: filepath.Join(t.TempDir(), "api.log")}
+	log, err := logger.New("api", logger.Options{Level: "debug", Format: "json", File: env.logPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { log.Close() })
+
+	cfg := &config.Config{}
+	r := Router(log, Handler(Service(Repository(nil, log), cfg), log, cfg), cfg)
+	for pattern, handler := range routes {
+		r.mux.HandleFunc(pattern, handler)
+	}
+
+	env.server = httptest.NewServer(r.SetRoutes())
+	t.Cleanup(env.server.Close)
+	return env
+}
+
+func (env *middlewareEnv) get(t *testing.T, path string, header http.Header) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, env.server.URL+path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET %s: %v", path, err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// records returns the log records with msg.
+func (env *middlewareEnv) records(t *testing.T, msg string) []map[string]any {
+	t.Helper()
+
+	data, err := os.ReadFile(env.logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Log line is no JSON: %q", line)
+		}
+		if strings.HasPrefix(record["msg"].(string), msg) {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+func TestRequestID(t *testing.T) {
+	env := newMiddlewareEnv(t, nil)
+
+	tests := []struct {
+		name     string
+		incoming string
+		kept     bool
+	}{
+		{name: "honors the incoming ID", incoming: "req-1234", kept: true},
+		{name: "generates a missing ID"},
+		{name: "replaces an ID with spaces", incoming: "not an id"},
+		{name: "replaces an overlong ID", incoming: strings.Repeat("x", maxRequestIDLength+1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.incoming != "" {
+				header.Set(requestIDHeader, tt.incoming)
+			}
+			resp := env.get(t, "/v1/trackers/abc", header)
+
+			id := resp.Header.Get(requestIDHeader)
+			if tt.kept && id != tt.incoming {
+				t.Fatalf("X-Request-ID = %q, want %q", id, tt.incoming)
+			}
+			if !tt.kept && (len(id) != 32 || id == tt.incoming) {
+				t.Fatalf("X-Request-ID = %q, want a new 32 character ID", id)
+			}
+
+			var problem model.Problem
+			if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil {
+				t.Fatal(err)
+			}
+			if problem.RequestID != id {
+				t.Errorf("problem request_id = %q, want the header %q", problem.RequestID, id)
+			}
+
+			for _, record := range env.records(t, "request") {
+				if record["request_id"] == id {
+					return
+				}
+			}
+			t.Errorf("no access log record with request_id %q", id)
+		})
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	env := newMiddlewareEnv(t, map[string]http.HandlerFunc{
+		"GET /test/teapot": func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+			io.WriteString(w, "short and stout")
+		},
+	})
+
+	resp := env.get(t, "/test/teapot", http.Header{"User-Agent": {"middleware-test"}})
+	io.Copy(io.Discard, resp.Body)
+
+	records := env.records(t, "request")
+	if len(records) != 1 {
+		t.Fatalf("got %d access log records, want 1", len(records))
+	}
+	record := records[0]
+	want := map[string]any{
+		"level":      "INFO",
+		"method":     "GET",
+		"path":       "/test/teapot",
+		"status":     float64(http.StatusTeapot),
+		"bytes":      float64(len("short and stout")),
+		"user_agent": "middleware-test",
+		"request_id": resp.Header.Get(requestIDHeader),
+	}
+	for key, value := range want {
+		if record[key] != value {
+			t.Errorf("%s = %v, want %v", key, record[key], value)
+		}
+	}
+	if _, ok := record["duration_ms"].(float64); !ok {
+		t.Errorf("duration_ms missing: %v", record)
+	}
+}
+
+func TestRecoverPanic(t *testing.T) {
+	env := newMiddlewareEnv(t, map[string]http.HandlerFunc{
+		"GET /test/panic": func(w http.ResponseWriter, req *http.Request) {
+			panic("handler bug")
+		},
+		"GET /test/partial": func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("Content-Length", "100")
+			io.WriteString(w, "partial")
+			w.(http.Flusher).Flush()
+			panic("handler bug after writing")
+		},
+	})
+
+	resp := env.get(t, "/test/panic", nil)
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != problemContentType {
+		t.Errorf("Content-Type = %q, want %q", ct, problemContentType)
+	}
+	var problem model.Problem
+	if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil {
+		t.Fatal(err)
+	}
+	if problem.Code != "INTERNAL_ERROR" || problem.RequestID != resp.Header.Get(requestIDHeader) {
+		t.Errorf("unexpected problem %+v", problem)
+	}
+
+	panics := env.records(t, "recoverPanic")
+	if len(panics) != 1 {
+		t.Fatalf("got %d panic records, want 1", len(panics))
+	}
+	if panics[0]["panic"] != "handler bug" || !strings.Contains(panics[0]["stack"].(string), "TestRecoverPanic") {
+		t.Errorf("panic record lacks the value or the stack: %v", panics[0])
+	}
+
+	// A started response cannot be replaced, the client has to notice the broken connection.
+	resp = env.get(t, "/test/partial", nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want the 200 already sent", resp.StatusCode)
+	}
+	if _, err := io.ReadAll(resp.Body); err == nil {
+		t.Errorf("reading the partial response succeeded, want an error")
+	}
+
+	// The server survives both panics.
+	if resp := env.get(t, "/health", nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("health status = %d after the panics, want 200", resp.StatusCode)
+	}
+}
+
+func TestMiddlewareKeepsStreaming(t *testing.T) {
+	env := newMiddlewareEnv(t, map[string]http.HandlerFunc{
+		"GET /test/stream": func(w http.ResponseWriter, req *http.Request) {
+			io.WriteString(w, "first\n")
+			if err := http.NewResponseController(w).Flush(); err != nil {
+				t.Errorf("Flush through the middleware: %v", err)
+			}
+			<-req.Context().Done()
+		},
+		"GET /test/hijack": func(w http.ResponseWriter, req *http.Request) {
+			conn, rw, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Errorf("Hijack through the middleware: %v", err)
+				return
+			}
+			defer conn.Close()
+			rw.WriteString("HTTP/1.1 204 No Content\r\nConnection: close\r\n\r\n")
+			rw.Flush()
+		},
+	})
+
+	resp := env.get(t, "/test/stream", nil)
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	if err != nil || line != "first\n" {
+		t.Fatalf("read %q, %v before the handler finished, want the flushed line", line, err)
+	}
+	resp.Body.Close()
+
+	if resp := env.get(t, "/test/hijack", nil); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("status = %d, want the 204 written on the hijacked connection", resp.StatusCode)
+	}
+}
+
+func TestIdempotencyScope(t *testing.T) {
+	log := logger.NewLogger("idempotency", filepath.Join(t.TempDir(), "api.log"))
+	cfg := &config.Config{Idempotency: config.IdempotencyConfig{Enabled: true}}
+	r := Router(log, Handler(Service(Repository(nil, log), cfg), log, cfg), cfg)
+
+	tests := []struct {
+		name    string
+		session *model.Session
+		want    string
+	}{
+		{"anonymous", nil, "anon:192.0.2.1"},
+		{"second factor pending", &model.Session{UserID: 7, MFAPending: true}, "anon:192.0.2.1"},
+		{"verified", &model.Session{UserID: 7}, "user:7"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/v1/trackers", nil)
+		if tt.session != nil {
+			ctx := context.WithValue(req.Context(), userContextKey, &model.User{ID: tt.session.UserID})
+			req = req.WithContext(context.WithValue(ctx, sessionContextKey, tt.session))
+		}
+		if got := r.idempotencyScope(req); got != tt.want {
+			t.Errorf("%s: scope %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestIdempotencyReplaysAnonymousRetry retries an anonymous request and expects the stored
+// response instead of a second execution, while another client IP has a scope of its own. It
+// needs TEST_DATABASE_URL.
+func TestIdempotencyReplaysAnonymousRetry(t *testing.T) {
+	database, withDB := openTestDatabase(t)
+	if !withDB {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	log := logger.NewLogger("idempotency", filepath.Join(t.TempDir(), "api.log"))
+	cfg := &config.Config{Idempotency: config.IdempotencyConfig{Enabled: true}}
+	r := Router(log, Handler(Service(Repository(database, log), cfg), log, cfg), cfg)
+	executions := 0
+	handler := r.idempotency(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		executions++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"id":%d}`, executions)
+	}))
+
+	key := fmt.Sprintf("anonymous-retry-%d", time.Now().UnixNano())
+	send := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/trackers", strings.NewReader(`{"task":"Review"}`))
+		req.RemoteAddr = remoteAddr
+		req.Header.Set(idempotencyKeyHeader, key)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	first := send("198.51.100.7:40000")
+	retry := send("198.51.100.7:40001")
+	if executions != 1 {
+		t.Fatalf("The retry executed the request again, %d executions", executions)
+	}
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() ||
+		retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("Retry answered %d %s, want the stored %d %s", retry.Code, retry.Body, first.Code, first.Body)
+	}
+
+	if other := send("203.0.113.9:40000"); other.Body.String() == first.Body.String() || executions != 2 {
+		t.Errorf("Another client got the stored response %s", other.Body)
+	}
+}
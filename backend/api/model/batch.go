@@ -0,0 +1,54 @@
+package model
+
+import "encoding/json"
+
+const (
+	BatchModeAtomic     = "atomic"
+	BatchModeBestEffort = "best_effort"
+)
+
+const (
+	BatchOpCreate = "create"
+	BatchOpUpdate = "update"
+	BatchOpPatch  = "patch"
+	BatchOpDelete = "delete"
+)
+
+type BatchRequest struct {
+	Mode string `json:"mode" validate:"omitempty,oneof=atomic best_effort"`
+	// Operations is bounded to limit the work, and the length of the transaction, of a single batch.
+	Operations []BatchOperation `json:"operations" validate:"min=1,max=100"`
+}
+
+// BatchOperation is a single write of a batch. Data holds the body the matching single request
+// takes: a CreateTrackerRequest, an UpdateTrackerRequest or a TrackerPatch. Version makes update,
+// patch and delete conditional like an If-Match header.
+type BatchOperation struct {
+	Op      string          `json:"op" validate:"oneof=create update patch delete"`
+	ID      int             `json:"id,omitempty"`
+	Version *int            `json:"version,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+type BatchOperationResult struct {
+	Index   int             `json:"index"`
+	Op      string          `json:"op"`
+	ID      int             `json:"id,omitempty"`
+	Status  int             `json:"status"`
+	Tracker *Tracker        `json:"tracker,omitempty"`
+	Error   *OperationError `json:"error,omitempty"`
+}
+
+// OperationError describes why a single operation of a batch or live socket message failed.
+// Errors lists the invalid fields when the operation failed validation.
+type OperationError struct {
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Errors  []FieldError `json:"errors,omitempty"`
+}
+
+type BatchResponse struct {
+	Mode      string                 `json:"mode"`
+	Committed bool                   `json:"committed"`
+	Results   []BatchOperationResult `json:"results"`
+}
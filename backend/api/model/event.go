@@ -0,0 +1,24 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	TrackerEventCreated = "created"
+	TrackerEventUpdated = "updated"
+	TrackerEventDeleted = "deleted"
+)
+
+// TrackerEvent is a change of a tracker, recorded by a database trigger. Data holds the tracker
+// after the change, or only its id for deletions. TxID is the transaction that made the change.
+type TrackerEvent struct {
+	ID        int64           `json:"id" db:"id"`
+	TxID      uint64          `json:"-" db:"txid"`
+	TrackerID int             `json:"tracker_id" db:"tracker_id"`
+	Type      string          `json:"type" db:"type"`
+	Version   int             `json:"version" db:"version"`
+	Data      json.RawMessage `json:"data" db:"payload"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+)
+
+// IdempotencyRecord is the stored outcome of a request sent with an Idempotency-Key.
+// StatusCode is nil while the first request with the key is still being processed.
+type IdempotencyRecord struct {
+	Scope           string              `db:"scope"`
+	Key             string              `db:"key"`
+	RequestHash     string              `db:"request_hash"`
+	StatusCode      *int                `db:"status_code"`
+	ResponseHeaders map[string][]string `db:"response_headers"`
+	ResponseBody    []byte              `db:"response_body"`
+	ExpiresAt       time.Time           `db:"expires_at"`
+	CreatedAt       time.Time           `db:"created_at"`
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+)
+
+// Message types of the live timer socket. Clients send subscribe, start and stop, the server
+// answers with state, started, stopped, tick and error.
+const (
+	LiveSubscribe = "subscribe"
+	LiveStart     = "start"
+	LiveStop      = "stop"
+	LiveState     = "state"
+	LiveStarted   = "started"
+	LiveStopped   = "stopped"
+	LiveTick      = "tick"
+	LiveError     = "error"
+)
+
+// LiveRequest is a message from a client. Ref is an optional client chosen id that is echoed in
+// the answer, so a client can match it with its request.
+type LiveRequest struct {
+	Type      string  `json:"type"`
+	Ref       string  `json:"ref,omitempty"`
+	Task      string  `json:"task,omitempty"`
+	Project   *string `json:"project,omitempty"`
+	TrackerID int     `json:"tracker_id,omitempty"`
+}
+
+// LiveMessage is a message from the server. Every message but error carries the running timers
+// of the user, so clients can render them without keeping state of their own. Running is left out
+// when no timer runs.
+type LiveMessage struct {
+	Type       string          `json:"type"`
+	Ref        string          `json:"ref,omitempty"`
+	Tracker    *Tracker        `json:"tracker,omitempty"`
+	Running    []LiveTimer     `json:"running"`
+	ServerTime time.Time       `json:"server_time"`
+	Error      *OperationError `json:"error,omitempty"`
+}
+
+// LiveTimer is a running tracker together with the time elapsed since its start.
+type LiveTimer struct {
+	Tracker
+	ElapsedSeconds int64 `json:"elapsed_seconds"`
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Nullable is a field of a JSON merge patch. It tells apart a member that is absent
+// (Set is false), explicitly null (Set is true, Valid is false) and holding a value.
+type Nullable[T any] struct {
+	Set   bool
+	Valid bool
+	Value T
+}
+
+// NullableValue returns a Nullable that is set to v.
+func NullableValue[T any](v T) Nullable[T] {
+	return Nullable[T]{Set: true, Valid: true, Value: v}
+}
+
+// NullableFromPtr returns a Nullable that is set to *v, or to null when v is nil.
+func NullableFromPtr[T any](v *T) Nullable[T] {
+	if v == nil {
+		return Nullable[T]{Set: true}
+	}
+	return NullableValue(*v)
+}
+
+// UnmarshalJSON is only called by encoding/json for members present in the document,
+// which is what marks the field as set.
+func (n *Nullable[T]) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		var zero T
+		n.Valid, n.Value = false, zero
+		return nil
+	}
+
+	if err := json.Unmarshal(data, &n.Value); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// OptionalValue lets the validation package tell absent, null and set members apart.
+func (n Nullable[T]) OptionalValue() (any, bool, bool) {
+	return n.Value, n.Set, n.Valid
+}
+
+// Ptr returns the value as a pointer, nil when the field is null or absent.
+func (n Nullable[T]) Ptr() *T {
+	if !n.Valid {
+		return nil
+	}
+	return &n.Value
+}
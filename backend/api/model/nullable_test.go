@@ -0,0 +1,89 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTrackerPatchDecoding(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		set       bool
+		valid     bool
+		project   string
+		isEmpty   bool
+		decodeErr bool
+	}{
+		{"absent", `{}`, false, false, "", true, false},
+		{"null", `{"project": null}`, true, false, "", false, false},
+		{"null with spaces", `{"project":  null }`, true, false, "", false, false},
+		{"value", `{"project": "Website"}`, true, true, "Website", false, false},
+		{"empty string is a value", `{"project": ""}`, true, true, "", false, false},
+		{"wrong type", `{"project": 42}`, false, false, "", false, true},
+	}
+
+	for _, tt := range tests {
+		var patch TrackerPatch
+		err := json.Unmarshal([]byte(tt.body), &patch)
+		if (err != nil) != tt.decodeErr {
+			t.Errorf("%s: decode error %v, want error %v", tt.name, err, tt.decodeErr)
+			continue
+		}
+		if tt.decodeErr {
+			continue
+		}
+		if patch.Project.Set != tt.set || patch.Project.Valid != tt.valid || patch.Project.Value != tt.project {
+			t.Errorf("%s: decoded %+v, want set %v, valid %v, value %q", tt.name, patch.Project, tt.set, tt.valid, tt.project)
+		}
+		if patch.IsEmpty() != tt.isEmpty {
+			t.Errorf("%s: IsEmpty %v, want %v", tt.name, patch.IsEmpty(), tt.isEmpty)
+		}
+	}
+}
+
+func TestTrackerPatchApply(t *testing.T) {
+	start := time.Date(2025, 10, 9, 9, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	project := "Website"
+	stored := Tracker{Task: "Review", Project: &project, StartTime: start, EndTime: &end}
+
+	tests := []struct {
+		name    string
+		body    string
+		project *string
+		running bool
+	}{
+		{"absent keeps", `{"task": "Write"}`, &project, false},
+		{"null clears", `{"project": null, "end_time": null}`, nil, true},
+		{"value replaces", `{"project": "Backend"}`, ptr("Backend"), false},
+	}
+
+	for _, tt := range tests {
+		var patch TrackerPatch
+		if err := json.Unmarshal([]byte(tt.body), &patch); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		merged := patch.Apply(stored)
+		if (merged.Project == nil) != (tt.project == nil) || merged.Project != nil && *merged.Project != *tt.project {
+			t.Errorf("%s: project %v, want %v", tt.name, merged.Project, tt.project)
+		}
+		if (merged.EndTime == nil) != tt.running {
+			t.Errorf("%s: end time %v, want running %v", tt.name, merged.EndTime, tt.running)
+		}
+	}
+	if stored.Project != &project || stored.EndTime != &end {
+		t.Error("Apply changed the stored tracker")
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,38 @@
+package model
+
+import "timetracker/validation"
+
+// Codes of a FieldError, stable so clients can map them to their own messages. The codes of
+// invalid request fields are the ones of the validation package.
+const (
+	FieldErrorRequired   = validation.CodeRequired
+	FieldErrorBlank      = validation.CodeBlank
+	FieldErrorNull       = validation.CodeNull
+	FieldErrorTooShort   = validation.CodeTooShort
+	FieldErrorTooLong    = validation.CodeTooLong
+	FieldErrorOutOfRange = validation.CodeOutOfRange
+	FieldErrorOutOfOrder = validation.CodeOutOfOrder
+	FieldErrorInvalid    = validation.CodeInvalid
+	FieldErrorDuplicate  = "duplicate"
+)
+
+// Problem is an error response as defined by RFC 7807, sent as application/problem+json.
+// Code and RequestID are extension members, Errors lists the invalid fields of a request body.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError describes why one field of a request is invalid. Field is the JSON name of the
+// field, nested fields are joined with a dot (e.g. data.task), an empty field means the whole body.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
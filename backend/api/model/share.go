@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+)
+
+type ShareLink struct {
+	ID        int        `json:"id" db:"id"`
+	CreatedBy int        `json:"created_by" db:"created_by"`
+	Project   *string    `json:"project,omitempty" db:"project"`
+	From      *time.Time `json:"from,omitempty" db:"from_time"`
+	To        *time.Time `json:"to,omitempty" db:"to_time"`
+	ExpiresAt time.Time  `json:"expires_at" db:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	URL       string     `json:"url,omitempty" db:"-"`
+}
+
+type CreateShareLinkRequest struct {
+	Project        *string    `json:"project,omitempty" validate:"omitempty,max=200"`
+	From           *time.Time `json:"from,omitempty"`
+	To             *time.Time `json:"to,omitempty" validate:"omitempty,gtfield=From"`
+	ExpiresInHours int        `json:"expires_in_hours,omitempty" validate:"min=0"`
+}
+
+type Report struct {
+	Project      *string       `json:"project,omitempty"`
+	From         *time.Time    `json:"from,omitempty"`
+	To           *time.Time    `json:"to,omitempty"`
+	GeneratedAt  time.Time     `json:"generated_at"`
+	ExpiresAt    time.Time     `json:"expires_at"`
+	TotalSeconds int64         `json:"total_seconds"`
+	TotalHours   float64       `json:"total_hours"`
+	Entries      []ReportEntry `json:"entries"`
+}
+
+type ReportEntry struct {
+	Task            string     `json:"task"`
+	Project         *string    `json:"project,omitempty"`
+	StartTime       time.Time  `json:"start_time"`
+	EndTime         *time.Time `json:"end_time,omitempty"`
+	DurationSeconds int64      `json:"duration_seconds"`
+}
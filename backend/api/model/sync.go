@@ -0,0 +1,74 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	SyncOpUpsert = "upsert"
+	SyncOpDelete = "delete"
+
+	SyncStatusApplied  = "applied"
+	SyncStatusConflict = "conflict"
+	SyncStatusRejected = "rejected"
+
+	SyncResolutionClientWins = "client_wins"
+	SyncResolutionServerWins = "server_wins"
+
+	// SyncConflictModified means the tracker changed on the server after the version the client edited.
+	SyncConflictModified = "modified_on_server"
+	// SyncConflictDeleted means the tracker the client edited was deleted on the server.
+	SyncConflictDeleted = "deleted_on_server"
+)
+
+// SyncChange is a change a client made offline to the tracker it identifies by ClientID.
+type SyncChange struct {
+	ClientID string `json:"client_id" validate:"uuid"`
+	Op       string `json:"op" validate:"oneof=upsert delete"`
+	// BaseVersion is the server version the client edited, absent for trackers created offline.
+	BaseVersion *int `json:"base_version,omitempty" validate:"omitempty,min=1"`
+	// ModifiedAt is when the change was made on the device, it decides conflicting edits.
+	ModifiedAt time.Time `json:"modified_at" validate:"required"`
+	// Data is the complete tracker for upserts, like the body of PUT.
+	Data *UpdateTrackerRequest `json:"data,omitempty"`
+}
+
+type SyncRequest struct {
+	Since string `json:"since,omitempty"`
+	// Changes is bounded to limit the work, and the length of the transaction, of a single upload.
+	Changes []SyncChange `json:"changes" validate:"max=500"`
+}
+
+// SyncTombstone reports a deleted tracker.
+type SyncTombstone struct {
+	ID        int       `json:"id"`
+	ClientID  *string   `json:"client_id,omitempty"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+type SyncConflict struct {
+	Reason     string `json:"reason"`
+	Resolution string `json:"resolution"`
+	// ServerVersion is the version the server had when the conflict was detected.
+	ServerVersion int `json:"server_version,omitempty"`
+}
+
+type SyncChangeResult struct {
+	ClientID string          `json:"client_id"`
+	Status   string          `json:"status"`
+	Tracker  *Tracker        `json:"tracker,omitempty"`
+	Deleted  bool            `json:"deleted,omitempty"`
+	Conflict *SyncConflict   `json:"conflict,omitempty"`
+	Error    *OperationError `json:"error,omitempty"`
+}
+
+// SyncResponse carries the trackers changed and deleted since the cursor of the request, and the
+// cursor to send next time. With Reset the cursor was missing or too old and Trackers holds all
+// trackers, the client replaces its synced state with them.
+type SyncResponse struct {
+	Cursor   string             `json:"cursor"`
+	Reset    bool               `json:"reset"`
+	Trackers []Tracker          `json:"trackers"`
+	Deleted  []SyncTombstone    `json:"deleted"`
+	Results  []SyncChangeResult `json:"results,omitempty"`
+}
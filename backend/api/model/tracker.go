@@ -7,19 +7,93 @@ import (
 type Tracker struct {
 	ID        int        `json:"id" db:"id"`
 	Task      string     `json:"task" db:"task"`
+	Project   *string    `json:"project,omitempty" db:"project"`
 	StartTime time.Time  `json:"start_time" db:"start_time"`
 	EndTime   *time.Time `json:"end_time,omitempty" db:"end_time"`
 	CreatedAt time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	Version   int        `json:"version" db:"version"`
+	UserID    *int       `json:"user_id,omitempty" db:"user_id"`
+	// ClientID is the UUID an offline client generated for a tracker it created, see POST /sync.
+	ClientID *string `json:"client_id,omitempty" db:"client_id"`
 }
 
 type CreateTrackerRequest struct {
 	Task      string     `json:"task" validate:"required,min=1,max=500"`
+	Project   *string    `json:"project,omitempty" validate:"omitempty,max=200"`
 	StartTime time.Time  `json:"start_time" validate:"required"`
-	EndTime   *time.Time `json:"end_time,omitempty"`
+	EndTime   *time.Time `json:"end_time,omitempty" validate:"omitempty,gtefield=StartTime"`
+	// UserID is set from the session of the request, never from the payload.
+	UserID *int `json:"-"`
+	// ClientID is only set for trackers created through POST /sync.
+	ClientID *string `json:"-"`
 }
+
+// UpdateTrackerRequest is the body of PUT, which replaces the whole tracker. Absent optional
+// fields are cleared.
 type UpdateTrackerRequest struct {
-	Task      *string    `json:"task,omitempty" validate:"omitempty,min=1,max=500"`
-	StartTime *time.Time `json:"start_time,omitempty"`
-	EndTime   *time.Time `json:"end_time,omitempty"`
+	Task      string     `json:"task" validate:"required,min=1,max=500"`
+	Project   *string    `json:"project,omitempty" validate:"omitempty,max=200"`
+	StartTime time.Time  `json:"start_time" validate:"required"`
+	EndTime   *time.Time `json:"end_time,omitempty" validate:"omitempty,gtefield=StartTime"`
+}
+
+// TrackerPatch is an RFC 7396 JSON merge patch of a tracker. Absent members are left unchanged
+// and null members are cleared, which e.g. sets a stopped tracker back to running.
+type TrackerPatch struct {
+	Task      Nullable[string]    `json:"task" validate:"required,min=1,max=500"`
+	Project   Nullable[string]    `json:"project" validate:"max=200"`
+	StartTime Nullable[time.Time] `json:"start_time" validate:"required"`
+	EndTime   Nullable[time.Time] `json:"end_time" validate:"gtefield=StartTime"`
+}
+
+// IsEmpty reports whether the patch does not touch any field.
+func (p TrackerPatch) IsEmpty() bool {
+	return !p.Task.Set && !p.Project.Set && !p.StartTime.Set && !p.EndTime.Set
+}
+
+// Apply returns a copy of tracker with the patch merged in.
+func (p TrackerPatch) Apply(tracker Tracker) Tracker {
+	if p.Task.Set {
+		tracker.Task = p.Task.Value
+	}
+	if p.Project.Set {
+		tracker.Project = p.Project.Ptr()
+	}
+	if p.StartTime.Set {
+		tracker.StartTime = p.StartTime.Value
+	}
+	if p.EndTime.Set {
+		tracker.EndTime = p.EndTime.Ptr()
+	}
+	return tracker
+}
+
+type TrackerFilter struct {
+	Project *string
+	From    *time.Time
+	To      *time.Time
+	UserID  *int
+	Running bool
+	// Limit bounds the number of trackers returned, 0 returns all of them. Offset skips the
+	// first trackers of the result.
+	Limit  int
+	Offset int
+}
+
+// TrackerSummary aggregates the durations of a set of trackers. Running trackers count up to now.
+type TrackerSummary struct {
+	Count        int              `json:"count"`
+	Running      int              `json:"running"`
+	TotalSeconds int64            `json:"total_seconds"`
+	TotalHours   float64          `json:"total_hours"`
+	Projects     []ProjectSummary `json:"projects"`
+}
+
+// ProjectSummary is the share of a single project, or of trackers without project, in a TrackerSummary.
+type ProjectSummary struct {
+	Project      *string `json:"project,omitempty"`
+	Count        int     `json:"count"`
+	TotalSeconds int64   `json:"total_seconds"`
+	TotalHours   float64 `json:"total_hours"`
 }
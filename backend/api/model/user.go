@@ -0,0 +1,70 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+type User struct {
+	ID          int        `json:"id" db:"id"`
+	Email       string     `json:"email" db:"email"`
+	Name        string     `json:"name" db:"name"`
+	Role        string     `json:"role" db:"role"`
+	TOTPEnabled bool       `json:"totp_enabled" db:"totp_enabled"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type Session struct {
+	ID         int       `json:"id" db:"id"`
+	UserID     int       `json:"user_id" db:"user_id"`
+	MFAPending bool      `json:"mfa_pending" db:"mfa_pending"`
+	ExpiresAt  time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+type OIDCLoginRequest struct {
+	State        string    `db:"state"`
+	Nonce        string    `db:"nonce"`
+	CodeVerifier string    `db:"code_verifier"`
+	ExpiresAt    time.Time `db:"expires_at"`
+}
+
+type OIDCIdentity struct {
+	Issuer  string
+	Subject string
+	Email   string
+	Name    string
+	Role    string
+}
+
+type LoginResponse struct {
+	Token       string    `json:"token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	MFARequired bool      `json:"mfa_required"`
+	User        User      `json:"user"`
+}
+
+type TOTPEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type TOTPCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type MFAVerifyRequest struct {
+	Code         string `json:"code,omitempty"`
+	RecoveryCode string `json:"recovery_code,omitempty"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
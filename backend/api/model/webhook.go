@@ -0,0 +1,85 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Event types a webhook subscription can listen to. Started and stopped are sent next to created
+// and updated when a timer starts running or stops.
+const (
+	WebhookEventTrackerCreated = "tracker.created"
+	WebhookEventTrackerUpdated = "tracker.updated"
+	WebhookEventTrackerDeleted = "tracker.deleted"
+	WebhookEventTrackerStarted = "tracker.started"
+	WebhookEventTrackerStopped = "tracker.stopped"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookSubscription receives the tracker events listed in EventTypes at URL. Secret signs the
+// deliveries and is only returned when the subscription is created.
+type WebhookSubscription struct {
+	ID         int       `json:"id" db:"id"`
+	URL        string    `json:"url" db:"url"`
+	EventTypes []string  `json:"event_types" db:"event_types"`
+	Secret     string    `json:"secret,omitempty" db:"secret"`
+	CreatedBy  *int      `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// CreateWebhookRequest registers a subscription. A secret is generated when none is given.
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" validate:"required,url,max=2000"`
+	EventTypes []string `json:"event_types" validate:"min=1,oneof=tracker.created tracker.updated tracker.deleted tracker.started tracker.stopped"`
+	Secret     string   `json:"secret,omitempty" validate:"omitempty,min=16,max=200"`
+}
+
+// WebhookDeliveryFilter selects the deliveries of the delivery log. An empty status matches every
+// status, Limit defaults to 50.
+type WebhookDeliveryFilter struct {
+	Status string `json:"status" validate:"omitempty,oneof=pending delivered failed"`
+	Limit  int    `json:"limit" validate:"min=0,max=200"`
+}
+
+// WebhookDelivery is one event queued for one subscription. NextAttemptAt is when a pending
+// delivery is sent next, History lists its attempts, oldest first.
+type WebhookDelivery struct {
+	ID             int64                    `json:"id" db:"id"`
+	SubscriptionID int                      `json:"subscription_id" db:"subscription_id"`
+	EventID        int64                    `json:"event_id" db:"event_id"`
+	EventType      string                   `json:"event_type" db:"event_type"`
+	Payload        json.RawMessage          `json:"payload" db:"payload"`
+	Status         string                   `json:"status" db:"status"`
+	Attempts       int                      `json:"attempts" db:"attempts"`
+	NextAttemptAt  *time.Time               `json:"next_attempt_at,omitempty" db:"next_attempt_at"`
+	LastStatusCode *int                     `json:"last_status_code,omitempty" db:"last_status_code"`
+	LastError      *string                  `json:"last_error,omitempty" db:"last_error"`
+	DeliveredAt    *time.Time               `json:"delivered_at,omitempty" db:"delivered_at"`
+	CreatedAt      time.Time                `json:"created_at" db:"created_at"`
+	History        []WebhookDeliveryAttempt `json:"history" db:"-"`
+}
+
+// WebhookDeliveryAttempt is one request sent for a delivery. StatusCode is empty when no
+// response arrived, Error describes why the attempt failed.
+type WebhookDeliveryAttempt struct {
+	Attempt     int       `json:"attempt" db:"attempt"`
+	StatusCode  *int      `json:"status_code,omitempty" db:"status_code"`
+	Error       *string   `json:"error,omitempty" db:"error"`
+	DurationMS  int       `json:"duration_ms" db:"duration_ms"`
+	AttemptedAt time.Time `json:"attempted_at" db:"attempted_at"`
+}
+
+// WebhookPayload is the body posted to a subscription. EventID is the id of the tracker event,
+// the same in every delivery of the event; Data holds the tracker after the change, or only its
+// id for deletions.
+type WebhookPayload struct {
+	EventID    int64           `json:"event_id"`
+	Type       string          `json:"type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
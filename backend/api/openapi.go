@@ -0,0 +1,62 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// openAPISpec is the contract of the REST API. The contract tests check every route against it,
+// so it cannot drift from the handlers unnoticed.
+//
+//go:embed openapi/timetracker-api.yaml
+var openAPISpec []byte
+
+// swaggerUIVersion pins the Swagger UI assets loaded by the docs page.
+const swaggerUIVersion = "5.17.14"
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>Time Tracker API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = function () {
+      window.ui = SwaggerUIBundle({ url: "/openapi.yaml", dom_id: "#swagger-ui" });
+    };
+  </script>
+</body>
+</html>
+`
+
+// OpenAPISpecHandler serves the OpenAPI document of the API.
+//
+// Returns:
+//   - 200 OK: The OpenAPI document as YAML
+func (h *handler) OpenAPISpecHandler(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	w.Write(openAPISpec)
+}
+
+// APIDocsHandler serves a Swagger UI page that renders the OpenAPI document.
+//
+// Returns:
+//   - 200 OK: The HTML page
+func (h *handler) APIDocsHandler(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(swaggerUIPage))
+}
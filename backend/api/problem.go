@@ -0,0 +1,138 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"timetracker/api/model"
+	"timetracker/logger"
+	"timetracker/validation"
+)
+
+const (
+	problemContentType = "application/problem+json"
+	problemTypePrefix  = "urn:timetracker:problem:"
+	requestIDHeader    = "X-Request-ID"
+	maxRequestIDLength = 128
+)
+
+// problemType turns an error code into the type URI of a problem, e.g. VALIDATION_ERROR into
+// urn:timetracker:problem:validation-error.
+func problemType(code string) string {
+	if code == "" {
+		return "about:blank"
+	}
+	return problemTypePrefix + strings.ReplaceAll(strings.ToLower(code), "_", "-")
+}
+
+// requestID returns the ID withRequestID gave the request, so an error reported by a user can be
+// found in the logs.
+func requestID(req *http.Request) string {
+	if id, ok := req.Context().Value(requestIDContextKey).(string); ok {
+		return id
+	}
+	return newRequestID(req)
+}
+
+// newRequestID returns the X-Request-ID the client or a proxy sent, or a new random one.
+func newRequestID(req *http.Request) string {
+	if id := req.Header.Get(requestIDHeader); id != "" && len(id) <= maxRequestIDLength && isPrintableASCII(id) {
+		return id
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+func isPrintableASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x21 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func fieldError(field, code, message string) model.FieldError {
+	return model.FieldError{Field: field, Code: code, Message: message}
+}
+
+// validateStruct checks v against its validate tags, see validation.Struct, and returns the
+// invalid fields as they are sent to clients.
+func validateStruct(v any) []model.FieldError {
+	errs := validation.Struct(v)
+	if len(errs) == 0 {
+		return nil
+	}
+	fieldErrors := make([]model.FieldError, len(errs))
+	for i, err := range errs {
+		fieldErrors[i] = fieldError(err.Field, err.Code, err.Message)
+	}
+	return fieldErrors
+}
+
+// prefixFieldErrors nests errs under field, e.g. task becomes data.task.
+func prefixFieldErrors(field string, errs []model.FieldError) []model.FieldError {
+	for i := range errs {
+		if errs[i].Field == "" {
+			errs[i].Field = field
+		} else {
+			errs[i].Field = field + "." + errs[i].Field
+		}
+	}
+	return errs
+}
+
+// fieldErrorMessages joins the messages of errs for logs and for clients that only show one message.
+func fieldErrorMessages(errs []model.FieldError) string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (h *handler) sendProblem(w http.ResponseWriter, problem model.Problem) {
+	if problem.RequestID != "" {
+		w.Header().Set(requestIDHeader, problem.RequestID)
+	}
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(problem.Status)
+
+	log := h.logger.With(logger.RequestID(problem.RequestID))
+	if problem.Status >= 500 {
+		log.Errorf("[%s] %s - %s", problem.Code, problem.Title, problem.Detail)
+	} else if problem.Status >= 400 {
+		log.Warnf("[%s] %s - %s", problem.Code, problem.Title, problem.Detail)
+	} else {
+		log.Infof("[%s] %s - %s", problem.Code, problem.Title, problem.Detail)
+	}
+
+	json.NewEncoder(w).Encode(problem)
+}
+
+// sendValidationErrorResponse answers 400 with one entry per invalid field, so clients can show
+// each message next to its input.
+func (h *handler) sendValidationErrorResponse(w http.ResponseWriter, req *http.Request, errs []model.FieldError) {
+	h.sendProblem(w, model.Problem{
+		Type:      problemType("VALIDATION_ERROR"),
+		Title:     "Validation failed",
+		Status:    http.StatusBadRequest,
+		Detail:    fieldErrorMessages(errs),
+		Instance:  req.URL.Path,
+		Code:      "VALIDATION_ERROR",
+		RequestID: requestID(req),
+		Errors:    errs,
+	})
+}
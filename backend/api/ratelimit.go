@@ -0,0 +1,183 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"timetracker/internal/config"
+)
+
+type tokenBucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// rateLimiter keeps one token bucket per client key. Buckets refill at rate tokens
+// per second up to burst and are dropped once they have been idle for idleTimeout.
+type rateLimiter struct {
+	mu          sync.Mutex
+	buckets     map[string]*tokenBucket
+	rate        float64
+	burst       float64
+	idleTimeout time.Duration
+	lastSweep   time.Time
+	now         func() time.Time
+}
+
+type rateLimitResult struct {
+	allowed    bool
+	remaining  int
+	retryAfter time.Duration
+	reset      time.Duration
+}
+
+func newRateLimiter(cfg config.RateLimitConfig) *rateLimiter {
+	idleTimeout := time.Duration(cfg.IdleTimeoutSeconds) * time.Second
+	if idleTimeout <= 0 {
+		idleTimeout = 10 * time.Minute
+	}
+
+	return &rateLimiter{
+		buckets:     make(map[string]*tokenBucket),
+		rate:        cfg.RequestsPerSecond,
+		burst:       float64(cfg.Burst),
+		idleTimeout: idleTimeout,
+		lastSweep:   time.Now(),
+		now:         time.Now,
+	}
+}
+
+func (l *rateLimiter) allow(key string) rateLimitResult {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.burst, lastSeen: now}
+		l.buckets[key] = bucket
+	}
+
+	bucket.tokens = math.Min(l.burst, bucket.tokens+now.Sub(bucket.lastSeen).Seconds()*l.rate)
+	bucket.lastSeen = now
+
+	result := rateLimitResult{}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		result.allowed = true
+	} else {
+		result.retryAfter = l.durationFor(1 - bucket.tokens)
+	}
+
+	result.remaining = int(bucket.tokens)
+	result.reset = l.durationFor(l.burst - bucket.tokens)
+	return result
+}
+
+// sweep drops idle buckets so the map does not grow with every client ever seen.
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.idleTimeout {
+		return
+	}
+
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.lastSeen) >= l.idleTimeout {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+func (l *rateLimiter) durationFor(tokens float64) time.Duration {
+	if tokens <= 0 || l.rate <= 0 {
+		return 0
+	}
+	return time.Duration(tokens / l.rate * float64(time.Second))
+}
+
+// rateLimit wraps next with the token bucket limiter, keyed by client IP and by session
+// token when one is sent. Requests over the limit are rejected with 429 Too Many Requests
+// and a Retry-After header.
+func (r *router) rateLimit(next http.Handler) http.Handler {
+	cfg := r.cfg.RateLimit
+	if !cfg.Enabled || cfg.RequestsPerSecond <= 0 || cfg.Burst <= 0 {
+		r.logger.Infof("Rate limiting disabled")
+		return next
+	}
+
+	limiter := newRateLimiter(cfg)
+	r.logger.Infof("Rate limiting enabled: %.2f requests/second, burst %d", cfg.RequestsPerSecond, cfg.Burst)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/health" {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		key := "ip:" + clientIP(req, cfg.TrustProxyHeaders)
+		result := limiter.allow(key)
+
+		// Sessions get a bucket of their own as well, so a single session is throttled
+		// even when it spreads its requests over many addresses.
+		if token := sessionToken(req, r.cfg.Auth.CookieName); token != "" && result.allowed {
+			key = "token:" + hashToken(token)
+			if tokenResult := limiter.allow(key); !tokenResult.allowed || tokenResult.remaining < result.remaining {
+				result = tokenResult
+			}
+		}
+
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(cfg.Burst))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.remaining))
+		w.Header().Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.reset)))
+
+		if !result.allowed {
+			retryAfter := ceilSeconds(result.retryAfter)
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			r.logger.Warnf("Rate limit exceeded for %s on %s %s", key, req.Method, req.URL.Path)
+			r.handler.sendErrorResponse(w, req, http.StatusTooManyRequests,
+				"Rate limit exceeded",
+				fmt.Sprintf("Too many requests, retry after %d seconds", retryAfter),
+				"RATE_LIMITED")
+			return
+		}
+
+		next.ServeHTTP(w, req)
+	})
+}
+
+// clientIP returns the address of the calling client. Forwarding headers are only
+// honoured when the API runs behind a trusted proxy, otherwise they could be spoofed.
+func clientIP(req *http.Request, trustProxyHeaders bool) string {
+	if trustProxyHeaders {
+		if forwarded := req.Header.Get("X-Forwarded-For"); forwarded != "" {
+			first, _, _ := strings.Cut(forwarded, ",")
+			return strings.TrimSpace(first)
+		}
+		if realIP := req.Header.Get("X-Real-IP"); realIP != "" {
+			return strings.TrimSpace(realIP)
+		}
+	}
+
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
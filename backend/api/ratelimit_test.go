@@ -0,0 +1,137 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+	"timetracker/internal/config"
+	"timetracker/logger"
+)
+
+// testClock is the injectable now of a rateLimiter.
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestRateLimiter(rate float64, burst int) (*rateLimiter, *testClock) {
+	clock := &testClock{now: time.Date(2025, 10, 9, 9, 0, 0, 0, time.UTC)}
+	limiter := newRateLimiter(config.RateLimitConfig{RequestsPerSecond: rate, Burst: burst, IdleTimeoutSeconds: 60})
+	limiter.now = func() time.Time { return clock.now }
+	limiter.lastSweep = clock.now
+	return limiter, clock
+}
+
+func TestRateLimiterRefill(t *testing.T) {
+	tests := []struct {
+		name      string
+		wait      time.Duration
+		allowed   bool
+		remaining int
+		retry     time.Duration
+	}{
+		// Every step takes one token from a bucket of burst 3 that gains 2 tokens per second.
+		{"first", 0, true, 2, 0},
+		{"second", 0, true, 1, 0},
+		{"third", 0, true, 0, 0},
+		{"exhausted", 0, false, 0, 500 * time.Millisecond},
+		{"half a token", 250 * time.Millisecond, false, 0, 250 * time.Millisecond},
+		{"refilled one", 250 * time.Millisecond, true, 0, 0},
+		{"refilled to burst only", time.Hour, true, 2, 0},
+	}
+
+	limiter, clock := newTestRateLimiter(2, 3)
+	for _, tt := range tests {
+		clock.advance(tt.wait)
+		result := limiter.allow("ip:192.0.2.1")
+		if result.allowed != tt.allowed || result.remaining != tt.remaining || result.retryAfter != tt.retry {
+			t.Errorf("%s: allowed %v, remaining %d, retry after %s, want %v, %d, %s",
+				tt.name, result.allowed, result.remaining, result.retryAfter, tt.allowed, tt.remaining, tt.retry)
+		}
+	}
+}
+
+func TestRateLimiterEvictsIdleBuckets(t *testing.T) {
+	limiter, clock := newTestRateLimiter(1, 1)
+
+	limiter.allow("ip:192.0.2.1")
+	clock.advance(30 * time.Second)
+	limiter.allow("ip:192.0.2.2")
+
+	// The sweep runs once per idle timeout and only drops buckets idle for that long.
+	clock.advance(30 * time.Second)
+	limiter.allow("ip:192.0.2.3")
+	if _, ok := limiter.buckets["ip:192.0.2.1"]; ok {
+		t.Error("Bucket idle for the idle timeout was kept")
+	}
+	if _, ok := limiter.buckets["ip:192.0.2.2"]; !ok {
+		t.Error("Bucket used within the idle timeout was dropped")
+	}
+
+	clock.advance(59 * time.Second)
+	limiter.allow("ip:192.0.2.3")
+	if _, ok := limiter.buckets["ip:192.0.2.2"]; !ok {
+		t.Error("Buckets were swept again before the idle timeout passed")
+	}
+	clock.advance(time.Second)
+	limiter.allow("ip:192.0.2.3")
+	if len(limiter.buckets) != 1 {
+		t.Errorf("%d buckets left after the sweep, want 1", len(limiter.buckets))
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	log := logger.NewLogger("ratelimit", filepath.Join(t.TempDir(), "api.log"))
+	// Burst 2 and practically no refill while the test runs.
+	cfg := &config.Config{RateLimit: config.RateLimitConfig{Enabled: true, RequestsPerSecond: 0.001, Burst: 2}}
+	r := Router(log, Handler(Service(Repository(nil, log), cfg), log, cfg), cfg)
+	handler := r.rateLimit(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+	tests := []struct {
+		name   string
+		path   string
+		ip     string
+		token  string
+		status int
+	}{
+		{"first of token A", "/v1/trackers", "192.0.2.1", "token-a", http.StatusOK},
+		{"second of token A", "/v1/trackers", "192.0.2.1", "token-a", http.StatusOK},
+		{"token A from another address", "/v1/trackers", "192.0.2.2", "token-a", http.StatusTooManyRequests},
+		{"other address without token", "/v1/trackers", "192.0.2.2", "", http.StatusOK},
+		{"token B from the exhausted address", "/v1/trackers", "192.0.2.1", "token-b", http.StatusTooManyRequests},
+		{"health is exempt", "/health", "192.0.2.1", "token-a", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.RemoteAddr = tt.ip + ":40000"
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != tt.status {
+			t.Errorf("%s: answered %d, want %d", tt.name, w.Code, tt.status)
+			continue
+		}
+		if tt.status == http.StatusTooManyRequests {
+			// One token takes 1000 seconds at 0.001 per second.
+			if retryAfter := w.Header().Get("Retry-After"); retryAfter != "1000" {
+				t.Errorf("%s: Retry-After %q, want 1000", tt.name, retryAfter)
+			}
+			if w.Header().Get("X-RateLimit-Remaining") != "0" {
+				t.Errorf("%s: X-RateLimit-Remaining %q, want 0", tt.name, w.Header().Get("X-RateLimit-Remaining"))
+			}
+		}
+	}
+}
@@ -6,6 +6,7 @@
 package api
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
@@ -13,27 +14,100 @@ import (
 	"timetracker/api/model"
 	"timetracker/errorutil"
 	"timetracker/logger"
+
+	"github.com/lib/pq"
 )
 
+// errVersionMismatch is returned by conditional writes when the tracker was changed since the
+// version the client last read.
+var errVersionMismatch = errorutil.New("tracker version mismatch")
+
+// dbtx is what queries run on, either the connection pool or an open transaction.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
 type repository struct {
-	db     *sql.DB
-	logger *logger.Logger
+	conn    *sql.DB
+	db      dbtx
+	logger  *logger.Logger
+	metrics *metrics
 }
 
 func Repository(db *sql.DB, logger *logger.Logger) *repository {
-	return &repository{
-		db:     db,
-		logger: logger,
+	m := newMetrics()
+	r := &repository{
+		conn:    db,
+		db:      timedDB{dbtx: db, metrics: m},
+		logger:  logger,
+		metrics: m,
+	}
+	if db != nil {
+		m.registerDatabase(db, r)
+	}
+	return r
+}
+
+// InTx runs fn with a repository whose queries all run in one transaction. The transaction is
+// committed when fn returns nil and rolled back otherwise. Like every statement, it is canceled
+// with ctx: when the request goes away before the commit, nothing of fn is written. Writes that
+// must outlive the request detach their context, see the idempotency middleware.
+func (r *repository) InTx(ctx context.Context, fn func(tx *repository) error) error {
+	tx, err := r.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return errorutil.Wrap(err, "Failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	if err := fn(&repository{conn: r.conn, db: timedDB{dbtx: tx, metrics: r.metrics}, logger: r.logger, metrics: r.metrics}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errorutil.Wrap(err, "Failed to commit transaction")
 	}
+	return nil
+}
+
+// Savepoint runs fn inside a savepoint of the current transaction, so an error of fn only undoes
+// the writes of fn. It must be called on a repository passed to an InTx callback.
+func (r *repository) Savepoint(ctx context.Context, name string, fn func() error) error {
+	if _, err := r.db.ExecContext(ctx, `SAVEPOINT `+name); err != nil {
+		return errorutil.Wrap(err, "Failed to create savepoint")
+	}
+
+	if err := fn(); err != nil {
+		if _, rollbackErr := r.db.ExecContext(ctx, `ROLLBACK TO SAVEPOINT `+name); rollbackErr != nil {
+			return errorutil.Wrap(rollbackErr, "Failed to roll back to savepoint")
+		}
+		return err
+	}
+
+	if _, err := r.db.ExecContext(ctx, `RELEASE SAVEPOINT `+name); err != nil {
+		return errorutil.Wrap(err, "Failed to release savepoint")
+	}
+	return nil
+}
+
+const trackerColumns = `id, task, project, start_time, end_time, created_at, updated_at, version, user_id, client_id`
+
+func scanTracker(row interface{ Scan(...any) error }, tracker *model.Tracker) error {
+	return row.Scan(&tracker.ID, &tracker.Task, &tracker.Project, &tracker.StartTime, &tracker.EndTime,
+		&tracker.CreatedAt, &tracker.UpdatedAt, &tracker.Version, &tracker.UserID, &tracker.ClientID)
 }
 
-func (r *repository) GetAllTrackers() ([]model.Tracker, error) {
+func (r *repository) GetAllTrackers(ctx context.Context) ([]model.Tracker, error) {
+	ctx, span := startChildSpan(ctx, "repository.GetAllTrackers")
+	defer span.End()
+
 	query := `
-		SELECT id, task, start_time, end_time, created_at, updated_at 
+		SELECT ` + trackerColumns + `
 		FROM tracker 
 		ORDER BY created_at DESC`
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, errorutil.Wrap(err, "Failed to execute query")
 	}
@@ -44,7 +118,7 @@ func (r *repository) GetAllTrackers() ([]model.Tracker, error) {
 
 	for rows.Next() {
 		var t model.Tracker
-		if err := rows.Scan(&t.ID, &t.Task, &t.StartTime, &t.EndTime, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		if err := scanTracker(rows, &t); err != nil {
 			return nil, errorutil.Wrap(err, "scanning tracker row")
 		}
 		trackers = append(trackers, t)
@@ -54,16 +128,137 @@ func (r *repository) GetAllTrackers() ([]model.Tracker, error) {
 	return trackers, nil
 }
 
-func (r *repository) CreateTracker(req model.CreateTrackerRequest) (*model.Tracker, error) {
+// trackerFilterConditions turns the criteria of filter into a WHERE clause and its arguments,
+// its limit and offset are left to the caller.
+func trackerFilterConditions(filter model.TrackerFilter) (string, []interface{}) {
+	conditions := []string{"TRUE"}
+	args := []interface{}{}
+
+	if filter.Project != nil {
+		args = append(args, *filter.Project)
+		conditions = append(conditions, fmt.Sprintf("project = $%d", len(args)))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		conditions = append(conditions, fmt.Sprintf("start_time >= $%d", len(args)))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		conditions = append(conditions, fmt.Sprintf("start_time < $%d", len(args)))
+	}
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		conditions = append(conditions, fmt.Sprintf("user_id = $%d", len(args)))
+	}
+	if filter.Running {
+		conditions = append(conditions, "end_time IS NULL")
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// FindTrackers returns the trackers matching filter, oldest first. From is inclusive and To
+// exclusive, both are compared with the start time. Ties are ordered by id, so pages are stable.
+func (r *repository) FindTrackers(ctx context.Context, filter model.TrackerFilter) ([]model.Tracker, error) {
+	ctx, span := startChildSpan(ctx, "repository.FindTrackers")
+	defer span.End()
+
+	where, args := trackerFilterConditions(filter)
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM tracker
+		WHERE %s
+		ORDER BY start_time ASC, id ASC`,
+		trackerColumns, where)
+
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit, filter.Offset)
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errorutil.Wrap(err, "Failed to execute query")
+	}
+
+	defer rows.Close()
+
+	trackers := []model.Tracker{}
+
+	for rows.Next() {
+		var t model.Tracker
+		if err := scanTracker(rows, &t); err != nil {
+			return nil, errorutil.Wrap(err, "scanning tracker row")
+		}
+		trackers = append(trackers, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errorutil.Wrap(err, "iterating tracker rows")
+	}
+
+	r.logger.Infof("Found %d trackers matching filter", len(trackers))
+	return trackers, nil
+}
+
+// SummarizeTrackers sums the durations of the trackers matching filter in whole seconds, in total
+// and per project. Running trackers count up to now. Projects are ordered by total duration,
+// longest first; limit and offset of filter are ignored.
+func (r *repository) SummarizeTrackers(ctx context.Context, filter model.TrackerFilter) (*model.TrackerSummary, error) {
+	ctx, span := startChildSpan(ctx, "repository.SummarizeTrackers")
+	defer span.End()
+
+	where, args := trackerFilterConditions(filter)
+
+	// Trackers without project form a group of their own, apart from a project named "".
+	query := fmt.Sprintf(`
+		SELECT project, COUNT(*),
+			COALESCE(SUM(GREATEST(0, FLOOR(EXTRACT(EPOCH FROM COALESCE(end_time, NOW()) - start_time)))), 0)::BIGINT AS total_seconds,
+			COUNT(*) FILTER (WHERE end_time IS NULL)
+		FROM tracker
+		WHERE %s
+		GROUP BY project
+		ORDER BY total_seconds DESC, MIN(start_time) ASC`,
+		where)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errorutil.Wrap(err, "Failed to execute query")
+	}
+
+	defer rows.Close()
+
+	summary := &model.TrackerSummary{Projects: []model.ProjectSummary{}}
+
+	for rows.Next() {
+		var p model.ProjectSummary
+		var running int
+		if err := rows.Scan(&p.Project, &p.Count, &p.TotalSeconds, &running); err != nil {
+			return nil, errorutil.Wrap(err, "scanning project summary row")
+		}
+		summary.Count += p.Count
+		summary.Running += running
+		summary.TotalSeconds += p.TotalSeconds
+		summary.Projects = append(summary.Projects, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errorutil.Wrap(err, "iterating project summary rows")
+	}
+
+	return summary, nil
+}
+
+func (r *repository) CreateTracker(ctx context.Context, req model.CreateTrackerRequest) (*model.Tracker, error) {
+	ctx, span := startChildSpan(ctx, "repository.CreateTracker")
+	defer span.End()
+
 	query := `
-		INSERT INTO tracker (task, start_time, end_time) 
-		VALUES ($1, $2, $3) 
-		RETURNING id, task, start_time, end_time, created_at, updated_at`
+		INSERT INTO tracker (task, project, start_time, end_time, user_id, client_id) 
+		VALUES ($1, $2, $3, $4, $5, $6) 
+		RETURNING ` + trackerColumns
 
 	var tracker model.Tracker
-	err := r.db.QueryRow(query, req.Task, req.StartTime, req.EndTime).Scan(
-		&tracker.ID, &tracker.Task, &tracker.StartTime, &tracker.EndTime,
-		&tracker.CreatedAt, &tracker.UpdatedAt)
+	err := scanTracker(r.db.QueryRowContext(ctx, query, req.Task, req.Project, req.StartTime, req.EndTime, req.UserID, req.ClientID), &tracker)
 
 	if err != nil {
 		return nil, errorutil.Wrap(err, "Failed to create tracker")
@@ -73,16 +268,17 @@ func (r *repository) CreateTracker(req model.CreateTrackerRequest) (*model.Track
 	return &tracker, nil
 }
 
-func (r *repository) GetTrackerByID(id int) (*model.Tracker, error) {
+func (r *repository) GetTrackerByID(ctx context.Context, id int) (*model.Tracker, error) {
+	ctx, span := startChildSpan(ctx, "repository.GetTrackerByID")
+	defer span.End()
+
 	query := `
-		SELECT id, task, start_time, end_time, created_at, updated_at 
+		SELECT ` + trackerColumns + `
 		FROM tracker 
 		WHERE id = $1`
 
 	var tracker model.Tracker
-	err := r.db.QueryRow(query, id).Scan(
-		&tracker.ID, &tracker.Task, &tracker.StartTime, &tracker.EndTime,
-		&tracker.CreatedAt, &tracker.UpdatedAt)
+	err := scanTracker(r.db.QueryRowContext(ctx, query, id), &tracker)
 
 	if err == sql.ErrNoRows {
 		return nil, errorutil.New("tracker not found")
@@ -95,24 +291,35 @@ func (r *repository) GetTrackerByID(id int) (*model.Tracker, error) {
 	return &tracker, nil
 }
 
-func (r *repository) UpdateTracker(id int, req model.UpdateTrackerRequest) (*model.Tracker, error) {
+// UpdateTracker applies patch and bumps the version of the tracker. Fields absent from the patch are
+// kept and null fields are cleared. When versions is not nil the update only happens if the current
+// version is one of them, otherwise errVersionMismatch is returned.
+func (r *repository) UpdateTracker(ctx context.Context, id int, patch model.TrackerPatch, versions []int) (*model.Tracker, error) {
+	ctx, span := startChildSpan(ctx, "repository.UpdateTracker")
+	defer span.End()
+
 	setParts := []string{}
 	args := []interface{}{}
 	argIndex := 1
 
-	if req.Task != nil {
+	if patch.Task.Set {
 		setParts = append(setParts, fmt.Sprintf("task = $%d", argIndex))
-		args = append(args, *req.Task)
+		args = append(args, patch.Task.Ptr())
 		argIndex++
 	}
-	if req.StartTime != nil {
+	if patch.Project.Set {
+		setParts = append(setParts, fmt.Sprintf("project = $%d", argIndex))
+		args = append(args, patch.Project.Ptr())
+		argIndex++
+	}
+	if patch.StartTime.Set {
 		setParts = append(setParts, fmt.Sprintf("start_time = $%d", argIndex))
-		args = append(args, *req.StartTime)
+		args = append(args, patch.StartTime.Ptr())
 		argIndex++
 	}
-	if req.EndTime != nil {
+	if patch.EndTime.Set {
 		setParts = append(setParts, fmt.Sprintf("end_time = $%d", argIndex))
-		args = append(args, *req.EndTime)
+		args = append(args, patch.EndTime.Ptr())
 		argIndex++
 	}
 
@@ -124,22 +331,29 @@ func (r *repository) UpdateTracker(id int, req model.UpdateTrackerRequest) (*mod
 	args = append(args, time.Now())
 	argIndex++
 
+	setParts = append(setParts, "version = version + 1")
+
 	args = append(args, id)
+	conditions := []string{fmt.Sprintf("id = $%d", argIndex)}
+	argIndex++
+
+	if versions != nil {
+		args = append(args, intArray(versions))
+		conditions = append(conditions, fmt.Sprintf("version = ANY($%d::int[])", argIndex))
+	}
 
 	query := fmt.Sprintf(`
 		UPDATE tracker 
 		SET %s 
-		WHERE id = $%d 
-		RETURNING id, task, start_time, end_time, created_at, updated_at`,
-		strings.Join(setParts, ", "), argIndex)
+		WHERE %s 
+		RETURNING %s`,
+		strings.Join(setParts, ", "), strings.Join(conditions, " AND "), trackerColumns)
 
 	var tracker model.Tracker
-	err := r.db.QueryRow(query, args...).Scan(
-		&tracker.ID, &tracker.Task, &tracker.StartTime, &tracker.EndTime,
-		&tracker.CreatedAt, &tracker.UpdatedAt)
+	err := scanTracker(r.db.QueryRowContext(ctx, query, args...), &tracker)
 
 	if err == sql.ErrNoRows {
-		return nil, errorutil.New("tracker not found")
+		return nil, r.conditionalWriteError(ctx, id, versions)
 	}
 	if err != nil {
 		return nil, errorutil.Wrap(err, "Failed to update tracker")
@@ -149,11 +363,22 @@ func (r *repository) UpdateTracker(id int, req model.UpdateTrackerRequest) (*mod
 	return &tracker, nil
 }
 
+// DeleteTracker removes the tracker. When versions is not nil the delete only happens if the
+// current version is one of them, otherwise errVersionMismatch is returned.
 // TODO: This has to improve to soft delete.
-func (r *repository) DeleteTracker(id int) error {
+func (r *repository) DeleteTracker(ctx context.Context, id int, versions []int) error {
+	ctx, span := startChildSpan(ctx, "repository.DeleteTracker")
+	defer span.End()
+
 	query := `DELETE FROM tracker WHERE id = $1`
+	args := []interface{}{id}
+
+	if versions != nil {
+		query += ` AND version = ANY($2::int[])`
+		args = append(args, intArray(versions))
+	}
 
-	result, err := r.db.Exec(query, id)
+	result, err := r.db.ExecContext(ctx, query, args...)
 	if err != nil {
 		return errorutil.Wrap(err, "Failed to delete tracker")
 	}
@@ -164,9 +389,34 @@ func (r *repository) DeleteTracker(id int) error {
 	}
 
 	if rowsAffected == 0 {
-		return errorutil.New("tracker not found")
+		return r.conditionalWriteError(ctx, id, versions)
 	}
 
 	r.logger.Infof("Deleted tracker with ID: %d", id)
 	return nil
 }
+
+// conditionalWriteError tells apart a missing tracker from a version mismatch after a
+// conditional write touched no rows.
+func (r *repository) conditionalWriteError(ctx context.Context, id int, versions []int) error {
+	if versions == nil {
+		return errorutil.New("tracker not found")
+	}
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM tracker WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return errorutil.Wrap(err, "Failed to check tracker existence")
+	}
+	if !exists {
+		return errorutil.New("tracker not found")
+	}
+	return errVersionMismatch
+}
+
+func intArray(values []int) pq.Int64Array {
+	array := make(pq.Int64Array, len(values))
+	for i, v := range values {
+		array[i] = int64(v)
+	}
+	return array
+}
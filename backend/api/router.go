@@ -8,6 +8,7 @@ package api
 
 import (
 	"net/http"
+	"timetracker/internal/config"
 	"timetracker/logger"
 )
 
@@ -15,27 +16,106 @@ type router struct {
 	mux     *http.ServeMux
 	logger  *logger.Logger
 	handler *handler
+	cfg     *config.Config
 }
 
-func Router(logger *logger.Logger, handler *handler) *router {
+// route is a pattern of a versioned API, relative to its version prefix, e.g. "GET /trackers".
+type route struct {
+	pattern string
+	handler http.HandlerFunc
+}
+
+func Router(logger *logger.Logger, handler *handler, cfg *config.Config) *router {
 	return &router{
 		mux:     http.NewServeMux(),
 		logger:  logger,
 		handler: handler,
+		cfg:     cfg,
 	}
 }
 
+// SetRoutes mounts every API version under its own prefix. A new version gets its own route table
+// next to v1Routes; routes that did not change between versions point to the same handlers, and
+// all versions share the service layer.
 func (r *router) SetRoutes() http.Handler {
-	r.mux.HandleFunc("GET /trackers", r.handler.GetAllTrackersHandler)
-	r.mux.HandleFunc("POST /trackers", r.handler.CreateTrackerHandler)
-	r.mux.HandleFunc("PUT /trackers/{id}", r.handler.UpdateTrackerHandler)
-	r.mux.HandleFunc("DELETE /trackers/{id}", r.handler.DeleteTrackerHandler)
-	r.mux.HandleFunc("GET /trackers/{id}", r.handler.FindTrackerByIDHandler)
-	r.mux.HandleFunc("/health", r.healthCheckHandler)
-	return r.mux
+	v1 := r.v1Routes()
+	r.mount("/v1", v1)
+	if r.cfg.LegacyRoutes.Enabled {
+		r.mountLegacy("/v1", v1)
+	}
+
+	r.mount("", r.unversionedRoutes())
+	return r.traceRequests(r.withRequestID(r.accessLog(r.instrument(r.recoverPanic(
+		r.cors(r.rateLimit(r.authenticate(r.idempotency(r.mux)))))))))
+}
+
+func (r *router) v1Routes() []route {
+	h := r.handler
+	return []route{
+		{"GET /trackers", h.GetAllTrackersHandler},
+		{"POST /trackers", h.CreateTrackerHandler},
+		{"POST /trackers/batch", h.BatchTrackersHandler},
+		{"GET /trackers/events", h.TrackerEventsHandler},
+		{"GET /trackers/live", h.requireAuth(h.LiveTimersHandler)},
+		{"PUT /trackers/{id}", h.UpdateTrackerHandler},
+		{"PATCH /trackers/{id}", h.PatchTrackerHandler},
+		{"DELETE /trackers/{id}", h.DeleteTrackerHandler},
+		{"GET /trackers/{id}", h.FindTrackerByIDHandler},
+		{"GET /auth/oidc/login", h.OIDCLoginHandler},
+		{"GET /auth/oidc/callback", h.OIDCCallbackHandler},
+		{"POST /auth/logout", h.requireSession(h.LogoutHandler)},
+		{"GET /auth/me", h.requireAuth(h.CurrentUserHandler)},
+		{"POST /auth/2fa/enroll", h.requireAuth(h.EnrollTOTPHandler)},
+		{"POST /auth/2fa/confirm", h.requireAuth(h.ConfirmTOTPHandler)},
+		{"POST /auth/2fa/verify", h.requireSession(h.VerifyMFAHandler)},
+		{"POST /auth/2fa/recovery-codes", h.requireAuth(h.RegenerateRecoveryCodesHandler)},
+		{"DELETE /admin/users/{id}/2fa", h.requireAdmin(h.ResetUserTOTPHandler)},
+		{"POST /share-links", h.requireAuth(h.CreateShareLinkHandler)},
+		{"GET /share-links", h.requireAuth(h.ListShareLinksHandler)},
+		{"DELETE /share-links/{id}", h.requireAuth(h.RevokeShareLinkHandler)},
+		{"GET /share/{token}", h.SharedReportHandler},
+		{"POST /graphql", h.GraphQLHandler},
+		{"GET /sync", h.requireAuth(h.GetSyncChangesHandler)},
+		{"POST /sync", h.requireAuth(h.PushSyncChangesHandler)},
+		{"POST /webhooks", h.requireAdmin(h.CreateWebhookHandler)},
+		{"GET /webhooks", h.requireAdmin(h.ListWebhooksHandler)},
+		{"GET /webhooks/{id}", h.requireAdmin(h.GetWebhookHandler)},
+		{"DELETE /webhooks/{id}", h.requireAdmin(h.DeleteWebhookHandler)},
+		{"GET /webhooks/{id}/deliveries", h.requireAdmin(h.ListWebhookDeliveriesHandler)},
+	}
+}
+
+// unversionedRoutes are served outside of the API versions.
+func (r *router) unversionedRoutes() []route {
+	routes := []route{
+		{"/health", r.healthCheckHandler},
+		{"GET /openapi.yaml", r.handler.OpenAPISpecHandler},
+		{"GET /docs", r.handler.APIDocsHandler},
+	}
+	if r.cfg.Metrics.Enabled {
+		routes = append(routes, route{"GET /metrics", r.handler.MetricsHandler})
+	}
+	return routes
+}
+
+// mount registers routes under prefix, e.g. "GET /trackers" as "GET /v1/trackers".
+func (r *router) mount(prefix string, routes []route) {
+	for _, rt := range routes {
+		pattern := prefixPattern(prefix, rt.pattern)
+		r.mux.HandleFunc(pattern, r.traceHandler(pattern, rt.handler))
+	}
+}
+
+// mountLegacy registers routes without prefix as deprecated aliases of the routes under prefix.
+func (r *router) mountLegacy(prefix string, routes []route) {
+	deprecation := r.deprecationHeaders()
+	for _, rt := range routes {
+		r.mux.HandleFunc(rt.pattern, r.deprecated(deprecation, prefix, r.traceHandler(rt.pattern, rt.handler)))
+	}
 }
 
 func (r *router) healthCheckHandler(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("OK"))
 }
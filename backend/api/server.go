@@ -0,0 +1,70 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+	"timetracker/internal/config"
+)
+
+// shutdownKey carries the channel that is closed once the server starts shutting down.
+type shutdownKey struct{}
+
+// HTTPServer returns the server for handler listening on the configured AppPort, with the timeouts
+// and header limit of the server configuration. Shutting it down also ends the event streams and
+// live sockets, which would otherwise keep Shutdown waiting until its deadline, while other
+// requests in flight are left to finish.
+func HTTPServer(cfg *config.Config, handler http.Handler) *http.Server {
+	port := cfg.AppPort
+	if port == "" {
+		port = "8080"
+	}
+	maxHeaderBytes := cfg.Server.MaxHeaderBytes
+	if maxHeaderBytes <= 0 {
+		maxHeaderBytes = http.DefaultMaxHeaderBytes
+	}
+
+	closing := make(chan struct{})
+	base := context.WithValue(context.Background(), shutdownKey{}, (<-chan struct{})(closing))
+
+	server := &http.Server{
+		Addr:              ":" + port,
+		Handler:           handler,
+		ReadTimeout:       serverTimeout(cfg.Server.ReadTimeoutSeconds, 15*time.Second),
+		ReadHeaderTimeout: serverTimeout(cfg.Server.ReadHeaderTimeoutSeconds, 5*time.Second),
+		WriteTimeout:      serverTimeout(cfg.Server.WriteTimeoutSeconds, 30*time.Second),
+		IdleTimeout:       serverTimeout(cfg.Server.IdleTimeoutSeconds, 120*time.Second),
+		MaxHeaderBytes:    maxHeaderBytes,
+		BaseContext:       func(net.Listener) context.Context { return base },
+	}
+	var once sync.Once
+	server.RegisterOnShutdown(func() { once.Do(func() { close(closing) }) })
+	return server
+}
+
+// ShutdownTimeout is how long in-flight requests may take to finish once the server shuts down.
+func ShutdownTimeout(cfg *config.Config) time.Duration {
+	return serverTimeout(cfg.Server.ShutdownTimeoutSeconds, 20*time.Second)
+}
+
+func serverTimeout(seconds int, fallback time.Duration) time.Duration {
+	if seconds <= 0 {
+		return fallback
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// serverClosing returns a channel that is closed once the server serving ctx starts shutting down.
+// Outside of HTTPServer, as in tests, it is nil and never ready.
+func serverClosing(ctx context.Context) <-chan struct{} {
+	closing, _ := ctx.Value(shutdownKey{}).(<-chan struct{})
+	return closing
+}
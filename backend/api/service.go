@@ -6,31 +6,187 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
 	"timetracker/api/model"
+	"timetracker/errorutil"
+	"timetracker/internal/config"
+	"timetracker/internal/oidc"
 )
 
+var errInvalidPatch = errorutil.New("invalid patch")
+
+// errPatchConflict is returned by a patch without If-Match when the tracker kept changing while
+// the patch was merged into it.
+var errPatchConflict = errorutil.New("tracker changed during patch")
+
+// maxPatchAttempts bounds how often a patch without If-Match is merged into a fresh read.
+const maxPatchAttempts = 3
+
 type service struct {
-	repo *repository
+	repo          *repository
+	cfg           *config.Config
+	oidc          *oidc.Client
+	events        *trackerEventHub
+	webhookClient *http.Client
 }
 
-func Service(repo *repository) *service {
-	return &service{
-		repo: repo,
+func Service(repo *repository, cfg *config.Config) *service {
+	s := &service{
+		repo:          repo,
+		cfg:           cfg,
+		events:        newTrackerEventHub(),
+		webhookClient: newWebhookClient(cfg.Webhooks.TimeoutSeconds),
 	}
+
+	if cfg.OIDC.Enabled {
+		s.oidc = oidc.New(oidc.Config{
+			IssuerURL:    cfg.OIDC.IssuerURL,
+			ClientID:     cfg.OIDC.ClientID,
+			ClientSecret: cfg.OIDC.ClientSecret,
+			RedirectURL:  cfg.OIDC.RedirectURL,
+			Scopes:       cfg.OIDC.Scopes,
+		}, nil)
+	}
+
+	return s
+}
+
+func (s *service) GetAllTrackersService(ctx context.Context) ([]model.Tracker, error) {
+	ctx, span := tracer.Start(ctx, "service.GetAllTrackersService")
+	defer span.End()
+
+	return s.repo.GetAllTrackers(ctx)
+}
+func (s *service) CreateTrackerService(ctx context.Context, req model.CreateTrackerRequest) (*model.Tracker, error) {
+	ctx, span := tracer.Start(ctx, "service.CreateTrackerService")
+	defer span.End()
+
+	return s.repo.CreateTracker(ctx, req)
 }
 
-func (s *service) GetAllTrackersService() ([]model.Tracker, error) {
-	return s.repo.GetAllTrackers()
+// UpdateTrackerService replaces all fields of the tracker, optional fields missing from req are cleared.
+func (s *service) UpdateTrackerService(ctx context.Context, id int, req model.UpdateTrackerRequest, versions []int) (*model.Tracker, error) {
+	ctx, span := tracer.Start(ctx, "service.UpdateTrackerService")
+	defer span.End()
+
+	return replaceTracker(ctx, s.repo, id, req, versions)
 }
-func (s *service) CreateTrackerService(req model.CreateTrackerRequest) (*model.Tracker, error) {
-	return s.repo.CreateTracker(req)
+
+// PatchTrackerService merges patch into the tracker. The merged result is validated as a whole,
+// since a patch of a single time has to stay consistent with the stored other one.
+func (s *service) PatchTrackerService(ctx context.Context, id int, patch model.TrackerPatch, versions []int) (*model.Tracker, error) {
+	ctx, span := tracer.Start(ctx, "service.PatchTrackerService")
+	defer span.End()
+
+	return patchTracker(ctx, s.repo, id, patch, versions)
 }
-func (s *service) UpdateTrackerService(id int, req model.UpdateTrackerRequest) (*model.Tracker, error) {
-	return s.repo.UpdateTracker(id, req)
+
+func replaceTracker(ctx context.Context, repo *repository, id int, req model.UpdateTrackerRequest, versions []int) (*model.Tracker, error) {
+	patch := model.TrackerPatch{
+		Task:      model.NullableValue(req.Task),
+		Project:   model.NullableFromPtr(req.Project),
+		StartTime: model.NullableValue(req.StartTime),
+		EndTime:   model.NullableFromPtr(req.EndTime),
+	}
+	return repo.UpdateTracker(ctx, id, patch, versions)
 }
-func (s *service) DeleteTrackerService(id int) error {
-	return s.repo.DeleteTracker(id)
+
+// trackerStore is the part of the repository patchTracker needs, either the connection pool or
+// the transaction of a batch.
+type trackerStore interface {
+	GetTrackerByID(ctx context.Context, id int) (*model.Tracker, error)
+	UpdateTracker(ctx context.Context, id int, patch model.TrackerPatch, versions []int) (*model.Tracker, error)
+}
+
+// patchTracker validates patch against the stored tracker and writes it. The write is always
+// conditional on the version that was validated: without If-Match a concurrent change makes it
+// read and validate again, until maxPatchAttempts is reached.
+func patchTracker(ctx context.Context, repo trackerStore, id int, patch model.TrackerPatch, versions []int) (*model.Tracker, error) {
+	for attempt := 1; ; attempt++ {
+		current, err := repo.GetTrackerByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		merged := patch.Apply(*current)
+		if merged.EndTime != nil && merged.EndTime.Before(merged.StartTime) {
+			return nil, fmt.Errorf("%w: end_time cannot be before start_time", errInvalidPatch)
+		}
+
+		expected := versions
+		if expected == nil {
+			expected = []int{current.Version}
+		}
+		tracker, err := repo.UpdateTracker(ctx, id, patch, expected)
+		if versions != nil || !errors.Is(err, errVersionMismatch) {
+			return tracker, err
+		}
+		if attempt == maxPatchAttempts {
+			return nil, fmt.Errorf("%w: %d attempts", errPatchConflict, attempt)
+		}
+	}
 }
-func (s *service) GetTrackerByIDService(id int) (*model.Tracker, error) {
-	return s.repo.GetTrackerByID(id)
+
+func (s *service) DeleteTrackerService(ctx context.Context, id int, versions []int) error {
+	ctx, span := tracer.Start(ctx, "service.DeleteTrackerService")
+	defer span.End()
+
+	return s.repo.DeleteTracker(ctx, id, versions)
+}
+func (s *service) GetTrackerByIDService(ctx context.Context, id int) (*model.Tracker, error) {
+	ctx, span := tracer.Start(ctx, "service.GetTrackerByIDService")
+	defer span.End()
+
+	return s.repo.GetTrackerByID(ctx, id)
+}
+
+func (s *service) FindTrackersService(ctx context.Context, filter model.TrackerFilter) ([]model.Tracker, error) {
+	ctx, span := tracer.Start(ctx, "service.FindTrackersService")
+	defer span.End()
+
+	return s.repo.FindTrackers(ctx, filter)
+}
+
+// SummarizeTrackersService aggregates all trackers matching filter in the database, its limit and
+// offset are ignored. Projects are ordered by total duration, longest first.
+func (s *service) SummarizeTrackersService(ctx context.Context, filter model.TrackerFilter) (*model.TrackerSummary, error) {
+	ctx, span := tracer.Start(ctx, "service.SummarizeTrackersService")
+	defer span.End()
+
+	summary, err := s.repo.SummarizeTrackers(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	summary.TotalHours = secondsToHours(summary.TotalSeconds)
+	for i := range summary.Projects {
+		summary.Projects[i].TotalHours = secondsToHours(summary.Projects[i].TotalSeconds)
+	}
+	return summary, nil
+}
+
+func (s *service) GetUsersByIDsService(ctx context.Context, ids []int) (map[int]*model.User, error) {
+	ctx, span := tracer.Start(ctx, "service.GetUsersByIDsService")
+	defer span.End()
+
+	return s.repo.GetUsersByIDs(ctx, ids)
+}
+
+// trackerDuration is the tracked time in whole seconds. Timers that are still running count up to now.
+func trackerDuration(t model.Tracker, now time.Time) int64 {
+	end := now
+	if t.EndTime != nil {
+		end = *t.EndTime
+	}
+	return int64(math.Max(0, end.Sub(t.StartTime).Seconds()))
+}
+
+// secondsToHours converts a duration to hours rounded to two decimals.
+func secondsToHours(seconds int64) float64 {
+	return math.Round(float64(seconds)/36) / 100
 }
@@ -0,0 +1,91 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+	"timetracker/api/model"
+)
+
+// fakeTrackerStore holds one tracker in memory. Every read is followed by concurrentWrites
+// writes of another client, which bump the version before the patch gets to write.
+type fakeTrackerStore struct {
+	tracker          model.Tracker
+	concurrentWrites int
+	reads            int
+	updates          [][]int
+}
+
+func (f *fakeTrackerStore) GetTrackerByID(ctx context.Context, id int) (*model.Tracker, error) {
+	f.reads++
+	current := f.tracker
+	if f.concurrentWrites > 0 {
+		f.concurrentWrites--
+		f.tracker.Version++
+	}
+	return &current, nil
+}
+
+func (f *fakeTrackerStore) UpdateTracker(ctx context.Context, id int, patch model.TrackerPatch, versions []int) (*model.Tracker, error) {
+	f.updates = append(f.updates, versions)
+	if versions != nil && !slices.Contains(versions, f.tracker.Version) {
+		return nil, errVersionMismatch
+	}
+	f.tracker = patch.Apply(f.tracker)
+	f.tracker.Version++
+	updated := f.tracker
+	return &updated, nil
+}
+
+func TestPatchTrackerRetries(t *testing.T) {
+	start := time.Date(2025, 10, 9, 9, 0, 0, 0, time.UTC)
+	patch := model.TrackerPatch{Task: model.NullableValue("Write")}
+
+	tests := []struct {
+		name             string
+		concurrentWrites int
+		versions         []int
+		wantErr          error
+		wantUpdates      [][]int
+	}{
+		{"without If-Match writes the read version", 0, nil, nil, [][]int{{1}}},
+		{"concurrent write is retried", 1, nil, nil, [][]int{{1}, {2}}},
+		{"last attempt succeeds", maxPatchAttempts - 1, nil, nil, [][]int{{1}, {2}, {3}}},
+		{"gives up after maxPatchAttempts", maxPatchAttempts, nil, errPatchConflict, [][]int{{1}, {2}, {3}}},
+		{"If-Match is not retried", 1, []int{1}, errVersionMismatch, [][]int{{1}}},
+	}
+
+	for _, tt := range tests {
+		store := &fakeTrackerStore{
+			tracker:          model.Tracker{ID: 42, Task: "Review", StartTime: start, Version: 1},
+			concurrentWrites: tt.concurrentWrites,
+		}
+		tracker, err := patchTracker(context.Background(), store, 42, patch, tt.versions)
+
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: error %v, want %v", tt.name, err, tt.wantErr)
+		}
+		if err == nil && tracker.Task != "Write" {
+			t.Errorf("%s: task %q was not patched", tt.name, tracker.Task)
+		}
+		if !slices.EqualFunc(store.updates, tt.wantUpdates, slices.Equal) {
+			t.Errorf("%s: updates with versions %v, want %v", tt.name, store.updates, tt.wantUpdates)
+		}
+	}
+}
+
+func TestPatchTrackerConflictStatus(t *testing.T) {
+	status, opErr := trackerOperationError(42, errPatchConflict)
+	if status != http.StatusConflict || opErr.Code != "CONFLICT" {
+		t.Errorf("errPatchConflict maps to %d %s, want 409 CONFLICT", status, opErr.Code)
+	}
+}
@@ -0,0 +1,183 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"timetracker/api/model"
+)
+
+// CreateShareLinkHandler mints a signed, expiring link to a read-only report.
+// It expects a JSON payload containing:
+//   - project: string (optional, restricts the report to one project)
+//   - from: timestamp (optional, inclusive lower bound of the start time)
+//   - to: timestamp (optional, exclusive upper bound of the start time, must be after from)
+//   - expires_in_hours: integer (optional, defaults to the configured TTL and is capped at the maximum)
+//
+// Returns:
+//   - 201 Created: Share link including the public URL
+//   - 400 Bad Request: Invalid JSON payload or validation errors
+//   - 401 Unauthorized: No valid session
+//   - 503 Service Unavailable: No signing secret is configured
+//   - 500 Internal Server Error: Database or server errors
+func (h *handler) CreateShareLinkHandler(w http.ResponseWriter, req *http.Request) {
+	h.log(req).Infof("CreateShareLinkHandler: Processing request from %s", req.RemoteAddr)
+
+	user, _ := userFromContext(req.Context())
+
+	var request model.CreateShareLinkRequest
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		h.log(req).Warnf("CreateShareLinkHandler: Failed to decode JSON - %v", err)
+		h.sendErrorResponse(w, req, http.StatusBadRequest,
+			"Invalid JSON payload",
+			"Request body must be valid JSON matching CreateShareLinkRequest schema",
+			"INVALID_JSON")
+		return
+	}
+
+	if validationErrors := validateStruct(&request); len(validationErrors) > 0 {
+		h.log(req).Warnf("CreateShareLinkHandler: Validation failed - %s", fieldErrorMessages(validationErrors))
+		h.sendValidationErrorResponse(w, req, validationErrors)
+		return
+	}
+
+	link, err := h.service.CreateShareLinkService(req.Context(), user, request)
+	if err != nil {
+		h.log(req).Errorf("CreateShareLinkHandler: Service error - %v", err)
+		if errors.Is(err, errShareDisabled) {
+			h.sendErrorResponse(w, req, http.StatusServiceUnavailable,
+				"Share links disabled",
+				"No signing secret is configured for share links",
+				"SHARE_DISABLED")
+			return
+		}
+
+		h.sendErrorResponse(w, req, http.StatusInternalServerError,
+			"Failed to create share link",
+			"An error occurred while saving the share link to database",
+			"CREATE_ERROR")
+		return
+	}
+
+	h.log(req).Infof("CreateShareLinkHandler: Successfully created share link with ID: %d", link.ID)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(link)
+}
+
+// ListShareLinksHandler returns the share links created by the signed in user, newest first.
+// Links that are still active include their public URL.
+//
+// Returns:
+//   - 200 OK: Array of share links (may be empty)
+//   - 401 Unauthorized: No valid session
+//   - 500 Internal Server Error: Database or server errors
+func (h *handler) ListShareLinksHandler(w http.ResponseWriter, req *http.Request) {
+	h.log(req).Infof("ListShareLinksHandler: Processing request from %s", req.RemoteAddr)
+
+	user, _ := userFromContext(req.Context())
+
+	links, err := h.service.ListShareLinksService(req.Context(), user)
+	if err != nil {
+		h.log(req).Errorf("ListShareLinksHandler: Service error - %v", err)
+		h.sendErrorResponse(w, req, http.StatusInternalServerError,
+			"Failed to fetch share links",
+			"An error occurred while retrieving share links from database",
+			"FETCH_ERROR")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(links)
+}
+
+// RevokeShareLinkHandler revokes a share link by ID. The public URL stops working immediately.
+// Users may revoke their own links, admins may revoke any link.
+//
+// Returns:
+//   - 204 No Content: Successfully revoked the link
+//   - 400 Bad Request: Invalid ID parameter
+//   - 401 Unauthorized: No valid session
+//   - 404 Not Found: No active link with the ID exists for this user
+//   - 500 Internal Server Error: Database or server errors
+func (h *handler) RevokeShareLinkHandler(w http.ResponseWriter, req *http.Request) {
+	h.log(req).Infof("RevokeShareLinkHandler: Processing request from %s", req.RemoteAddr)
+
+	user, _ := userFromContext(req.Context())
+
+	id, err := h.extractIDFromPath(req)
+	if err != nil {
+		h.log(req).Warnf("RevokeShareLinkHandler: Invalid ID parameter - %v", err)
+		h.sendErrorResponse(w, req, http.StatusBadRequest,
+			"Invalid ID parameter",
+			err.Error(),
+			"INVALID_ID")
+		return
+	}
+
+	if err := h.service.RevokeShareLinkService(req.Context(), user, id); err != nil {
+		h.log(req).Errorf("RevokeShareLinkHandler: Service error for ID %d - %v", id, err)
+		if strings.Contains(err.Error(), "not found") {
+			h.sendErrorResponse(w, req, http.StatusNotFound,
+				"Share link not found",
+				fmt.Sprintf("No active share link exists with ID %d", id),
+				"NOT_FOUND")
+			return
+		}
+
+		h.sendErrorResponse(w, req, http.StatusInternalServerError,
+			"Failed to revoke share link",
+			"An error occurred while revoking the share link",
+			"REVOKE_ERROR")
+		return
+	}
+
+	h.log(req).Infof("RevokeShareLinkHandler: Successfully revoked share link ID: %d", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SharedReportHandler serves the read-only report behind a share link. It needs no session:
+// the token in the path is checked against its HMAC signature and expiry before the database
+// is queried, and the link must not have been revoked.
+//
+// Returns:
+//   - 200 OK: Report with totals and the matching entries
+//   - 404 Not Found: Invalid, expired or revoked link
+//   - 500 Internal Server Error: Database or server errors
+func (h *handler) SharedReportHandler(w http.ResponseWriter, req *http.Request) {
+	h.log(req).Infof("SharedReportHandler: Processing request from %s", req.RemoteAddr)
+
+	report, err := h.service.GetSharedReportService(req.Context(), req.PathValue("token"))
+	if err != nil {
+		h.log(req).Warnf("SharedReportHandler: Service error - %v", err)
+		// Disabled, forged, expired and revoked links look the same from outside.
+		if errors.Is(err, errInvalidShareToken) || errors.Is(err, errShareDisabled) {
+			h.sendErrorResponse(w, req, http.StatusNotFound,
+				"Report not found",
+				"This link is invalid or has expired",
+				"NOT_FOUND")
+			return
+		}
+
+		h.sendErrorResponse(w, req, http.StatusInternalServerError,
+			"Failed to build report",
+			"An error occurred while retrieving the report from database",
+			"FETCH_ERROR")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Robots-Tag", "noindex")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,128 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+package api
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"timetracker/api/model"
+	"timetracker/errorutil"
+)
+
+const shareLinkColumns = `id, created_by, project, from_time, to_time, expires_at, revoked_at, created_at`
+
+func scanShareLink(row interface{ Scan(...any) error }, link *model.ShareLink) error {
+	return row.Scan(&link.ID, &link.CreatedBy, &link.Project, &link.From, &link.To,
+		&link.ExpiresAt, &link.RevokedAt, &link.CreatedAt)
+}
+
+func (r *repository) CreateShareLink(ctx context.Context, userID int, req model.CreateShareLinkRequest, expiresAt time.Time) (*model.ShareLink, error) {
+	ctx, span := startChildSpan(ctx, "repository.CreateShareLink")
+	defer span.End()
+
+	query := `
+		INSERT INTO share_links (created_by, project, from_time, to_time, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + shareLinkColumns
+
+	var link model.ShareLink
+	err := scanShareLink(r.db.QueryRowContext(ctx, query, userID, req.Project, req.From, req.To, expiresAt), &link)
+	if err != nil {
+		return nil, errorutil.Wrap(err, "Failed to create share link")
+	}
+
+	r.logger.Infof("Created share link with ID: %d", link.ID)
+	return &link, nil
+}
+
+func (r *repository) ListShareLinks(ctx context.Context, userID int) ([]model.ShareLink, error) {
+	ctx, span := startChildSpan(ctx, "repository.ListShareLinks")
+	defer span.End()
+
+	query := `
+		SELECT ` + shareLinkColumns + `
+		FROM share_links
+		WHERE created_by = $1
+		ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, errorutil.Wrap(err, "Failed to execute query")
+	}
+
+	defer rows.Close()
+
+	links := []model.ShareLink{}
+
+	for rows.Next() {
+		var link model.ShareLink
+		if err := scanShareLink(rows, &link); err != nil {
+			return nil, errorutil.Wrap(err, "scanning share link row")
+		}
+		links = append(links, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errorutil.Wrap(err, "iterating share link rows")
+	}
+
+	return links, nil
+}
+
+// GetActiveShareLink returns the share link with the given ID unless it was revoked or has expired.
+func (r *repository) GetActiveShareLink(ctx context.Context, id int) (*model.ShareLink, error) {
+	ctx, span := startChildSpan(ctx, "repository.GetActiveShareLink")
+	defer span.End()
+
+	query := `
+		SELECT ` + shareLinkColumns + `
+		FROM share_links
+		WHERE id = $1
+		  AND revoked_at IS NULL
+		  AND expires_at > CURRENT_TIMESTAMP`
+
+	var link model.ShareLink
+	err := scanShareLink(r.db.QueryRowContext(ctx, query, id), &link)
+
+	if err == sql.ErrNoRows {
+		return nil, errorutil.New("share link not found")
+	}
+	if err != nil {
+		return nil, errorutil.Wrap(err, "Failed to get share link")
+	}
+
+	return &link, nil
+}
+
+// RevokeShareLink revokes a link created by userID. Admins may revoke any link.
+func (r *repository) RevokeShareLink(ctx context.Context, id, userID int, isAdmin bool) error {
+	ctx, span := startChildSpan(ctx, "repository.RevokeShareLink")
+	defer span.End()
+
+	query := `
+		UPDATE share_links
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		  AND revoked_at IS NULL
+		  AND (created_by = $2 OR $3)`
+
+	result, err := r.db.ExecContext(ctx, query, id, userID, isAdmin)
+	if err != nil {
+		return errorutil.Wrap(err, "Failed to revoke share link")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errorutil.Wrap(err, "Failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errorutil.New("share link not found")
+	}
+
+	r.logger.Infof("Revoked share link with ID: %d", id)
+	return nil
+}
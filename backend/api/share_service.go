@@ -0,0 +1,180 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"timetracker/api/model"
+	"timetracker/errorutil"
+)
+
+var (
+	errShareDisabled     = errorutil.New("share links are not enabled")
+	errInvalidShareToken = errorutil.New("invalid share token")
+)
+
+func (s *service) CreateShareLinkService(ctx context.Context, user *model.User, req model.CreateShareLinkRequest) (*model.ShareLink, error) {
+	ctx, span := tracer.Start(ctx, "service.CreateShareLinkService")
+	defer span.End()
+
+	if s.cfg.Share.SigningSecret == "" {
+		return nil, errShareDisabled
+	}
+
+	hours := req.ExpiresInHours
+	if hours <= 0 {
+		hours = s.cfg.Share.DefaultTTLHours
+	}
+	if maxHours := s.cfg.Share.MaxTTLHours; maxHours > 0 && hours > maxHours {
+		hours = maxHours
+	}
+	// Expiry is embedded in the token in whole seconds, so the stored value is truncated to match.
+	expiresAt := time.Now().Add(time.Duration(hours) * time.Hour).Truncate(time.Second)
+
+	link, err := s.repo.CreateShareLink(ctx, user.ID, req, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	link.URL = s.shareURL(link)
+	return link, nil
+}
+
+func (s *service) ListShareLinksService(ctx context.Context, user *model.User) ([]model.ShareLink, error) {
+	ctx, span := tracer.Start(ctx, "service.ListShareLinksService")
+	defer span.End()
+
+	links, err := s.repo.ListShareLinks(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.cfg.Share.SigningSecret != "" {
+		for i := range links {
+			if links[i].RevokedAt == nil && links[i].ExpiresAt.After(time.Now()) {
+				links[i].URL = s.shareURL(&links[i])
+			}
+		}
+	}
+	return links, nil
+}
+
+func (s *service) RevokeShareLinkService(ctx context.Context, user *model.User, id int) error {
+	ctx, span := tracer.Start(ctx, "service.RevokeShareLinkService")
+	defer span.End()
+
+	return s.repo.RevokeShareLink(ctx, id, user.ID, user.Role == model.RoleAdmin)
+}
+
+// GetSharedReportService validates the signature and expiry of a share token before anything
+// is read from the database, then builds the report for the link's project and date range. The
+// report only covers the trackers of the user who created the link.
+func (s *service) GetSharedReportService(ctx context.Context, token string) (*model.Report, error) {
+	ctx, span := tracer.Start(ctx, "service.GetSharedReportService")
+	defer span.End()
+
+	if s.cfg.Share.SigningSecret == "" {
+		return nil, errShareDisabled
+	}
+
+	id, expiresAt, err := s.verifyShareToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	link, err := s.repo.GetActiveShareLink(ctx, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, fmt.Errorf("%w: link was revoked", errInvalidShareToken)
+		}
+		return nil, err
+	}
+	if link.ExpiresAt.Unix() != expiresAt {
+		return nil, fmt.Errorf("%w: expiry does not match the link", errInvalidShareToken)
+	}
+
+	trackers, err := s.repo.FindTrackers(ctx, model.TrackerFilter{
+		Project: link.Project,
+		From:    link.From,
+		To:      link.To,
+		UserID:  &link.CreatedBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	report := &model.Report{
+		Project:     link.Project,
+		From:        link.From,
+		To:          link.To,
+		GeneratedAt: now,
+		ExpiresAt:   link.ExpiresAt,
+		Entries:     make([]model.ReportEntry, 0, len(trackers)),
+	}
+
+	for _, t := range trackers {
+		duration := trackerDuration(t, now)
+
+		report.TotalSeconds += duration
+		report.Entries = append(report.Entries, model.ReportEntry{
+			Task:            t.Task,
+			Project:         t.Project,
+			StartTime:       t.StartTime,
+			EndTime:         t.EndTime,
+			DurationSeconds: duration,
+		})
+	}
+	report.TotalHours = secondsToHours(report.TotalSeconds)
+
+	return report, nil
+}
+
+// Share tokens have the form <id>.<expires unix>.<signature>, the signature being the
+// HMAC-SHA256 of the id and expiry under the configured signing secret.
+func (s *service) signShareToken(id int, expiresAt int64) string {
+	mac := hmac.New(sha256.New, []byte(s.cfg.Share.SigningSecret))
+	fmt.Fprintf(mac, "share-link:%d:%d", id, expiresAt)
+	signature := base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+
+	return fmt.Sprintf("%d.%d.%s", id, expiresAt, signature)
+}
+
+func (s *service) verifyShareToken(token string) (int, int64, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, 0, fmt.Errorf("%w: malformed token", errInvalidShareToken)
+	}
+
+	id, err := strconv.Atoi(parts[0])
+	if err != nil || id <= 0 {
+		return 0, 0, fmt.Errorf("%w: malformed token", errInvalidShareToken)
+	}
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: malformed token", errInvalidShareToken)
+	}
+
+	if !hmac.Equal([]byte(token), []byte(s.signShareToken(id, expiresAt))) {
+		return 0, 0, fmt.Errorf("%w: bad signature", errInvalidShareToken)
+	}
+	if time.Now().Unix() >= expiresAt {
+		return 0, 0, fmt.Errorf("%w: link has expired", errInvalidShareToken)
+	}
+
+	return id, expiresAt, nil
+}
+
+func (s *service) shareURL(link *model.ShareLink) string {
+	return strings.TrimSuffix(s.cfg.Share.BaseURL, "/") + "/v1/share/" + s.signShareToken(link.ID, link.ExpiresAt.Unix())
+}
@@ -0,0 +1,86 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"timetracker/api/model"
+	"timetracker/internal/config"
+	"timetracker/logger"
+)
+
+// TestSharedReportOnlyListsTheCreator books trackers of two users on the same project and checks
+// that the links of one user, with and without project, never show the other's. It needs
+// TEST_DATABASE_URL.
+func TestSharedReportOnlyListsTheCreator(t *testing.T) {
+	database, withDB := openTestDatabase(t)
+	if !withDB {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	log := logger.NewLogger("share", filepath.Join(t.TempDir(), "api.log"))
+	s := Service(Repository(database, log), &config.Config{Share: config.ShareConfig{SigningSecret: "share-test-secret"}})
+	ctx := context.Background()
+
+	suffix := time.Now().UnixNano()
+	project := fmt.Sprintf("Shared %d", suffix)
+	from := time.Now().Add(-time.Hour)
+	to := time.Now().Add(time.Hour)
+
+	users := map[string]*model.User{}
+	for _, name := range []string{"owner", "other"} {
+		user, err := s.repo.UpsertOIDCUser(ctx, model.OIDCIdentity{
+			Issuer:  "share-test",
+			Subject: fmt.Sprintf("%s-%d", name, suffix),
+			Email:   fmt.Sprintf("share-%s-%d@example.com", name, suffix),
+		})
+		if err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+		users[name] = user
+
+		if _, err := s.CreateTrackerService(ctx, model.CreateTrackerRequest{
+			Task:      "Task of " + name,
+			Project:   &project,
+			StartTime: time.Now(),
+			UserID:    &user.ID,
+		}); err != nil {
+			t.Fatalf("Failed to create tracker: %v", err)
+		}
+	}
+
+	for name, req := range map[string]model.CreateShareLinkRequest{
+		"project":    {Project: &project, From: &from, To: &to, ExpiresInHours: 1},
+		"no project": {From: &from, To: &to, ExpiresInHours: 1},
+	} {
+		link, err := s.CreateShareLinkService(ctx, users["owner"], req)
+		if err != nil {
+			t.Fatalf("%s: Failed to create share link: %v", name, err)
+		}
+		report, err := s.GetSharedReportService(ctx, link.URL[strings.LastIndex(link.URL, "/")+1:])
+		if err != nil {
+			t.Fatalf("%s: Failed to get report: %v", name, err)
+		}
+
+		owned := 0
+		for _, entry := range report.Entries {
+			switch entry.Task {
+			case "Task of other":
+				t.Errorf("%s: report lists a tracker of another user", name)
+			case "Task of owner":
+				owned++
+			}
+		}
+		if owned != 1 {
+			t.Errorf("%s: report lists %d trackers of the owner, want 1", name, owned)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"timetracker/api/model"
+)
+
+// validateSyncChange checks a change against the rules declared on SyncChange. The data of an
+// upsert is required and validated like the body of PUT, the data of a delete is ignored.
+func (h *handler) validateSyncChange(change *model.SyncChange) []model.FieldError {
+	if change.Op == model.SyncOpDelete {
+		withoutData := *change
+		withoutData.Data = nil
+		return validateStruct(&withoutData)
+	}
+
+	errors := validateStruct(change)
+	if change.Op == model.SyncOpUpsert && change.Data == nil {
+		errors = append(errors, fieldError("data", model.FieldErrorRequired, "data is required for upsert"))
+	}
+	return errors
+}
+
+// sendSyncError answers a failed pull, with 400 for a cursor the server did not issue.
+func (h *handler) sendSyncError(w http.ResponseWriter, req *http.Request, handlerName string, err error) {
+	if errors.Is(err, errInvalidSyncCursor) {
+		h.sendErrorResponse(w, req, http.StatusBadRequest,
+			"Invalid cursor",
+			"since must be a cursor returned by a previous sync",
+			"INVALID_CURSOR")
+		return
+	}
+
+	h.log(req).Errorf("%s: Service error - %v", handlerName, err)
+	h.sendErrorResponse(w, req, http.StatusInternalServerError,
+		"Failed to sync",
+		"An error occurred while loading the changes",
+		"SYNC_ERROR")
+}
+
+// GetSyncChangesHandler returns the trackers of the signed in user that changed since a cursor,
+// for clients that keep a local copy. It accepts the query parameter:
+//   - since: string (optional, the cursor of the previous sync)
+//
+// The response holds the changed trackers, tombstones of deleted ones and the cursor for the next
+// call. Without since, or with a cursor older than the event retention, reset is true and trackers
+// holds all trackers of the user.
+//
+// Returns:
+//   - 200 OK: The changes and the next cursor
+//   - 400 Bad Request: since is not a cursor issued by the server
+//   - 401 Unauthorized: No valid session
+//   - 500 Internal Server Error: Database or server errors
+func (h *handler) GetSyncChangesHandler(w http.ResponseWriter, req *http.Request) {
+	h.log(req).Infof("GetSyncChangesHandler: Processing request from %s", req.RemoteAddr)
+
+	user, _ := userFromContext(req.Context())
+
+	response, err := h.service.PullSyncChangesService(req.Context(), user, req.URL.Query().Get("since"))
+	if err != nil {
+		h.sendSyncError(w, req, "GetSyncChangesHandler", err)
+		return
+	}
+
+	h.log(req).Infof("GetSyncChangesHandler: Sent %d changed and %d deleted trackers to user ID %d, reset: %t",
+		len(response.Trackers), len(response.Deleted), user.ID, response.Reset)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// PushSyncChangesHandler applies the changes a client made offline and returns the changes since
+// its cursor, including its own, in one round-trip. It expects a JSON payload containing:
+//   - since: string (optional, the cursor of the previous sync)
+//   - changes: array (required, up to 500 changes)
+//
+// Each change contains:
+//   - client_id: string (required, UUID the client generated for the tracker)
+//   - op: string (required, upsert or delete)
+//   - base_version: integer (optional, the server version the client edited, absent for new trackers)
+//   - modified_at: timestamp (required, when the change was made on the device)
+//   - data: object (the complete tracker like the body of PUT, required for upsert)
+//
+// The results array holds one entry per change, in order, with status applied, conflict or
+// rejected. A conflict reports its reason and whether the client or the server version won.
+//
+// Returns:
+//   - 200 OK: Results of the changes, the changes since the cursor and the next cursor
+//   - 400 Bad Request: Invalid JSON payload, number of changes, or cursor
+//   - 401 Unauthorized: No valid session
+//   - 500 Internal Server Error: Database or server errors
+func (h *handler) PushSyncChangesHandler(w http.ResponseWriter, req *http.Request) {
+	h.log(req).Infof("PushSyncChangesHandler: Processing request from %s", req.RemoteAddr)
+
+	user, _ := userFromContext(req.Context())
+
+	var request model.SyncRequest
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		h.log(req).Warnf("PushSyncChangesHandler: Failed to decode JSON - %v", err)
+		h.sendErrorResponse(w, req, http.StatusBadRequest,
+			"Invalid JSON payload",
+			"Request body must be valid JSON matching SyncRequest schema",
+			"INVALID_JSON")
+		return
+	}
+
+	if validationErrors := validateStruct(&request); len(validationErrors) > 0 {
+		h.log(req).Warnf("PushSyncChangesHandler: Validation failed - %s", fieldErrorMessages(validationErrors))
+		h.sendValidationErrorResponse(w, req, validationErrors)
+		return
+	}
+	if request.Since != "" {
+		if _, _, err := decodeSyncCursor(request.Since); err != nil {
+			h.sendSyncError(w, req, "PushSyncChangesHandler", err)
+			return
+		}
+	}
+
+	results := make([]model.SyncChangeResult, len(request.Changes))
+	changes := make([]model.SyncChange, 0, len(request.Changes))
+	changeIndexes := make([]int, 0, len(request.Changes))
+	seen := map[string]bool{}
+
+	for i := range request.Changes {
+		change := &request.Changes[i]
+		validationErrors := h.validateSyncChange(change)
+		if seen[strings.ToLower(change.ClientID)] {
+			validationErrors = append(validationErrors,
+				fieldError("client_id", model.FieldErrorDuplicate, "client_id appears more than once"))
+		}
+		seen[strings.ToLower(change.ClientID)] = true
+
+		if len(validationErrors) > 0 {
+			results[i] = model.SyncChangeResult{
+				ClientID: change.ClientID,
+				Status:   model.SyncStatusRejected,
+				Error: &model.OperationError{
+					Code:    "VALIDATION_ERROR",
+					Message: fieldErrorMessages(validationErrors),
+					Errors:  validationErrors,
+				},
+			}
+			continue
+		}
+		changes = append(changes, *change)
+		changeIndexes = append(changeIndexes, i)
+	}
+
+	if len(changes) > 0 {
+		applied, err := h.service.PushSyncChangesService(req.Context(), user, changes)
+		if err != nil {
+			h.log(req).Errorf("PushSyncChangesHandler: Service error - %v", err)
+			h.sendErrorResponse(w, req, http.StatusInternalServerError,
+				"Failed to sync",
+				"An error occurred while committing the changes to database",
+				"SYNC_ERROR")
+			return
+		}
+		for j, result := range applied {
+			results[changeIndexes[j]] = result
+		}
+	}
+
+	response, err := h.service.PullSyncChangesService(req.Context(), user, request.Since)
+	if err != nil {
+		h.sendSyncError(w, req, "PushSyncChangesHandler", err)
+		return
+	}
+	response.Results = results
+
+	conflicts := 0
+	for _, result := range results {
+		if result.Status == model.SyncStatusConflict {
+			conflicts++
+		}
+	}
+	h.log(req).Infof("PushSyncChangesHandler: Processed %d changes of user ID %d, %d conflicts",
+		len(results), user.ID, conflicts)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
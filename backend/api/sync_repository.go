@@ -0,0 +1,132 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+package api
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"timetracker/api/model"
+	"timetracker/errorutil"
+)
+
+// SyncHorizon returns the oldest transaction id that may still be running. Every change made by a
+// transaction with a lower id is already committed or rolled back, so a client that read all
+// changes before taking the horizon only needs the changes of transactions from the horizon on.
+func (r *repository) SyncHorizon(ctx context.Context) (uint64, error) {
+	ctx, span := startChildSpan(ctx, "repository.SyncHorizon")
+	defer span.End()
+
+	var horizon string
+	if err := r.db.QueryRowContext(ctx, `SELECT pg_snapshot_xmin(pg_current_snapshot())::TEXT`).Scan(&horizon); err != nil {
+		return 0, errorutil.Wrap(err, "Failed to get sync horizon")
+	}
+
+	value, err := strconv.ParseUint(horizon, 10, 64)
+	if err != nil {
+		return 0, errorutil.Wrap(err, "Failed to parse sync horizon")
+	}
+	return value, nil
+}
+
+// ChangedTrackersSince returns the trackers of userID that were created or updated by a transaction
+// from horizon on, ordered by id.
+func (r *repository) ChangedTrackersSince(ctx context.Context, userID int, horizon uint64) ([]model.Tracker, error) {
+	ctx, span := startChildSpan(ctx, "repository.ChangedTrackersSince")
+	defer span.End()
+
+	query := `
+		SELECT ` + trackerColumns + `
+		FROM tracker
+		WHERE user_id = $1
+		  AND id IN (SELECT tracker_id FROM tracker_events WHERE txid >= $2::xid8)
+		ORDER BY id ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, strconv.FormatUint(horizon, 10))
+	if err != nil {
+		return nil, errorutil.Wrap(err, "Failed to execute query")
+	}
+
+	defer rows.Close()
+
+	trackers := []model.Tracker{}
+
+	for rows.Next() {
+		var t model.Tracker
+		if err := scanTracker(rows, &t); err != nil {
+			return nil, errorutil.Wrap(err, "scanning tracker row")
+		}
+		trackers = append(trackers, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errorutil.Wrap(err, "iterating tracker rows")
+	}
+
+	return trackers, nil
+}
+
+// DeletedTrackersSince returns the trackers of userID that were deleted by a transaction from
+// horizon on, ordered by id.
+func (r *repository) DeletedTrackersSince(ctx context.Context, userID int, horizon uint64) ([]model.SyncTombstone, error) {
+	ctx, span := startChildSpan(ctx, "repository.DeletedTrackersSince")
+	defer span.End()
+
+	query := `
+		SELECT e.tracker_id, e.payload->>'client_id', e.created_at
+		FROM tracker_events e
+		WHERE e.type = 'deleted'
+		  AND e.txid >= $2::xid8
+		  AND e.payload->>'user_id' = $1::TEXT
+		ORDER BY e.tracker_id ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, strconv.FormatUint(horizon, 10))
+	if err != nil {
+		return nil, errorutil.Wrap(err, "Failed to execute query")
+	}
+
+	defer rows.Close()
+
+	tombstones := []model.SyncTombstone{}
+
+	for rows.Next() {
+		var t model.SyncTombstone
+		if err := rows.Scan(&t.ID, &t.ClientID, &t.DeletedAt); err != nil {
+			return nil, errorutil.Wrap(err, "scanning tombstone row")
+		}
+		tombstones = append(tombstones, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errorutil.Wrap(err, "iterating tombstone rows")
+	}
+
+	return tombstones, nil
+}
+
+// LockTrackerByClientID returns the tracker with clientID and locks it until the end of the
+// transaction, so the conflict check and the write of a sync change cannot interleave with
+// other writes. It must be called on a repository passed to an InTx callback.
+func (r *repository) LockTrackerByClientID(ctx context.Context, clientID string) (*model.Tracker, error) {
+	ctx, span := startChildSpan(ctx, "repository.LockTrackerByClientID")
+	defer span.End()
+
+	query := `
+		SELECT ` + trackerColumns + `
+		FROM tracker
+		WHERE client_id = $1
+		FOR UPDATE`
+
+	var tracker model.Tracker
+	err := scanTracker(r.db.QueryRowContext(ctx, query, clientID), &tracker)
+
+	if err == sql.ErrNoRows {
+		return nil, errorutil.New("tracker not found")
+	}
+	if err != nil {
+		return nil, errorutil.Wrap(err, "Failed to get tracker by client ID")
+	}
+
+	return &tracker, nil
+}
@@ -0,0 +1,213 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"timetracker/api/model"
+	"timetracker/errorutil"
+)
+
+var errInvalidSyncCursor = errorutil.New("invalid sync cursor")
+
+// Sync cursors have the form v1.<horizon>.<issued unix>, base64url encoded. They are opaque to clients.
+func encodeSyncCursor(horizon uint64, issuedAt time.Time) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("v1.%d.%d", horizon, issuedAt.Unix())))
+}
+
+func decodeSyncCursor(cursor string) (uint64, time.Time, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, time.Time{}, errInvalidSyncCursor
+	}
+
+	parts := strings.Split(string(raw), ".")
+	if len(parts) != 3 || parts[0] != "v1" {
+		return 0, time.Time{}, errInvalidSyncCursor
+	}
+	horizon, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return 0, time.Time{}, errInvalidSyncCursor
+	}
+	issued, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return 0, time.Time{}, errInvalidSyncCursor
+	}
+	return horizon, time.Unix(issued, 0), nil
+}
+
+// PullSyncChangesService returns the trackers of user changed since cursor and a new cursor.
+// Without cursor, or when the changes since cursor were already pruned from the event log, it
+// returns all trackers of the user with Reset set.
+// Changes can be reported twice, clients apply them by version, trackers first, then tombstones.
+func (s *service) PullSyncChangesService(ctx context.Context, user *model.User, cursor string) (*model.SyncResponse, error) {
+	ctx, span := tracer.Start(ctx, "service.PullSyncChangesService")
+	defer span.End()
+
+	var since uint64
+	reset := cursor == ""
+	if !reset {
+		horizon, issuedAt, err := decodeSyncCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		retention := time.Duration(s.cfg.Events.RetentionHours) * time.Hour
+		reset = retention > 0 && time.Since(issuedAt) > retention
+		since = horizon
+	}
+
+	// The horizon is taken before reading, so whatever commits in between is read again next time
+	// instead of being missed.
+	issuedAt := time.Now()
+	horizon, err := s.repo.SyncHorizon(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &model.SyncResponse{
+		Cursor:  encodeSyncCursor(horizon, issuedAt),
+		Reset:   reset,
+		Deleted: []model.SyncTombstone{},
+	}
+
+	if reset {
+		response.Trackers, err = s.repo.FindTrackers(ctx, model.TrackerFilter{UserID: &user.ID})
+		return response, err
+	}
+
+	if response.Trackers, err = s.repo.ChangedTrackersSince(ctx, user.ID, since); err != nil {
+		return nil, err
+	}
+	if response.Deleted, err = s.repo.DeletedTrackersSince(ctx, user.ID, since); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// PushSyncChangesService applies the changes of an offline client in one transaction, each in its
+// own savepoint so a failing change does not undo the others. Conflicts are resolved by these rules:
+//   - A change based on the current server version is applied.
+//   - An edit wins over a concurrent delete: deleting a tracker that changed on the server is
+//     refused, editing a tracker deleted on the server recreates it.
+//   - Of two concurrent edits the later one wins, by modified_at of the change and updated_at of
+//     the server tracker.
+//
+// The returned error is only set when the transaction itself failed.
+func (s *service) PushSyncChangesService(ctx context.Context, user *model.User, changes []model.SyncChange) ([]model.SyncChangeResult, error) {
+	ctx, span := tracer.Start(ctx, "service.PushSyncChangesService")
+	defer span.End()
+
+	results := make([]model.SyncChangeResult, len(changes))
+
+	err := s.repo.InTx(ctx, func(tx *repository) error {
+		for i, change := range changes {
+			var applyErr error
+			err := tx.Savepoint(ctx, "sync_change", func() error {
+				results[i], applyErr = applySyncChange(ctx, tx, user, change)
+				return applyErr
+			})
+			if err != nil && err != applyErr {
+				return err
+			}
+			if applyErr != nil {
+				results[i] = model.SyncChangeResult{
+					ClientID: change.ClientID,
+					Status:   model.SyncStatusRejected,
+					Error: &model.OperationError{
+						Code:    "SYNC_ERROR",
+						Message: "An error occurred while writing the change to database",
+					},
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func applySyncChange(ctx context.Context, repo *repository, user *model.User, change model.SyncChange) (model.SyncChangeResult, error) {
+	result := model.SyncChangeResult{ClientID: change.ClientID, Status: model.SyncStatusApplied}
+
+	current, err := repo.LockTrackerByClientID(ctx, change.ClientID)
+	if err != nil && !strings.Contains(err.Error(), "not found") {
+		return result, err
+	}
+	if current != nil && (current.UserID == nil || *current.UserID != user.ID) {
+		result.Status = model.SyncStatusRejected
+		result.Error = &model.OperationError{Code: "FORBIDDEN", Message: "The tracker belongs to another user"}
+		return result, nil
+	}
+
+	if change.Op == model.SyncOpDelete {
+		result.Deleted = true
+		if current == nil {
+			// Already deleted, or created and deleted offline before it was ever synced.
+			return result, nil
+		}
+		if change.BaseVersion != nil && *change.BaseVersion != current.Version {
+			result.Status = model.SyncStatusConflict
+			result.Deleted = false
+			result.Tracker = current
+			result.Conflict = &model.SyncConflict{
+				Reason:        model.SyncConflictModified,
+				Resolution:    model.SyncResolutionServerWins,
+				ServerVersion: current.Version,
+			}
+			return result, nil
+		}
+		return result, repo.DeleteTracker(ctx, current.ID, []int{current.Version})
+	}
+
+	if current == nil {
+		result.Tracker, err = repo.CreateTracker(ctx, model.CreateTrackerRequest{
+			Task:      change.Data.Task,
+			Project:   change.Data.Project,
+			StartTime: change.Data.StartTime,
+			EndTime:   change.Data.EndTime,
+			UserID:    &user.ID,
+			ClientID:  &change.ClientID,
+		})
+		if change.BaseVersion != nil {
+			result.Status = model.SyncStatusConflict
+			result.Conflict = &model.SyncConflict{
+				Reason:     model.SyncConflictDeleted,
+				Resolution: model.SyncResolutionClientWins,
+			}
+		}
+		return result, err
+	}
+
+	// A change without base version for a known tracker is the retry of a create whose response
+	// was lost, it is based on the first version.
+	base := 1
+	if change.BaseVersion != nil {
+		base = *change.BaseVersion
+	}
+	if base != current.Version {
+		result.Status = model.SyncStatusConflict
+		result.Conflict = &model.SyncConflict{
+			Reason:        model.SyncConflictModified,
+			Resolution:    model.SyncResolutionServerWins,
+			ServerVersion: current.Version,
+		}
+		if !change.ModifiedAt.After(current.UpdatedAt) {
+			result.Tracker = current
+			return result, nil
+		}
+		result.Conflict.Resolution = model.SyncResolutionClientWins
+	}
+
+	result.Tracker, err = replaceTracker(ctx, repo, current.ID, *change.Data, []int{current.Version})
+	return result, err
+}
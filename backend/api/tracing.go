@@ -0,0 +1,248 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"timetracker/errorutil"
+	"timetracker/internal/config"
+	"timetracker/logger"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer starts the spans of the handler, service and repository layers. It follows the global
+// tracer provider, so its spans are dropped until SetupTracing installed one.
+var tracer = otel.Tracer("timetracker/api")
+
+// SetupTracing installs the global tracer provider with the configured exporter and the W3C trace
+// context propagator. The returned function flushes the spans still buffered and stops the
+// exporter; it is to be called once the servers stopped.
+func SetupTracing(cfg *config.Config) (func(context.Context) error, error) {
+	tracing := cfg.Tracing
+	serviceName := tracing.ServiceName
+	if serviceName == "" {
+		serviceName = "timetracker-api"
+	}
+	ratio := tracing.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+
+	res, err := resource.Merge(resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, errorutil.Wrap(err, "Failed to describe the traced service")
+	}
+
+	var (
+		processor sdktrace.SpanProcessor
+		file      *os.File
+	)
+	switch tracing.Exporter {
+	case "", "otlp":
+		exporter, err := otlpExporter(tracing)
+		if err != nil {
+			return nil, errorutil.Wrap(err, "Failed to create OTLP exporter")
+		}
+		processor = sdktrace.NewBatchSpanProcessor(exporter)
+
+	case "stdout", "file":
+		opts := []stdouttrace.Option{stdouttrace.WithPrettyPrint()}
+		if tracing.Exporter == "file" {
+			path := tracing.File
+			if path == "" {
+				path = "traces.json"
+			}
+			file, err = os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+			if err != nil {
+				return nil, errorutil.Wrap(err, "Failed to open trace file")
+			}
+			opts = []stdouttrace.Option{stdouttrace.WithWriter(file)}
+		}
+		exporter, err := stdouttrace.New(opts...)
+		if err != nil {
+			return nil, errorutil.Wrap(err, "Failed to create trace exporter")
+		}
+		// Local debugging wants to see a request right after it was answered, not once a batch filled.
+		processor = sdktrace.NewSimpleSpanProcessor(exporter)
+
+	default:
+		return nil, errorutil.New("Unknown trace exporter " + tracing.Exporter)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithSpanProcessor(processor),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{}))
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if file != nil {
+			err = errors.Join(err, file.Close())
+		}
+		return err
+	}, nil
+}
+
+// otlpExporter sends the spans to an OpenTelemetry collector over gRPC or HTTP. Without an
+// Endpoint the OTEL_EXPORTER_OTLP_* environment variables and then the default local collector
+// apply.
+func otlpExporter(tracing config.TracingConfig) (sdktrace.SpanExporter, error) {
+	ctx := context.Background()
+
+	switch tracing.Protocol {
+	case "", "grpc":
+		var opts []otlptracegrpc.Option
+		if tracing.Endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(tracing.Endpoint))
+		}
+		if tracing.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(ctx, opts...)
+
+	case "http":
+		var opts []otlptracehttp.Option
+		if tracing.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(tracing.Endpoint))
+		}
+		if tracing.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, opts...)
+
+	default:
+		return nil, errorutil.New("Unknown OTLP protocol " + tracing.Protocol)
+	}
+}
+
+// startChildSpan starts a span below the span of ctx and starts none without one. Repository
+// methods and SQL statements thereby show up in the trace of the request, service call or webhook
+// delivery they belong to, while the polling of the background workers does not produce a trace
+// every few seconds.
+func startChildSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+	return tracer.Start(ctx, name, opts...)
+}
+
+// startQuerySpan starts the span of an SQL statement run by the repository method. The arguments
+// are left out, they may hold personal data.
+func startQuerySpan(ctx context.Context, method, query string) (context.Context, trace.Span) {
+	query = strings.Join(strings.Fields(query), " ")
+	operation, _, _ := strings.Cut(query, " ")
+	operation = strings.ToUpper(operation)
+
+	return startChildSpan(ctx, operation, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNamePostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBQueryText(query),
+			semconv.CodeFunctionName("repository."+method),
+		))
+}
+
+// endSpan ends span, marking it failed when err is set.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// traceRequests continues the trace of the caller, as sent in the W3C traceparent header, or
+// starts a new one, with a server span per request named after the route pattern. The trace ID
+// is written with every log line of the request.
+func (r *router) traceRequests(next http.Handler) http.Handler {
+	if !r.cfg.Tracing.Enabled {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+
+		name := req.Method
+		attrs := []attribute.KeyValue{
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.URLPath(req.URL.Path),
+			semconv.UserAgentOriginal(req.UserAgent()),
+			semconv.ClientAddress(clientIP(req, r.cfg.RateLimit.TrustProxyHeaders)),
+		}
+		if _, pattern := r.mux.Handler(req); pattern != "" {
+			route := pattern
+			if _, path, found := strings.Cut(pattern, " "); found {
+				route = path
+			}
+			name += " " + route
+			attrs = append(attrs, semconv.HTTPRoute(route))
+		}
+
+		ctx, span := tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attrs...))
+		if sc := span.SpanContext(); sc.IsValid() {
+			ctx = logger.ContextWith(ctx, logger.TraceID(sc.TraceID().String()))
+		}
+		rec := &responseRecorder{ResponseWriter: w}
+
+		defer func() {
+			aborted := recover()
+			status := rec.status
+			if status == 0 && aborted == nil {
+				status = http.StatusOK
+			}
+			if status != 0 {
+				span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+			}
+			if status >= http.StatusInternalServerError || aborted != nil {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+			span.End()
+
+			if aborted != nil {
+				panic(aborted)
+			}
+		}()
+
+		next.ServeHTTP(rec, req.WithContext(ctx))
+	})
+}
+
+// traceHandler wraps the handler of a route in a span, which tells the time spent in the handler,
+// e.g. decoding the request body, apart from the middleware and the service calls below it.
+func (r *router) traceHandler(pattern string, next http.HandlerFunc) http.HandlerFunc {
+	if !r.cfg.Tracing.Enabled {
+		return next
+	}
+
+	name := "handler " + pattern
+	return func(w http.ResponseWriter, req *http.Request) {
+		ctx, span := tracer.Start(req.Context(), name)
+		defer span.End()
+
+		next(w, req.WithContext(ctx))
+	}
+}
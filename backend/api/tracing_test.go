@@ -0,0 +1,106 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"timetracker/internal/config"
+	"timetracker/logger"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+)
+
+func TestTracingSpansFollowTheLayers(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	log := logger.NewLogger("tracing", filepath.Join(t.TempDir(), "api.log"))
+	cfg := &config.Config{Tracing: config.TracingConfig{Enabled: true}}
+	repo := Repository(nil, log)
+	repo.db = timedDB{dbtx: failingDB{}, metrics: repo.metrics}
+	routes := Router(log, Handler(Service(repo, cfg), log, cfg), cfg).SetRoutes()
+
+	const (
+		traceID      = "4bf92f3577b34da6a3ce929d0e0e4736"
+		callerSpanID = "00f067aa0ba902b7"
+	)
+	req := httptest.NewRequest(http.MethodDelete, "/v1/trackers/7", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-"+callerSpanID+"-01")
+	w := httptest.NewRecorder()
+	routes.ServeHTTP(w, req)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("DELETE answered %d, want 500 from the failing database", w.Code)
+	}
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+		if got := span.SpanContext().TraceID().String(); got != traceID {
+			t.Errorf("span %q is in trace %s, want the caller's %s", span.Name(), got, traceID)
+		}
+	}
+
+	// Each span is the child of the one before.
+	parentID := callerSpanID
+	for _, name := range []string{
+		"DELETE /v1/trackers/{id}",
+		"handler DELETE /v1/trackers/{id}",
+		"service.DeleteTrackerService",
+		"repository.DeleteTracker",
+		"DELETE",
+	} {
+		span, ok := spans[name]
+		if !ok {
+			t.Fatalf("no span %q among %d spans", name, len(spans))
+		}
+		if got := span.Parent().SpanID().String(); got != parentID {
+			t.Errorf("span %q has parent %s, want %s", name, got, parentID)
+		}
+		parentID = span.SpanContext().SpanID().String()
+	}
+
+	server := spans["DELETE /v1/trackers/{id}"]
+	if server.Status().Code != codes.Error {
+		t.Errorf("server span status %v, want an error for the 500", server.Status())
+	}
+	if !hasAttribute(server, semconv.HTTPResponseStatusCode(http.StatusInternalServerError)) {
+		t.Errorf("server span lacks the status code: %v", server.Attributes())
+	}
+
+	statement := spans["DELETE"]
+	if !hasAttribute(statement, semconv.DBQueryText("DELETE FROM tracker WHERE id = $1")) {
+		t.Errorf("statement span lacks the query: %v", statement.Attributes())
+	}
+	if statement.Status().Code != codes.Error || len(statement.Events()) == 0 {
+		t.Errorf("statement span does not record the error: %v", statement.Status())
+	}
+}
+
+func hasAttribute(span sdktrace.ReadOnlySpan, want attribute.KeyValue) bool {
+	for _, attr := range span.Attributes() {
+		if attr == want {
+			return true
+		}
+	}
+	return false
+}
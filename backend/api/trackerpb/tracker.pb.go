@@ -0,0 +1,994 @@
+// Copyright © 2025 My personal.
+//
+// All rights reserved.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: timetracker/v1/tracker.proto
+
+package trackerpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Tracker struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Task      string                 `protobuf:"bytes,2,opt,name=task,proto3" json:"task,omitempty"`
+	Project   *string                `protobuf:"bytes,3,opt,name=project,proto3,oneof" json:"project,omitempty"`
+	StartTime *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	// Unset while the tracker is running.
+	EndTime    *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	CreateTime *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=create_time,json=createTime,proto3" json:"create_time,omitempty"`
+	UpdateTime *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=update_time,json=updateTime,proto3" json:"update_time,omitempty"`
+	// Incremented on every change, pass it as version to make a write conditional.
+	Version       int32  `protobuf:"varint,8,opt,name=version,proto3" json:"version,omitempty"`
+	UserId        *int64 `protobuf:"varint,9,opt,name=user_id,json=userId,proto3,oneof" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Tracker) Reset() {
+	*x = Tracker{}
+	mi := &file_timetracker_v1_tracker_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Tracker) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Tracker) ProtoMessage() {}
+
+func (x *Tracker) ProtoReflect() protoreflect.Message {
+	mi := &file_timetracker_v1_tracker_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Tracker.ProtoReflect.Descriptor instead.
+func (*Tracker) Descriptor() ([]byte, []int) {
+	return file_timetracker_v1_tracker_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Tracker) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Tracker) GetTask() string {
+	if x != nil {
+		return x.Task
+	}
+	return ""
+}
+
+func (x *Tracker) GetProject() string {
+	if x != nil && x.Project != nil {
+		return *x.Project
+	}
+	return ""
+}
+
+func (x *Tracker) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *Tracker) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *Tracker) GetCreateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreateTime
+	}
+	return nil
+}
+
+func (x *Tracker) GetUpdateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdateTime
+	}
+	return nil
+}
+
+func (x *Tracker) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Tracker) GetUserId() int64 {
+	if x != nil && x.UserId != nil {
+		return *x.UserId
+	}
+	return 0
+}
+
+type GetTrackerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTrackerRequest) Reset() {
+	*x = GetTrackerRequest{}
+	mi := &file_timetracker_v1_tracker_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTrackerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTrackerRequest) ProtoMessage() {}
+
+func (x *GetTrackerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_timetracker_v1_tracker_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTrackerRequest.ProtoReflect.Descriptor instead.
+func (*GetTrackerRequest) Descriptor() ([]byte, []int) {
+	return file_timetracker_v1_tracker_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetTrackerRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListTrackersRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Project *string                `protobuf:"bytes,1,opt,name=project,proto3,oneof" json:"project,omitempty"`
+	// Inclusive, compared with the start time.
+	From *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	// Exclusive, compared with the start time.
+	To *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	// Only the caller's own id, unless the caller is an admin.
+	UserId *int64 `protobuf:"varint,4,opt,name=user_id,json=userId,proto3,oneof" json:"user_id,omitempty"`
+	// Only running trackers when true.
+	Running bool `protobuf:"varint,5,opt,name=running,proto3" json:"running,omitempty"`
+	// Between 1 and 500, defaults to 100.
+	PageSize int32 `protobuf:"varint,6,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// The next_page_token of the previous page.
+	PageToken     string `protobuf:"bytes,7,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTrackersRequest) Reset() {
+	*x = ListTrackersRequest{}
+	mi := &file_timetracker_v1_tracker_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTrackersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTrackersRequest) ProtoMessage() {}
+
+func (x *ListTrackersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_timetracker_v1_tracker_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTrackersRequest.ProtoReflect.Descriptor instead.
+func (*ListTrackersRequest) Descriptor() ([]byte, []int) {
+	return file_timetracker_v1_tracker_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListTrackersRequest) GetProject() string {
+	if x != nil && x.Project != nil {
+		return *x.Project
+	}
+	return ""
+}
+
+func (x *ListTrackersRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *ListTrackersRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *ListTrackersRequest) GetUserId() int64 {
+	if x != nil && x.UserId != nil {
+		return *x.UserId
+	}
+	return 0
+}
+
+func (x *ListTrackersRequest) GetRunning() bool {
+	if x != nil {
+		return x.Running
+	}
+	return false
+}
+
+func (x *ListTrackersRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListTrackersRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListTrackersResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Trackers []*Tracker             `protobuf:"bytes,1,rep,name=trackers,proto3" json:"trackers,omitempty"`
+	// Empty on the last page.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTrackersResponse) Reset() {
+	*x = ListTrackersResponse{}
+	mi := &file_timetracker_v1_tracker_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTrackersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTrackersResponse) ProtoMessage() {}
+
+func (x *ListTrackersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_timetracker_v1_tracker_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTrackersResponse.ProtoReflect.Descriptor instead.
+func (*ListTrackersResponse) Descriptor() ([]byte, []int) {
+	return file_timetracker_v1_tracker_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListTrackersResponse) GetTrackers() []*Tracker {
+	if x != nil {
+		return x.Trackers
+	}
+	return nil
+}
+
+func (x *ListTrackersResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type CreateTrackerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Task          string                 `protobuf:"bytes,1,opt,name=task,proto3" json:"task,omitempty"`
+	Project       *string                `protobuf:"bytes,2,opt,name=project,proto3,oneof" json:"project,omitempty"`
+	StartTime     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime       *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTrackerRequest) Reset() {
+	*x = CreateTrackerRequest{}
+	mi := &file_timetracker_v1_tracker_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTrackerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTrackerRequest) ProtoMessage() {}
+
+func (x *CreateTrackerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_timetracker_v1_tracker_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTrackerRequest.ProtoReflect.Descriptor instead.
+func (*CreateTrackerRequest) Descriptor() ([]byte, []int) {
+	return file_timetracker_v1_tracker_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreateTrackerRequest) GetTask() string {
+	if x != nil {
+		return x.Task
+	}
+	return ""
+}
+
+func (x *CreateTrackerRequest) GetProject() string {
+	if x != nil && x.Project != nil {
+		return *x.Project
+	}
+	return ""
+}
+
+func (x *CreateTrackerRequest) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *CreateTrackerRequest) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+type UpdateTrackerRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Task      string                 `protobuf:"bytes,2,opt,name=task,proto3" json:"task,omitempty"`
+	Project   *string                `protobuf:"bytes,3,opt,name=project,proto3,oneof" json:"project,omitempty"`
+	StartTime *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime   *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	// When set the update fails with ABORTED unless the tracker still has this version.
+	Version       *int32 `protobuf:"varint,6,opt,name=version,proto3,oneof" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateTrackerRequest) Reset() {
+	*x = UpdateTrackerRequest{}
+	mi := &file_timetracker_v1_tracker_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTrackerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTrackerRequest) ProtoMessage() {}
+
+func (x *UpdateTrackerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_timetracker_v1_tracker_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTrackerRequest.ProtoReflect.Descriptor instead.
+func (*UpdateTrackerRequest) Descriptor() ([]byte, []int) {
+	return file_timetracker_v1_tracker_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UpdateTrackerRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateTrackerRequest) GetTask() string {
+	if x != nil {
+		return x.Task
+	}
+	return ""
+}
+
+func (x *UpdateTrackerRequest) GetProject() string {
+	if x != nil && x.Project != nil {
+		return *x.Project
+	}
+	return ""
+}
+
+func (x *UpdateTrackerRequest) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *UpdateTrackerRequest) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *UpdateTrackerRequest) GetVersion() int32 {
+	if x != nil && x.Version != nil {
+		return *x.Version
+	}
+	return 0
+}
+
+type PatchTrackerRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Only the fields listed in update_mask are read.
+	Tracker *Tracker `protobuf:"bytes,2,opt,name=tracker,proto3" json:"tracker,omitempty"`
+	// Paths among task, project, start_time and end_time.
+	UpdateMask    *fieldmaskpb.FieldMask `protobuf:"bytes,3,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+	Version       *int32                 `protobuf:"varint,4,opt,name=version,proto3,oneof" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PatchTrackerRequest) Reset() {
+	*x = PatchTrackerRequest{}
+	mi := &file_timetracker_v1_tracker_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PatchTrackerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PatchTrackerRequest) ProtoMessage() {}
+
+func (x *PatchTrackerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_timetracker_v1_tracker_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PatchTrackerRequest.ProtoReflect.Descriptor instead.
+func (*PatchTrackerRequest) Descriptor() ([]byte, []int) {
+	return file_timetracker_v1_tracker_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *PatchTrackerRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *PatchTrackerRequest) GetTracker() *Tracker {
+	if x != nil {
+		return x.Tracker
+	}
+	return nil
+}
+
+func (x *PatchTrackerRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+func (x *PatchTrackerRequest) GetVersion() int32 {
+	if x != nil && x.Version != nil {
+		return *x.Version
+	}
+	return 0
+}
+
+type DeleteTrackerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Version       *int32                 `protobuf:"varint,2,opt,name=version,proto3,oneof" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteTrackerRequest) Reset() {
+	*x = DeleteTrackerRequest{}
+	mi := &file_timetracker_v1_tracker_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTrackerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTrackerRequest) ProtoMessage() {}
+
+func (x *DeleteTrackerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_timetracker_v1_tracker_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTrackerRequest.ProtoReflect.Descriptor instead.
+func (*DeleteTrackerRequest) Descriptor() ([]byte, []int) {
+	return file_timetracker_v1_tracker_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DeleteTrackerRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DeleteTrackerRequest) GetVersion() int32 {
+	if x != nil && x.Version != nil {
+		return *x.Version
+	}
+	return 0
+}
+
+type StartTimerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Task          string                 `protobuf:"bytes,1,opt,name=task,proto3" json:"task,omitempty"`
+	Project       *string                `protobuf:"bytes,2,opt,name=project,proto3,oneof" json:"project,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartTimerRequest) Reset() {
+	*x = StartTimerRequest{}
+	mi := &file_timetracker_v1_tracker_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartTimerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartTimerRequest) ProtoMessage() {}
+
+func (x *StartTimerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_timetracker_v1_tracker_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartTimerRequest.ProtoReflect.Descriptor instead.
+func (*StartTimerRequest) Descriptor() ([]byte, []int) {
+	return file_timetracker_v1_tracker_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *StartTimerRequest) GetTask() string {
+	if x != nil {
+		return x.Task
+	}
+	return ""
+}
+
+func (x *StartTimerRequest) GetProject() string {
+	if x != nil && x.Project != nil {
+		return *x.Project
+	}
+	return ""
+}
+
+type StopTimerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TrackerId     int64                  `protobuf:"varint,1,opt,name=tracker_id,json=trackerId,proto3" json:"tracker_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopTimerRequest) Reset() {
+	*x = StopTimerRequest{}
+	mi := &file_timetracker_v1_tracker_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopTimerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopTimerRequest) ProtoMessage() {}
+
+func (x *StopTimerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_timetracker_v1_tracker_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopTimerRequest.ProtoReflect.Descriptor instead.
+func (*StopTimerRequest) Descriptor() ([]byte, []int) {
+	return file_timetracker_v1_tracker_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *StopTimerRequest) GetTrackerId() int64 {
+	if x != nil {
+		return x.TrackerId
+	}
+	return 0
+}
+
+type ListRunningTimersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRunningTimersRequest) Reset() {
+	*x = ListRunningTimersRequest{}
+	mi := &file_timetracker_v1_tracker_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRunningTimersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRunningTimersRequest) ProtoMessage() {}
+
+func (x *ListRunningTimersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_timetracker_v1_tracker_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRunningTimersRequest.ProtoReflect.Descriptor instead.
+func (*ListRunningTimersRequest) Descriptor() ([]byte, []int) {
+	return file_timetracker_v1_tracker_proto_rawDescGZIP(), []int{10}
+}
+
+type ListRunningTimersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Timers        []*Tracker             `protobuf:"bytes,1,rep,name=timers,proto3" json:"timers,omitempty"`
+	ServerTime    *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=server_time,json=serverTime,proto3" json:"server_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRunningTimersResponse) Reset() {
+	*x = ListRunningTimersResponse{}
+	mi := &file_timetracker_v1_tracker_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRunningTimersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRunningTimersResponse) ProtoMessage() {}
+
+func (x *ListRunningTimersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_timetracker_v1_tracker_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRunningTimersResponse.ProtoReflect.Descriptor instead.
+func (*ListRunningTimersResponse) Descriptor() ([]byte, []int) {
+	return file_timetracker_v1_tracker_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListRunningTimersResponse) GetTimers() []*Tracker {
+	if x != nil {
+		return x.Timers
+	}
+	return nil
+}
+
+func (x *ListRunningTimersResponse) GetServerTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ServerTime
+	}
+	return nil
+}
+
+var File_timetracker_v1_tracker_proto protoreflect.FileDescriptor
+
+const file_timetracker_v1_tracker_proto_rawDesc = "" +
+	"\n" +
+	"\x1ctimetracker/v1/tracker.proto\x12\x0etimetracker.v1\x1a\x1bgoogle/protobuf/empty.proto\x1a google/protobuf/field_mask.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x88\x03\n" +
+	"\aTracker\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04task\x18\x02 \x01(\tR\x04task\x12\x1d\n" +
+	"\aproject\x18\x03 \x01(\tH\x00R\aproject\x88\x01\x01\x129\n" +
+	"\n" +
+	"start_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x12;\n" +
+	"\vcreate_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"createTime\x12;\n" +
+	"\vupdate_time\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"updateTime\x12\x18\n" +
+	"\aversion\x18\b \x01(\x05R\aversion\x12\x1c\n" +
+	"\auser_id\x18\t \x01(\x03H\x01R\x06userId\x88\x01\x01B\n" +
+	"\n" +
+	"\b_projectB\n" +
+	"\n" +
+	"\b_user_id\"#\n" +
+	"\x11GetTrackerRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x9c\x02\n" +
+	"\x13ListTrackersRequest\x12\x1d\n" +
+	"\aproject\x18\x01 \x01(\tH\x00R\aproject\x88\x01\x01\x12.\n" +
+	"\x04from\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x12\x1c\n" +
+	"\auser_id\x18\x04 \x01(\x03H\x01R\x06userId\x88\x01\x01\x12\x18\n" +
+	"\arunning\x18\x05 \x01(\bR\arunning\x12\x1b\n" +
+	"\tpage_size\x18\x06 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\a \x01(\tR\tpageTokenB\n" +
+	"\n" +
+	"\b_projectB\n" +
+	"\n" +
+	"\b_user_id\"s\n" +
+	"\x14ListTrackersResponse\x123\n" +
+	"\btrackers\x18\x01 \x03(\v2\x17.timetracker.v1.TrackerR\btrackers\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\xc7\x01\n" +
+	"\x14CreateTrackerRequest\x12\x12\n" +
+	"\x04task\x18\x01 \x01(\tR\x04task\x12\x1d\n" +
+	"\aproject\x18\x02 \x01(\tH\x00R\aproject\x88\x01\x01\x129\n" +
+	"\n" +
+	"start_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\aendTimeB\n" +
+	"\n" +
+	"\b_project\"\x82\x02\n" +
+	"\x14UpdateTrackerRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04task\x18\x02 \x01(\tR\x04task\x12\x1d\n" +
+	"\aproject\x18\x03 \x01(\tH\x00R\aproject\x88\x01\x01\x129\n" +
+	"\n" +
+	"start_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x12\x1d\n" +
+	"\aversion\x18\x06 \x01(\x05H\x01R\aversion\x88\x01\x01B\n" +
+	"\n" +
+	"\b_projectB\n" +
+	"\n" +
+	"\b_version\"\xc0\x01\n" +
+	"\x13PatchTrackerRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x121\n" +
+	"\atracker\x18\x02 \x01(\v2\x17.timetracker.v1.TrackerR\atracker\x12;\n" +
+	"\vupdate_mask\x18\x03 \x01(\v2\x1a.google.protobuf.FieldMaskR\n" +
+	"updateMask\x12\x1d\n" +
+	"\aversion\x18\x04 \x01(\x05H\x00R\aversion\x88\x01\x01B\n" +
+	"\n" +
+	"\b_version\"Q\n" +
+	"\x14DeleteTrackerRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1d\n" +
+	"\aversion\x18\x02 \x01(\x05H\x00R\aversion\x88\x01\x01B\n" +
+	"\n" +
+	"\b_version\"R\n" +
+	"\x11StartTimerRequest\x12\x12\n" +
+	"\x04task\x18\x01 \x01(\tR\x04task\x12\x1d\n" +
+	"\aproject\x18\x02 \x01(\tH\x00R\aproject\x88\x01\x01B\n" +
+	"\n" +
+	"\b_project\"1\n" +
+	"\x10StopTimerRequest\x12\x1d\n" +
+	"\n" +
+	"tracker_id\x18\x01 \x01(\x03R\ttrackerId\"\x1a\n" +
+	"\x18ListRunningTimersRequest\"\x89\x01\n" +
+	"\x19ListRunningTimersResponse\x12/\n" +
+	"\x06timers\x18\x01 \x03(\v2\x17.timetracker.v1.TrackerR\x06timers\x12;\n" +
+	"\vserver_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"serverTime2\xee\x05\n" +
+	"\x0eTrackerService\x12H\n" +
+	"\n" +
+	"GetTracker\x12!.timetracker.v1.GetTrackerRequest\x1a\x17.timetracker.v1.Tracker\x12Y\n" +
+	"\fListTrackers\x12#.timetracker.v1.ListTrackersRequest\x1a$.timetracker.v1.ListTrackersResponse\x12N\n" +
+	"\rCreateTracker\x12$.timetracker.v1.CreateTrackerRequest\x1a\x17.timetracker.v1.Tracker\x12N\n" +
+	"\rUpdateTracker\x12$.timetracker.v1.UpdateTrackerRequest\x1a\x17.timetracker.v1.Tracker\x12L\n" +
+	"\fPatchTracker\x12#.timetracker.v1.PatchTrackerRequest\x1a\x17.timetracker.v1.Tracker\x12M\n" +
+	"\rDeleteTracker\x12$.timetracker.v1.DeleteTrackerRequest\x1a\x16.google.protobuf.Empty\x12H\n" +
+	"\n" +
+	"StartTimer\x12!.timetracker.v1.StartTimerRequest\x1a\x17.timetracker.v1.Tracker\x12F\n" +
+	"\tStopTimer\x12 .timetracker.v1.StopTimerRequest\x1a\x17.timetracker.v1.Tracker\x12h\n" +
+	"\x11ListRunningTimers\x12(.timetracker.v1.ListRunningTimersRequest\x1a).timetracker.v1.ListRunningTimersResponseB\x1bZ\x19timetracker/api/trackerpbb\x06proto3"
+
+var (
+	file_timetracker_v1_tracker_proto_rawDescOnce sync.Once
+	file_timetracker_v1_tracker_proto_rawDescData []byte
+)
+
+func file_timetracker_v1_tracker_proto_rawDescGZIP() []byte {
+	file_timetracker_v1_tracker_proto_rawDescOnce.Do(func() {
+		file_timetracker_v1_tracker_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_timetracker_v1_tracker_proto_rawDesc), len(file_timetracker_v1_tracker_proto_rawDesc)))
+	})
+	return file_timetracker_v1_tracker_proto_rawDescData
+}
+
+var file_timetracker_v1_tracker_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_timetracker_v1_tracker_proto_goTypes = []any{
+	(*Tracker)(nil),                   // 0: timetracker.v1.Tracker
+	(*GetTrackerRequest)(nil),         // 1: timetracker.v1.GetTrackerRequest
+	(*ListTrackersRequest)(nil),       // 2: timetracker.v1.ListTrackersRequest
+	(*ListTrackersResponse)(nil),      // 3: timetracker.v1.ListTrackersResponse
+	(*CreateTrackerRequest)(nil),      // 4: timetracker.v1.CreateTrackerRequest
+	(*UpdateTrackerRequest)(nil),      // 5: timetracker.v1.UpdateTrackerRequest
+	(*PatchTrackerRequest)(nil),       // 6: timetracker.v1.PatchTrackerRequest
+	(*DeleteTrackerRequest)(nil),      // 7: timetracker.v1.DeleteTrackerRequest
+	(*StartTimerRequest)(nil),         // 8: timetracker.v1.StartTimerRequest
+	(*StopTimerRequest)(nil),          // 9: timetracker.v1.StopTimerRequest
+	(*ListRunningTimersRequest)(nil),  // 10: timetracker.v1.ListRunningTimersRequest
+	(*ListRunningTimersResponse)(nil), // 11: timetracker.v1.ListRunningTimersResponse
+	(*timestamppb.Timestamp)(nil),     // 12: google.protobuf.Timestamp
+	(*fieldmaskpb.FieldMask)(nil),     // 13: google.protobuf.FieldMask
+	(*emptypb.Empty)(nil),             // 14: google.protobuf.Empty
+}
+var file_timetracker_v1_tracker_proto_depIdxs = []int32{
+	12, // 0: timetracker.v1.Tracker.start_time:type_name -> google.protobuf.Timestamp
+	12, // 1: timetracker.v1.Tracker.end_time:type_name -> google.protobuf.Timestamp
+	12, // 2: timetracker.v1.Tracker.create_time:type_name -> google.protobuf.Timestamp
+	12, // 3: timetracker.v1.Tracker.update_time:type_name -> google.protobuf.Timestamp
+	12, // 4: timetracker.v1.ListTrackersRequest.from:type_name -> google.protobuf.Timestamp
+	12, // 5: timetracker.v1.ListTrackersRequest.to:type_name -> google.protobuf.Timestamp
+	0,  // 6: timetracker.v1.ListTrackersResponse.trackers:type_name -> timetracker.v1.Tracker
+	12, // 7: timetracker.v1.CreateTrackerRequest.start_time:type_name -> google.protobuf.Timestamp
+	12, // 8: timetracker.v1.CreateTrackerRequest.end_time:type_name -> google.protobuf.Timestamp
+	12, // 9: timetracker.v1.UpdateTrackerRequest.start_time:type_name -> google.protobuf.Timestamp
+	12, // 10: timetracker.v1.UpdateTrackerRequest.end_time:type_name -> google.protobuf.Timestamp
+	0,  // 11: timetracker.v1.PatchTrackerRequest.tracker:type_name -> timetracker.v1.Tracker
+	13, // 12: timetracker.v1.PatchTrackerRequest.update_mask:type_name -> google.protobuf.FieldMask
+	0,  // 13: timetracker.v1.ListRunningTimersResponse.timers:type_name -> timetracker.v1.Tracker
+	12, // 14: timetracker.v1.ListRunningTimersResponse.server_time:type_name -> google.protobuf.Timestamp
+	1,  // 15: timetracker.v1.TrackerService.GetTracker:input_type -> timetracker.v1.GetTrackerRequest
+	2,  // 16: timetracker.v1.TrackerService.ListTrackers:input_type -> timetracker.v1.ListTrackersRequest
+	4,  // 17: timetracker.v1.TrackerService.CreateTracker:input_type -> timetracker.v1.CreateTrackerRequest
+	5,  // 18: timetracker.v1.TrackerService.UpdateTracker:input_type -> timetracker.v1.UpdateTrackerRequest
+	6,  // 19: timetracker.v1.TrackerService.PatchTracker:input_type -> timetracker.v1.PatchTrackerRequest
+	7,  // 20: timetracker.v1.TrackerService.DeleteTracker:input_type -> timetracker.v1.DeleteTrackerRequest
+	8,  // 21: timetracker.v1.TrackerService.StartTimer:input_type -> timetracker.v1.StartTimerRequest
+	9,  // 22: timetracker.v1.TrackerService.StopTimer:input_type -> timetracker.v1.StopTimerRequest
+	10, // 23: timetracker.v1.TrackerService.ListRunningTimers:input_type -> timetracker.v1.ListRunningTimersRequest
+	0,  // 24: timetracker.v1.TrackerService.GetTracker:output_type -> timetracker.v1.Tracker
+	3,  // 25: timetracker.v1.TrackerService.ListTrackers:output_type -> timetracker.v1.ListTrackersResponse
+	0,  // 26: timetracker.v1.TrackerService.CreateTracker:output_type -> timetracker.v1.Tracker
+	0,  // 27: timetracker.v1.TrackerService.UpdateTracker:output_type -> timetracker.v1.Tracker
+	0,  // 28: timetracker.v1.TrackerService.PatchTracker:output_type -> timetracker.v1.Tracker
+	14, // 29: timetracker.v1.TrackerService.DeleteTracker:output_type -> google.protobuf.Empty
+	0,  // 30: timetracker.v1.TrackerService.StartTimer:output_type -> timetracker.v1.Tracker
+	0,  // 31: timetracker.v1.TrackerService.StopTimer:output_type -> timetracker.v1.Tracker
+	11, // 32: timetracker.v1.TrackerService.ListRunningTimers:output_type -> timetracker.v1.ListRunningTimersResponse
+	24, // [24:33] is the sub-list for method output_type
+	15, // [15:24] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
+}
+
+func init() { file_timetracker_v1_tracker_proto_init() }
+func file_timetracker_v1_tracker_proto_init() {
+	if File_timetracker_v1_tracker_proto != nil {
+		return
+	}
+	file_timetracker_v1_tracker_proto_msgTypes[0].OneofWrappers = []any{}
+	file_timetracker_v1_tracker_proto_msgTypes[2].OneofWrappers = []any{}
+	file_timetracker_v1_tracker_proto_msgTypes[4].OneofWrappers = []any{}
+	file_timetracker_v1_tracker_proto_msgTypes[5].OneofWrappers = []any{}
+	file_timetracker_v1_tracker_proto_msgTypes[6].OneofWrappers = []any{}
+	file_timetracker_v1_tracker_proto_msgTypes[7].OneofWrappers = []any{}
+	file_timetracker_v1_tracker_proto_msgTypes[8].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_timetracker_v1_tracker_proto_rawDesc), len(file_timetracker_v1_tracker_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_timetracker_v1_tracker_proto_goTypes,
+		DependencyIndexes: file_timetracker_v1_tracker_proto_depIdxs,
+		MessageInfos:      file_timetracker_v1_tracker_proto_msgTypes,
+	}.Build()
+	File_timetracker_v1_tracker_proto = out.File
+	file_timetracker_v1_tracker_proto_goTypes = nil
+	file_timetracker_v1_tracker_proto_depIdxs = nil
+}
@@ -0,0 +1,460 @@
+// Copyright © 2025 My personal.
+//
+// All rights reserved.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: timetracker/v1/tracker.proto
+
+package trackerpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	TrackerService_GetTracker_FullMethodName        = "/timetracker.v1.TrackerService/GetTracker"
+	TrackerService_ListTrackers_FullMethodName      = "/timetracker.v1.TrackerService/ListTrackers"
+	TrackerService_CreateTracker_FullMethodName     = "/timetracker.v1.TrackerService/CreateTracker"
+	TrackerService_UpdateTracker_FullMethodName     = "/timetracker.v1.TrackerService/UpdateTracker"
+	TrackerService_PatchTracker_FullMethodName      = "/timetracker.v1.TrackerService/PatchTracker"
+	TrackerService_DeleteTracker_FullMethodName     = "/timetracker.v1.TrackerService/DeleteTracker"
+	TrackerService_StartTimer_FullMethodName        = "/timetracker.v1.TrackerService/StartTimer"
+	TrackerService_StopTimer_FullMethodName         = "/timetracker.v1.TrackerService/StopTimer"
+	TrackerService_ListRunningTimers_FullMethodName = "/timetracker.v1.TrackerService/ListRunningTimers"
+)
+
+// TrackerServiceClient is the client API for TrackerService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// TrackerService gives internal services typed access to trackers. It shares the service layer
+// with the REST API, so validation rules and optimistic concurrency are the same.
+//
+// Calls may carry a session token as "authorization: Bearer <token>" metadata. Trackers created
+// with a token belong to its user, and the timer calls require one.
+type TrackerServiceClient interface {
+	// GetTracker returns NOT_FOUND when no tracker has the id.
+	GetTracker(ctx context.Context, in *GetTrackerRequest, opts ...grpc.CallOption) (*Tracker, error)
+	// ListTrackers returns the trackers matching the filter, oldest first.
+	ListTrackers(ctx context.Context, in *ListTrackersRequest, opts ...grpc.CallOption) (*ListTrackersResponse, error)
+	CreateTracker(ctx context.Context, in *CreateTrackerRequest, opts ...grpc.CallOption) (*Tracker, error)
+	// UpdateTracker replaces all fields of the tracker, unset optional fields are cleared.
+	UpdateTracker(ctx context.Context, in *UpdateTrackerRequest, opts ...grpc.CallOption) (*Tracker, error)
+	// PatchTracker changes the fields listed in update_mask. A listed field that is unset in
+	// tracker is cleared, e.g. end_time sets a stopped tracker back to running.
+	PatchTracker(ctx context.Context, in *PatchTrackerRequest, opts ...grpc.CallOption) (*Tracker, error)
+	DeleteTracker(ctx context.Context, in *DeleteTrackerRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// StartTimer starts a running tracker for the calling user at the current time.
+	StartTimer(ctx context.Context, in *StartTimerRequest, opts ...grpc.CallOption) (*Tracker, error)
+	// StopTimer stops a running tracker of the calling user. It returns PERMISSION_DENIED for
+	// trackers of other users and FAILED_PRECONDITION when the timer already stopped.
+	StopTimer(ctx context.Context, in *StopTimerRequest, opts ...grpc.CallOption) (*Tracker, error)
+	// ListRunningTimers returns the running trackers of the calling user.
+	ListRunningTimers(ctx context.Context, in *ListRunningTimersRequest, opts ...grpc.CallOption) (*ListRunningTimersResponse, error)
+}
+
+type trackerServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTrackerServiceClient(cc grpc.ClientConnInterface) TrackerServiceClient {
+	return &trackerServiceClient{cc}
+}
+
+func (c *trackerServiceClient) GetTracker(ctx context.Context, in *GetTrackerRequest, opts ...grpc.CallOption) (*Tracker, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Tracker)
+	err := c.cc.Invoke(ctx, TrackerService_GetTracker_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trackerServiceClient) ListTrackers(ctx context.Context, in *ListTrackersRequest, opts ...grpc.CallOption) (*ListTrackersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTrackersResponse)
+	err := c.cc.Invoke(ctx, TrackerService_ListTrackers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trackerServiceClient) CreateTracker(ctx context.Context, in *CreateTrackerRequest, opts ...grpc.CallOption) (*Tracker, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Tracker)
+	err := c.cc.Invoke(ctx, TrackerService_CreateTracker_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trackerServiceClient) UpdateTracker(ctx context.Context, in *UpdateTrackerRequest, opts ...grpc.CallOption) (*Tracker, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Tracker)
+	err := c.cc.Invoke(ctx, TrackerService_UpdateTracker_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trackerServiceClient) PatchTracker(ctx context.Context, in *PatchTrackerRequest, opts ...grpc.CallOption) (*Tracker, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Tracker)
+	err := c.cc.Invoke(ctx, TrackerService_PatchTracker_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trackerServiceClient) DeleteTracker(ctx context.Context, in *DeleteTrackerRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, TrackerService_DeleteTracker_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trackerServiceClient) StartTimer(ctx context.Context, in *StartTimerRequest, opts ...grpc.CallOption) (*Tracker, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Tracker)
+	err := c.cc.Invoke(ctx, TrackerService_StartTimer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trackerServiceClient) StopTimer(ctx context.Context, in *StopTimerRequest, opts ...grpc.CallOption) (*Tracker, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Tracker)
+	err := c.cc.Invoke(ctx, TrackerService_StopTimer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trackerServiceClient) ListRunningTimers(ctx context.Context, in *ListRunningTimersRequest, opts ...grpc.CallOption) (*ListRunningTimersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListRunningTimersResponse)
+	err := c.cc.Invoke(ctx, TrackerService_ListRunningTimers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TrackerServiceServer is the server API for TrackerService service.
+// All implementations must embed UnimplementedTrackerServiceServer
+// for forward compatibility.
+//
+// TrackerService gives internal services typed access to trackers. It shares the service layer
+// with the REST API, so validation rules and optimistic concurrency are the same.
+//
+// Calls may carry a session token as "authorization: Bearer <token>" metadata. Trackers created
+// with a token belong to its user, and the timer calls require one.
+type TrackerServiceServer interface {
+	// GetTracker returns NOT_FOUND when no tracker has the id.
+	GetTracker(context.Context, *GetTrackerRequest) (*Tracker, error)
+	// ListTrackers returns the trackers matching the filter, oldest first.
+	ListTrackers(context.Context, *ListTrackersRequest) (*ListTrackersResponse, error)
+	CreateTracker(context.Context, *CreateTrackerRequest) (*Tracker, error)
+	// UpdateTracker replaces all fields of the tracker, unset optional fields are cleared.
+	UpdateTracker(context.Context, *UpdateTrackerRequest) (*Tracker, error)
+	// PatchTracker changes the fields listed in update_mask. A listed field that is unset in
+	// tracker is cleared, e.g. end_time sets a stopped tracker back to running.
+	PatchTracker(context.Context, *PatchTrackerRequest) (*Tracker, error)
+	DeleteTracker(context.Context, *DeleteTrackerRequest) (*emptypb.Empty, error)
+	// StartTimer starts a running tracker for the calling user at the current time.
+	StartTimer(context.Context, *StartTimerRequest) (*Tracker, error)
+	// StopTimer stops a running tracker of the calling user. It returns PERMISSION_DENIED for
+	// trackers of other users and FAILED_PRECONDITION when the timer already stopped.
+	StopTimer(context.Context, *StopTimerRequest) (*Tracker, error)
+	// ListRunningTimers returns the running trackers of the calling user.
+	ListRunningTimers(context.Context, *ListRunningTimersRequest) (*ListRunningTimersResponse, error)
+	mustEmbedUnimplementedTrackerServiceServer()
+}
+
+// UnimplementedTrackerServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTrackerServiceServer struct{}
+
+func (UnimplementedTrackerServiceServer) GetTracker(context.Context, *GetTrackerRequest) (*Tracker, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetTracker not implemented")
+}
+func (UnimplementedTrackerServiceServer) ListTrackers(context.Context, *ListTrackersRequest) (*ListTrackersResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListTrackers not implemented")
+}
+func (UnimplementedTrackerServiceServer) CreateTracker(context.Context, *CreateTrackerRequest) (*Tracker, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateTracker not implemented")
+}
+func (UnimplementedTrackerServiceServer) UpdateTracker(context.Context, *UpdateTrackerRequest) (*Tracker, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateTracker not implemented")
+}
+func (UnimplementedTrackerServiceServer) PatchTracker(context.Context, *PatchTrackerRequest) (*Tracker, error) {
+	return nil, status.Error(codes.Unimplemented, "method PatchTracker not implemented")
+}
+func (UnimplementedTrackerServiceServer) DeleteTracker(context.Context, *DeleteTrackerRequest) (*emptypb.Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteTracker not implemented")
+}
+func (UnimplementedTrackerServiceServer) StartTimer(context.Context, *StartTimerRequest) (*Tracker, error) {
+	return nil, status.Error(codes.Unimplemented, "method StartTimer not implemented")
+}
+func (UnimplementedTrackerServiceServer) StopTimer(context.Context, *StopTimerRequest) (*Tracker, error) {
+	return nil, status.Error(codes.Unimplemented, "method StopTimer not implemented")
+}
+func (UnimplementedTrackerServiceServer) ListRunningTimers(context.Context, *ListRunningTimersRequest) (*ListRunningTimersResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListRunningTimers not implemented")
+}
+func (UnimplementedTrackerServiceServer) mustEmbedUnimplementedTrackerServiceServer() {}
+func (UnimplementedTrackerServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeTrackerServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TrackerServiceServer will
+// result in compilation errors.
+type UnsafeTrackerServiceServer interface {
+	mustEmbedUnimplementedTrackerServiceServer()
+}
+
+func RegisterTrackerServiceServer(s grpc.ServiceRegistrar, srv TrackerServiceServer) {
+	// If the following call panics, it indicates UnimplementedTrackerServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TrackerService_ServiceDesc, srv)
+}
+
+func _TrackerService_GetTracker_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTrackerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrackerServiceServer).GetTracker(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrackerService_GetTracker_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrackerServiceServer).GetTracker(ctx, req.(*GetTrackerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrackerService_ListTrackers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTrackersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrackerServiceServer).ListTrackers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrackerService_ListTrackers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrackerServiceServer).ListTrackers(ctx, req.(*ListTrackersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrackerService_CreateTracker_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateTrackerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrackerServiceServer).CreateTracker(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrackerService_CreateTracker_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrackerServiceServer).CreateTracker(ctx, req.(*CreateTrackerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrackerService_UpdateTracker_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateTrackerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrackerServiceServer).UpdateTracker(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrackerService_UpdateTracker_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrackerServiceServer).UpdateTracker(ctx, req.(*UpdateTrackerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrackerService_PatchTracker_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PatchTrackerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrackerServiceServer).PatchTracker(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrackerService_PatchTracker_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrackerServiceServer).PatchTracker(ctx, req.(*PatchTrackerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrackerService_DeleteTracker_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteTrackerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrackerServiceServer).DeleteTracker(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrackerService_DeleteTracker_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrackerServiceServer).DeleteTracker(ctx, req.(*DeleteTrackerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrackerService_StartTimer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartTimerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrackerServiceServer).StartTimer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrackerService_StartTimer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrackerServiceServer).StartTimer(ctx, req.(*StartTimerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrackerService_StopTimer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StopTimerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrackerServiceServer).StopTimer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrackerService_StopTimer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrackerServiceServer).StopTimer(ctx, req.(*StopTimerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrackerService_ListRunningTimers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRunningTimersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrackerServiceServer).ListRunningTimers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrackerService_ListRunningTimers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrackerServiceServer).ListRunningTimers(ctx, req.(*ListRunningTimersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TrackerService_ServiceDesc is the grpc.ServiceDesc for TrackerService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TrackerService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "timetracker.v1.TrackerService",
+	HandlerType: (*TrackerServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetTracker",
+			Handler:    _TrackerService_GetTracker_Handler,
+		},
+		{
+			MethodName: "ListTrackers",
+			Handler:    _TrackerService_ListTrackers_Handler,
+		},
+		{
+			MethodName: "CreateTracker",
+			Handler:    _TrackerService_CreateTracker_Handler,
+		},
+		{
+			MethodName: "UpdateTracker",
+			Handler:    _TrackerService_UpdateTracker_Handler,
+		},
+		{
+			MethodName: "PatchTracker",
+			Handler:    _TrackerService_PatchTracker_Handler,
+		},
+		{
+			MethodName: "DeleteTracker",
+			Handler:    _TrackerService_DeleteTracker_Handler,
+		},
+		{
+			MethodName: "StartTimer",
+			Handler:    _TrackerService_StartTimer_Handler,
+		},
+		{
+			MethodName: "StopTimer",
+			Handler:    _TrackerService_StopTimer_Handler,
+		},
+		{
+			MethodName: "ListRunningTimers",
+			Handler:    _TrackerService_ListRunningTimers_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "timetracker/v1/tracker.proto",
+}
@@ -0,0 +1,234 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+package api
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"timetracker/api/model"
+	"timetracker/errorutil"
+)
+
+const userColumns = `u.id, u.email, u.name, u.role, u.totp_enabled, u.last_login_at, u.created_at, u.updated_at`
+
+func scanUser(row interface{ Scan(...any) error }, user *model.User) error {
+	return row.Scan(&user.ID, &user.Email, &user.Name, &user.Role, &user.TOTPEnabled, &user.LastLoginAt,
+		&user.CreatedAt, &user.UpdatedAt)
+}
+
+// errOIDCIdentityConflict is returned when the email of a login belongs to an account that is
+// linked to another identity.
+var errOIDCIdentityConflict = errorutil.New("email belongs to an account linked to another identity")
+
+// UpsertOIDCUser provisions the user identified by the issuer and subject of the ID token on first
+// login and refreshes the stored email, name and role on every later one. The email only links an
+// existing account that has no identity yet; an account linked to another identity is never taken
+// over and the login fails with errOIDCIdentityConflict. The admin role is only ever granted here, never revoked.
+func (r *repository) UpsertOIDCUser(ctx context.Context, identity model.OIDCIdentity) (*model.User, error) {
+	ctx, span := startChildSpan(ctx, "repository.UpsertOIDCUser")
+	defer span.End()
+
+	var user model.User
+	err := r.InTx(ctx, func(tx *repository) error {
+		var linked bool
+		err := tx.db.QueryRowContext(ctx, `
+			SELECT oidc_issuer IS NOT NULL
+			FROM users
+			WHERE email = $1 AND (oidc_issuer, oidc_subject) IS DISTINCT FROM ($2, $3)
+			FOR UPDATE`, identity.Email, identity.Issuer, identity.Subject).Scan(&linked)
+		switch {
+		case err == sql.ErrNoRows:
+		case err != nil:
+			return errorutil.Wrap(err, "Failed to look up user by email")
+		case linked:
+			return errOIDCIdentityConflict
+		default:
+			_, err := tx.db.ExecContext(ctx, `UPDATE users SET oidc_issuer = $2, oidc_subject = $3 WHERE email = $1`,
+				identity.Email, identity.Issuer, identity.Subject)
+			if err != nil {
+				return errorutil.Wrap(err, "Failed to link user")
+			}
+		}
+
+		query := `
+			INSERT INTO users AS u (email, name, role, oidc_issuer, oidc_subject, last_login_at)
+			VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)
+			ON CONFLICT (oidc_issuer, oidc_subject) DO UPDATE SET
+				email = EXCLUDED.email,
+				name = COALESCE(NULLIF(EXCLUDED.name, ''), u.name),
+				role = CASE WHEN EXCLUDED.role = 'admin' THEN 'admin' ELSE u.role END,
+				last_login_at = CURRENT_TIMESTAMP,
+				updated_at = CURRENT_TIMESTAMP
+			RETURNING ` + userColumns
+
+		err = scanUser(tx.db.QueryRowContext(ctx, query, identity.Email, identity.Name, identity.Role,
+			identity.Issuer, identity.Subject), &user)
+		if err != nil {
+			return errorutil.Wrap(err, "Failed to provision user")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.Infof("Provisioned user with ID: %d", user.ID)
+	return &user, nil
+}
+
+// CreateSession stores a new session. Sessions of users with two-factor authentication start
+// with mfaPending set and are only usable for the second factor until it is verified.
+func (r *repository) CreateSession(ctx context.Context, userID int, tokenHash string, expiresAt time.Time, mfaPending bool) (*model.Session, error) {
+	ctx, span := startChildSpan(ctx, "repository.CreateSession")
+	defer span.End()
+
+	query := `
+		INSERT INTO sessions (user_id, token_hash, expires_at, mfa_pending)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, user_id, mfa_pending, expires_at, created_at`
+
+	var session model.Session
+	err := r.db.QueryRowContext(ctx, query, userID, tokenHash, expiresAt, mfaPending).Scan(
+		&session.ID, &session.UserID, &session.MFAPending, &session.ExpiresAt, &session.CreatedAt)
+	if err != nil {
+		return nil, errorutil.Wrap(err, "Failed to create session")
+	}
+
+	r.logger.Infof("Created session %d for user ID: %d", session.ID, userID)
+	return &session, nil
+}
+
+// GetSessionUser returns the active session with the given token hash and the user it belongs to.
+func (r *repository) GetSessionUser(ctx context.Context, tokenHash string) (*model.Session, *model.User, error) {
+	ctx, span := startChildSpan(ctx, "repository.GetSessionUser")
+	defer span.End()
+
+	query := `
+		SELECT s.id, s.user_id, s.mfa_pending, s.expires_at, s.created_at, ` + userColumns + `
+		FROM sessions s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.token_hash = $1
+		  AND s.revoked_at IS NULL
+		  AND s.expires_at > CURRENT_TIMESTAMP`
+
+	var session model.Session
+	var user model.User
+	err := r.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&session.ID, &session.UserID, &session.MFAPending, &session.ExpiresAt, &session.CreatedAt,
+		&user.ID, &user.Email, &user.Name, &user.Role, &user.TOTPEnabled, &user.LastLoginAt,
+		&user.CreatedAt, &user.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil, errorutil.New("session not found")
+	}
+	if err != nil {
+		return nil, nil, errorutil.Wrap(err, "Failed to get session")
+	}
+
+	return &session, &user, nil
+}
+
+func (r *repository) RevokeSession(ctx context.Context, tokenHash string) error {
+	ctx, span := startChildSpan(ctx, "repository.RevokeSession")
+	defer span.End()
+
+	query := `UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP WHERE token_hash = $1 AND revoked_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, tokenHash)
+	if err != nil {
+		return errorutil.Wrap(err, "Failed to revoke session")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errorutil.Wrap(err, "Failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errorutil.New("session not found")
+	}
+
+	r.logger.Infof("Revoked session")
+	return nil
+}
+
+func (r *repository) SaveOIDCLoginRequest(ctx context.Context, req model.OIDCLoginRequest) error {
+	ctx, span := startChildSpan(ctx, "repository.SaveOIDCLoginRequest")
+	defer span.End()
+
+	// Abandoned logins are cleaned up whenever a new one starts.
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM oidc_login_requests WHERE expires_at < CURRENT_TIMESTAMP`); err != nil {
+		return errorutil.Wrap(err, "Failed to delete expired login requests")
+	}
+
+	query := `
+		INSERT INTO oidc_login_requests (state, nonce, code_verifier, expires_at)
+		VALUES ($1, $2, $3, $4)`
+
+	if _, err := r.db.ExecContext(ctx, query, req.State, req.Nonce, req.CodeVerifier, req.ExpiresAt); err != nil {
+		return errorutil.Wrap(err, "Failed to save login request")
+	}
+
+	return nil
+}
+
+// ConsumeOIDCLoginRequest deletes and returns the pending login for state, so every state
+// value can complete exactly one login.
+func (r *repository) ConsumeOIDCLoginRequest(ctx context.Context, state string) (*model.OIDCLoginRequest, error) {
+	ctx, span := startChildSpan(ctx, "repository.ConsumeOIDCLoginRequest")
+	defer span.End()
+
+	query := `
+		DELETE FROM oidc_login_requests
+		WHERE state = $1
+		RETURNING state, nonce, code_verifier, expires_at`
+
+	var req model.OIDCLoginRequest
+	err := r.db.QueryRowContext(ctx, query, state).Scan(&req.State, &req.Nonce, &req.CodeVerifier, &req.ExpiresAt)
+
+	if err == sql.ErrNoRows {
+		return nil, errorutil.New("login request not found")
+	}
+	if err != nil {
+		return nil, errorutil.Wrap(err, "Failed to get login request")
+	}
+
+	if time.Now().After(req.ExpiresAt) {
+		return nil, errorutil.New("login request not found")
+	}
+
+	return &req, nil
+}
+
+// GetUsersByIDs returns the users with the given ids by id. Unknown ids are left out.
+func (r *repository) GetUsersByIDs(ctx context.Context, ids []int) (map[int]*model.User, error) {
+	ctx, span := startChildSpan(ctx, "repository.GetUsersByIDs")
+	defer span.End()
+
+	query := `SELECT ` + userColumns + ` FROM users u WHERE u.id = ANY($1::int[])`
+
+	rows, err := r.db.QueryContext(ctx, query, intArray(ids))
+	if err != nil {
+		return nil, errorutil.Wrap(err, "Failed to execute query")
+	}
+
+	defer rows.Close()
+
+	users := make(map[int]*model.User, len(ids))
+	for rows.Next() {
+		var user model.User
+		if err := scanUser(rows, &user); err != nil {
+			return nil, errorutil.Wrap(err, "scanning user row")
+		}
+		users[user.ID] = &user
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errorutil.Wrap(err, "iterating user rows")
+	}
+
+	return users, nil
+}
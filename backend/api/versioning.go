@@ -0,0 +1,62 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// prefixPattern puts prefix in front of the path of a ServeMux pattern, keeping its method.
+func prefixPattern(prefix, pattern string) string {
+	if method, path, ok := strings.Cut(pattern, " "); ok {
+		return method + " " + prefix + path
+	}
+	return prefix + pattern
+}
+
+// deprecationHeaders are sent with every response of a legacy route: Deprecation (RFC 9745) and
+// Sunset (RFC 8594), from the dates in the configuration. Dates that are not set or cannot be
+// parsed are left out.
+func (r *router) deprecationHeaders() http.Header {
+	headers := http.Header{}
+	cfg := r.cfg.LegacyRoutes
+
+	if cfg.DeprecatedAt != "" {
+		if at, err := time.Parse(time.RFC3339, cfg.DeprecatedAt); err == nil {
+			headers.Set("Deprecation", fmt.Sprintf("@%d", at.Unix()))
+		} else {
+			r.logger.Warnf("Invalid legacy_routes.deprecated_at %q, expected RFC 3339: %v", cfg.DeprecatedAt, err)
+		}
+	}
+
+	if cfg.SunsetAt != "" {
+		if at, err := time.Parse(time.RFC3339, cfg.SunsetAt); err == nil {
+			headers.Set("Sunset", at.UTC().Format(http.TimeFormat))
+		} else {
+			r.logger.Warnf("Invalid legacy_routes.sunset_at %q, expected RFC 3339: %v", cfg.SunsetAt, err)
+		}
+	}
+
+	return headers
+}
+
+// deprecated serves next as an alias of the same route under prefix. The response points to the
+// successor with a Link header, so clients can find out where to migrate.
+func (r *router) deprecated(headers http.Header, prefix string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		for name := range headers {
+			w.Header().Set(name, headers.Get(name))
+		}
+		w.Header().Set("Link", fmt.Sprintf("<%s%s>; rel=\"successor-version\"", prefix, req.URL.EscapedPath()))
+
+		r.logger.Debugf("deprecated: %s %s served through the unversioned alias", req.Method, req.URL.Path)
+		next(w, req)
+	}
+}
@@ -0,0 +1,226 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"timetracker/api/model"
+)
+
+// CreateWebhookHandler subscribes a URL to tracker events. Every event is posted to the URL as
+// JSON, signed with the subscription secret in the X-Timetracker-Signature header.
+// It expects a JSON payload containing:
+//   - url: string (required, absolute http or https URL)
+//   - event_types: array of strings (required, tracker.created, tracker.updated, tracker.deleted,
+//     tracker.started or tracker.stopped)
+//   - secret: string (optional, 16 to 200 characters, generated when missing)
+//
+// Returns:
+//   - 201 Created: The subscription including its secret, which is not returned again
+//   - 400 Bad Request: Invalid JSON payload or validation errors
+//   - 401 Unauthorized: No valid session
+//   - 403 Forbidden: The user is not an admin
+//   - 500 Internal Server Error: Database or server errors
+func (h *handler) CreateWebhookHandler(w http.ResponseWriter, req *http.Request) {
+	h.log(req).Infof("CreateWebhookHandler: Processing request from %s", req.RemoteAddr)
+
+	user, _ := userFromContext(req.Context())
+
+	var request model.CreateWebhookRequest
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		h.log(req).Warnf("CreateWebhookHandler: Failed to decode JSON - %v", err)
+		h.sendErrorResponse(w, req, http.StatusBadRequest,
+			"Invalid JSON payload",
+			"Request body must be valid JSON matching CreateWebhookRequest schema",
+			"INVALID_JSON")
+		return
+	}
+
+	if validationErrors := validateStruct(&request); len(validationErrors) > 0 {
+		h.log(req).Warnf("CreateWebhookHandler: Validation failed - %s", fieldErrorMessages(validationErrors))
+		h.sendValidationErrorResponse(w, req, validationErrors)
+		return
+	}
+
+	webhook, err := h.service.CreateWebhookService(req.Context(), user, request)
+	if err != nil {
+		h.log(req).Errorf("CreateWebhookHandler: Service error - %v", err)
+		h.sendErrorResponse(w, req, http.StatusInternalServerError,
+			"Failed to create webhook",
+			"An error occurred while saving the webhook to database",
+			"CREATE_ERROR")
+		return
+	}
+
+	h.log(req).Infof("CreateWebhookHandler: Admin ID %d created webhook ID %d for %s", user.ID, webhook.ID, strings.Join(webhook.EventTypes, ", "))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(webhook)
+}
+
+// ListWebhooksHandler returns all webhook subscriptions, oldest first, without their secrets.
+//
+// Returns:
+//   - 200 OK: Array of subscriptions (may be empty)
+//   - 401 Unauthorized: No valid session
+//   - 403 Forbidden: The user is not an admin
+//   - 500 Internal Server Error: Database or server errors
+func (h *handler) ListWebhooksHandler(w http.ResponseWriter, req *http.Request) {
+	h.log(req).Infof("ListWebhooksHandler: Processing request from %s", req.RemoteAddr)
+
+	webhooks, err := h.service.ListWebhooksService(req.Context())
+	if err != nil {
+		h.log(req).Errorf("ListWebhooksHandler: Service error - %v", err)
+		h.sendErrorResponse(w, req, http.StatusInternalServerError,
+			"Failed to fetch webhooks",
+			"An error occurred while retrieving webhooks from database",
+			"FETCH_ERROR")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(webhooks)
+}
+
+// GetWebhookHandler returns a webhook subscription by ID, without its secret.
+//
+// Returns:
+//   - 200 OK: The subscription
+//   - 400 Bad Request: Invalid ID parameter
+//   - 401 Unauthorized: No valid session
+//   - 403 Forbidden: The user is not an admin
+//   - 404 Not Found: No subscription with the ID exists
+//   - 500 Internal Server Error: Database or server errors
+func (h *handler) GetWebhookHandler(w http.ResponseWriter, req *http.Request) {
+	h.log(req).Infof("GetWebhookHandler: Processing request from %s", req.RemoteAddr)
+
+	id, ok := h.webhookIDFromPath(w, req, "GetWebhookHandler")
+	if !ok {
+		return
+	}
+
+	webhook, err := h.service.GetWebhookService(req.Context(), id)
+	if err != nil {
+		h.sendWebhookError(w, req, "GetWebhookHandler", id, err, "Failed to fetch webhook", "FETCH_ERROR")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(webhook)
+}
+
+// DeleteWebhookHandler removes a webhook subscription by ID. Its queued deliveries are dropped
+// and its delivery log is deleted.
+//
+// Returns:
+//   - 204 No Content: Successfully deleted the subscription
+//   - 400 Bad Request: Invalid ID parameter
+//   - 401 Unauthorized: No valid session
+//   - 403 Forbidden: The user is not an admin
+//   - 404 Not Found: No subscription with the ID exists
+//   - 500 Internal Server Error: Database or server errors
+func (h *handler) DeleteWebhookHandler(w http.ResponseWriter, req *http.Request) {
+	h.log(req).Infof("DeleteWebhookHandler: Processing request from %s", req.RemoteAddr)
+
+	id, ok := h.webhookIDFromPath(w, req, "DeleteWebhookHandler")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteWebhookService(req.Context(), id); err != nil {
+		h.sendWebhookError(w, req, "DeleteWebhookHandler", id, err, "Failed to delete webhook", "DELETE_ERROR")
+		return
+	}
+
+	h.log(req).Infof("DeleteWebhookHandler: Successfully deleted webhook ID: %d", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListWebhookDeliveriesHandler returns the delivery log of a webhook subscription, newest first.
+// Each delivery lists its attempts with the status code the receiver answered or the error.
+// It accepts the query parameters:
+//   - status: string (optional, pending, delivered or failed)
+//   - limit: integer (optional, at most 200, defaults to 50)
+//
+// Returns:
+//   - 200 OK: Array of deliveries (may be empty)
+//   - 400 Bad Request: Invalid ID or query parameters
+//   - 401 Unauthorized: No valid session
+//   - 403 Forbidden: The user is not an admin
+//   - 404 Not Found: No subscription with the ID exists
+//   - 500 Internal Server Error: Database or server errors
+func (h *handler) ListWebhookDeliveriesHandler(w http.ResponseWriter, req *http.Request) {
+	h.log(req).Infof("ListWebhookDeliveriesHandler: Processing request from %s", req.RemoteAddr)
+
+	id, ok := h.webhookIDFromPath(w, req, "ListWebhookDeliveriesHandler")
+	if !ok {
+		return
+	}
+
+	query := req.URL.Query()
+	filter := model.WebhookDeliveryFilter{Status: query.Get("status")}
+	var validationErrors []model.FieldError
+	if limit := query.Get("limit"); limit != "" {
+		var err error
+		if filter.Limit, err = strconv.Atoi(limit); err != nil {
+			validationErrors = append(validationErrors, fieldError("limit", model.FieldErrorInvalid, "limit must be an integer"))
+		}
+	}
+	validationErrors = append(validationErrors, validateStruct(&filter)...)
+	if len(validationErrors) > 0 {
+		h.log(req).Warnf("ListWebhookDeliveriesHandler: Validation failed - %s", fieldErrorMessages(validationErrors))
+		h.sendValidationErrorResponse(w, req, validationErrors)
+		return
+	}
+
+	deliveries, err := h.service.ListWebhookDeliveriesService(req.Context(), id, filter)
+	if err != nil {
+		h.sendWebhookError(w, req, "ListWebhookDeliveriesHandler", id, err, "Failed to fetch webhook deliveries", "FETCH_ERROR")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+func (h *handler) webhookIDFromPath(w http.ResponseWriter, req *http.Request, handlerName string) (int, bool) {
+	id, err := h.extractIDFromPath(req)
+	if err != nil {
+		h.log(req).Warnf("%s: Invalid ID parameter - %v", handlerName, err)
+		h.sendErrorResponse(w, req, http.StatusBadRequest,
+			"Invalid ID parameter",
+			err.Error(),
+			"INVALID_ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// sendWebhookError answers a failed webhook lookup with 404 Not Found when the subscription does
+// not exist, and with 500 Internal Server Error otherwise.
+func (h *handler) sendWebhookError(w http.ResponseWriter, req *http.Request, handlerName string, id int, err error, title, code string) {
+	h.log(req).Errorf("%s: Service error for ID %d - %v", handlerName, id, err)
+	if strings.Contains(err.Error(), "not found") {
+		h.sendErrorResponse(w, req, http.StatusNotFound,
+			"Webhook not found",
+			fmt.Sprintf("No webhook exists with ID %d", id),
+			"NOT_FOUND")
+		return
+	}
+
+	h.sendErrorResponse(w, req, http.StatusInternalServerError,
+		title,
+		"An error occurred while accessing webhooks in database",
+		code)
+}
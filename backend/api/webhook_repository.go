@@ -0,0 +1,319 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"timetracker/api/model"
+	"timetracker/errorutil"
+
+	"github.com/lib/pq"
+)
+
+const webhookColumns = `id, url, event_types, created_by, created_at`
+
+func scanWebhook(row interface{ Scan(...any) error }, webhook *model.WebhookSubscription) error {
+	return row.Scan(&webhook.ID, &webhook.URL, (*pq.StringArray)(&webhook.EventTypes), &webhook.CreatedBy, &webhook.CreatedAt)
+}
+
+const webhookDeliveryColumns = `id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, last_error, delivered_at, created_at`
+
+func scanWebhookDelivery(row interface{ Scan(...any) error }, delivery *model.WebhookDelivery, extra ...any) error {
+	var nextAttemptAt time.Time
+	dest := []any{&delivery.ID, &delivery.SubscriptionID, &delivery.EventID, &delivery.EventType, &delivery.Payload,
+		&delivery.Status, &delivery.Attempts, &nextAttemptAt, &delivery.LastStatusCode, &delivery.LastError,
+		&delivery.DeliveredAt, &delivery.CreatedAt}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+
+	// Only pending deliveries have another attempt ahead.
+	if delivery.Status == model.WebhookDeliveryPending {
+		delivery.NextAttemptAt = &nextAttemptAt
+	}
+	return nil
+}
+
+// dueWebhookDelivery is a delivery claimed for sending, with the subscription it goes to.
+type dueWebhookDelivery struct {
+	model.WebhookDelivery
+	URL    string
+	Secret string
+}
+
+func (r *repository) CreateWebhook(ctx context.Context, userID int, req model.CreateWebhookRequest, secret string) (*model.WebhookSubscription, error) {
+	ctx, span := startChildSpan(ctx, "repository.CreateWebhook")
+	defer span.End()
+
+	query := `
+		INSERT INTO webhook_subscriptions (url, event_types, secret, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + webhookColumns
+
+	var webhook model.WebhookSubscription
+	err := scanWebhook(r.db.QueryRowContext(ctx, query, req.URL, pq.StringArray(req.EventTypes), secret, userID), &webhook)
+	if err != nil {
+		return nil, errorutil.Wrap(err, "Failed to create webhook")
+	}
+
+	r.logger.Infof("Created webhook with ID: %d", webhook.ID)
+	return &webhook, nil
+}
+
+func (r *repository) ListWebhooks(ctx context.Context) ([]model.WebhookSubscription, error) {
+	ctx, span := startChildSpan(ctx, "repository.ListWebhooks")
+	defer span.End()
+
+	query := `
+		SELECT ` + webhookColumns + `
+		FROM webhook_subscriptions
+		ORDER BY id ASC`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errorutil.Wrap(err, "Failed to execute query")
+	}
+
+	defer rows.Close()
+
+	webhooks := []model.WebhookSubscription{}
+
+	for rows.Next() {
+		var webhook model.WebhookSubscription
+		if err := scanWebhook(rows, &webhook); err != nil {
+			return nil, errorutil.Wrap(err, "scanning webhook row")
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errorutil.Wrap(err, "iterating webhook rows")
+	}
+
+	return webhooks, nil
+}
+
+func (r *repository) GetWebhook(ctx context.Context, id int) (*model.WebhookSubscription, error) {
+	ctx, span := startChildSpan(ctx, "repository.GetWebhook")
+	defer span.End()
+
+	query := `
+		SELECT ` + webhookColumns + `
+		FROM webhook_subscriptions
+		WHERE id = $1`
+
+	var webhook model.WebhookSubscription
+	err := scanWebhook(r.db.QueryRowContext(ctx, query, id), &webhook)
+
+	if err == sql.ErrNoRows {
+		return nil, errorutil.New("webhook not found")
+	}
+	if err != nil {
+		return nil, errorutil.Wrap(err, "Failed to get webhook")
+	}
+
+	return &webhook, nil
+}
+
+// DeleteWebhook removes the subscription together with its queued deliveries and their log.
+func (r *repository) DeleteWebhook(ctx context.Context, id int) error {
+	ctx, span := startChildSpan(ctx, "repository.DeleteWebhook")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return errorutil.Wrap(err, "Failed to delete webhook")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errorutil.Wrap(err, "Failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errorutil.New("webhook not found")
+	}
+
+	r.logger.Infof("Deleted webhook with ID: %d", id)
+	return nil
+}
+
+// ListWebhookDeliveries returns the deliveries of a subscription matching filter with their
+// attempts, newest first.
+func (r *repository) ListWebhookDeliveries(ctx context.Context, subscriptionID int, filter model.WebhookDeliveryFilter) ([]model.WebhookDelivery, error) {
+	ctx, span := startChildSpan(ctx, "repository.ListWebhookDeliveries")
+	defer span.End()
+
+	conditions := []string{"subscription_id = $1"}
+	args := []interface{}{subscriptionID}
+
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+	args = append(args, filter.Limit)
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM webhook_deliveries
+		WHERE %s
+		ORDER BY id DESC
+		LIMIT $%d`,
+		webhookDeliveryColumns, strings.Join(conditions, " AND "), len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errorutil.Wrap(err, "Failed to execute query")
+	}
+
+	defer rows.Close()
+
+	deliveries := []model.WebhookDelivery{}
+	index := map[int64]int{}
+	ids := pq.Int64Array{}
+
+	for rows.Next() {
+		delivery := model.WebhookDelivery{History: []model.WebhookDeliveryAttempt{}}
+		if err := scanWebhookDelivery(rows, &delivery); err != nil {
+			return nil, errorutil.Wrap(err, "scanning webhook delivery row")
+		}
+		index[delivery.ID] = len(deliveries)
+		ids = append(ids, delivery.ID)
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errorutil.Wrap(err, "iterating webhook delivery rows")
+	}
+	if len(deliveries) == 0 {
+		return deliveries, nil
+	}
+
+	attemptRows, err := r.db.QueryContext(ctx, `
+		SELECT delivery_id, attempt, status_code, error, duration_ms, attempted_at
+		FROM webhook_delivery_attempts
+		WHERE delivery_id = ANY($1)
+		ORDER BY delivery_id, attempt`, ids)
+	if err != nil {
+		return nil, errorutil.Wrap(err, "Failed to execute query")
+	}
+
+	defer attemptRows.Close()
+
+	for attemptRows.Next() {
+		var deliveryID int64
+		var attempt model.WebhookDeliveryAttempt
+		if err := attemptRows.Scan(&deliveryID, &attempt.Attempt, &attempt.StatusCode, &attempt.Error,
+			&attempt.DurationMS, &attempt.AttemptedAt); err != nil {
+			return nil, errorutil.Wrap(err, "scanning webhook delivery attempt row")
+		}
+		delivery := &deliveries[index[deliveryID]]
+		delivery.History = append(delivery.History, attempt)
+	}
+	if err := attemptRows.Err(); err != nil {
+		return nil, errorutil.Wrap(err, "iterating webhook delivery attempt rows")
+	}
+
+	return deliveries, nil
+}
+
+// ClaimWebhookDeliveries picks up to limit pending deliveries that are due and leases them for
+// lease by moving their next attempt. Instances running side by side skip the rows claimed by each
+// other, and a delivery whose sender died is picked up again once its lease ran out.
+func (r *repository) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]dueWebhookDelivery, error) {
+	ctx, span := startChildSpan(ctx, "repository.ClaimWebhookDeliveries")
+	defer span.End()
+
+	query := `
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2)
+		FROM webhook_subscriptions s
+		WHERE s.id = d.subscription_id
+		  AND d.id IN (
+			SELECT id
+			FROM webhook_deliveries
+			WHERE status = 'pending'
+			  AND next_attempt_at <= CURRENT_TIMESTAMP
+			ORDER BY next_attempt_at, id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED)
+		RETURNING d.` + strings.ReplaceAll(webhookDeliveryColumns, ", ", ", d.") + `, s.url, s.secret`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, errorutil.Wrap(err, "Failed to claim webhook deliveries")
+	}
+
+	defer rows.Close()
+
+	var deliveries []dueWebhookDelivery
+
+	for rows.Next() {
+		var delivery dueWebhookDelivery
+		if err := scanWebhookDelivery(rows, &delivery.WebhookDelivery, &delivery.URL, &delivery.Secret); err != nil {
+			return nil, errorutil.Wrap(err, "scanning webhook delivery row")
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errorutil.Wrap(err, "iterating webhook delivery rows")
+	}
+
+	return deliveries, nil
+}
+
+// RecordWebhookAttempt logs an attempt of a delivery and moves the delivery to status. A pending
+// delivery is sent again after retryAfter.
+func (r *repository) RecordWebhookAttempt(ctx context.Context, deliveryID int64, attempt model.WebhookDeliveryAttempt, status string, retryAfter time.Duration) error {
+	ctx, span := startChildSpan(ctx, "repository.RecordWebhookAttempt")
+	defer span.End()
+
+	return r.InTx(ctx, func(tx *repository) error {
+		_, err := tx.db.ExecContext(ctx, `
+			INSERT INTO webhook_delivery_attempts (delivery_id, attempt, status_code, error, duration_ms, attempted_at)
+			VALUES ($1, $2, $3, $4, $5, $6)`,
+			deliveryID, attempt.Attempt, attempt.StatusCode, attempt.Error, attempt.DurationMS, attempt.AttemptedAt)
+		if err != nil {
+			return errorutil.Wrap(err, "Failed to log webhook delivery attempt")
+		}
+
+		_, err = tx.db.ExecContext(ctx, `
+			UPDATE webhook_deliveries
+			SET status = $2,
+			    attempts = $3,
+			    next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $4),
+			    last_status_code = $5,
+			    last_error = $6,
+			    delivered_at = CASE WHEN $2 = 'delivered' THEN CURRENT_TIMESTAMP END
+			WHERE id = $1`,
+			deliveryID, status, attempt.Attempt, retryAfter.Seconds(), attempt.StatusCode, attempt.Error)
+		if err != nil {
+			return errorutil.Wrap(err, "Failed to update webhook delivery")
+		}
+		return nil
+	})
+}
+
+// DeleteWebhookDeliveriesBefore prunes the delivered and failed deliveries created before cutoff.
+func (r *repository) DeleteWebhookDeliveriesBefore(ctx context.Context, cutoff time.Time) error {
+	ctx, span := startChildSpan(ctx, "repository.DeleteWebhookDeliveriesBefore")
+	defer span.End()
+
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM webhook_deliveries
+		WHERE status <> 'pending'
+		  AND created_at < $1`, cutoff)
+	if err != nil {
+		return errorutil.Wrap(err, "Failed to delete webhook deliveries")
+	}
+
+	if rowsAffected, err := result.RowsAffected(); err == nil && rowsAffected > 0 {
+		r.logger.Infof("Pruned %d webhook deliveries older than %s", rowsAffected, cutoff.Format(time.RFC3339))
+	}
+	return nil
+}
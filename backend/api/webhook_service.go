@@ -0,0 +1,339 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	mathrand "math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"timetracker/api/model"
+	"timetracker/errorutil"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	webhookEventHeader     = "X-Timetracker-Event"
+	webhookDeliveryHeader  = "X-Timetracker-Delivery"
+	webhookTimestampHeader = "X-Timetracker-Timestamp"
+	webhookSignatureHeader = "X-Timetracker-Signature"
+	// webhookErrorBodyLimit is how much of an error response is kept in the delivery log.
+	webhookErrorBodyLimit = 512
+	webhookDeliveryLimit  = 50
+)
+
+// newWebhookClient does not follow redirects, a receiver has to answer at the registered URL.
+func newWebhookClient(timeoutSeconds int) *http.Client {
+	timeout := time.Duration(timeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func (s *service) CreateWebhookService(ctx context.Context, user *model.User, req model.CreateWebhookRequest) (*model.WebhookSubscription, error) {
+	ctx, span := tracer.Start(ctx, "service.CreateWebhookService")
+	defer span.End()
+
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		if secret, err = newWebhookSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	webhook, err := s.repo.CreateWebhook(ctx, user.ID, req, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	// The secret is only shown once, like a password.
+	webhook.Secret = secret
+	return webhook, nil
+}
+
+func (s *service) ListWebhooksService(ctx context.Context) ([]model.WebhookSubscription, error) {
+	ctx, span := tracer.Start(ctx, "service.ListWebhooksService")
+	defer span.End()
+
+	return s.repo.ListWebhooks(ctx)
+}
+
+func (s *service) GetWebhookService(ctx context.Context, id int) (*model.WebhookSubscription, error) {
+	ctx, span := tracer.Start(ctx, "service.GetWebhookService")
+	defer span.End()
+
+	return s.repo.GetWebhook(ctx, id)
+}
+
+func (s *service) DeleteWebhookService(ctx context.Context, id int) error {
+	ctx, span := tracer.Start(ctx, "service.DeleteWebhookService")
+	defer span.End()
+
+	return s.repo.DeleteWebhook(ctx, id)
+}
+
+// ListWebhookDeliveriesService returns the delivery log of a subscription, newest first.
+func (s *service) ListWebhookDeliveriesService(ctx context.Context, id int, filter model.WebhookDeliveryFilter) ([]model.WebhookDelivery, error) {
+	ctx, span := tracer.Start(ctx, "service.ListWebhookDeliveriesService")
+	defer span.End()
+
+	if _, err := s.repo.GetWebhook(ctx, id); err != nil {
+		return nil, err
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = webhookDeliveryLimit
+	}
+	return s.repo.ListWebhookDeliveries(ctx, id, filter)
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", errorutil.Wrap(err, "Failed to generate webhook secret")
+	}
+	return "whsec_" + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// webhookSignature signs a delivery as sha256=<hex>, the HMAC-SHA256 with the subscription secret
+// of the timestamp header, a dot and the body. Receivers recompute it to check that a delivery is
+// authentic, and reject old timestamps to stop replays.
+func webhookSignature(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// DispatchWebhooks sends the queued deliveries until ctx is done. It also prunes the delivery log
+// older than the configured retention.
+func (s *service) DispatchWebhooks(ctx context.Context) {
+	interval := time.Duration(s.cfg.Webhooks.PollIntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	retention := time.Duration(s.cfg.Webhooks.RetentionHours) * time.Hour
+	if retention <= 0 {
+		retention = 30 * 24 * time.Hour
+	}
+	s.pruneWebhookDeliveries(ctx, retention)
+
+	pollTicker := time.NewTicker(interval)
+	defer pollTicker.Stop()
+	pruneTicker := time.NewTicker(time.Hour)
+	defer pruneTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-pollTicker.C:
+			s.sendDueWebhooks(ctx)
+
+		case <-pruneTicker.C:
+			s.pruneWebhookDeliveries(ctx, retention)
+		}
+	}
+}
+
+// sendDueWebhooks sends the deliveries that are due, a batch at a time, and returns how many
+// it sent. The deliveries of a batch are sent concurrently, so one slow receiver does not hold up
+// the others for more than the timeout.
+func (s *service) sendDueWebhooks(ctx context.Context) int {
+	batchSize := s.cfg.Webhooks.BatchSize
+	if batchSize <= 0 {
+		batchSize = 20
+	}
+	// The lease outlasts the timeout of every request of the batch, so no delivery is sent twice.
+	lease := 2*s.webhookClient.Timeout + time.Minute
+
+	sent := 0
+	for ctx.Err() == nil {
+		deliveries, err := s.repo.ClaimWebhookDeliveries(ctx, batchSize, lease)
+		if err != nil {
+			s.repo.logger.Errorf("sendDueWebhooks: %v", err)
+			return sent
+		}
+
+		var wg sync.WaitGroup
+		for _, delivery := range deliveries {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.deliverWebhook(ctx, delivery)
+			}()
+		}
+		wg.Wait()
+
+		sent += len(deliveries)
+		if len(deliveries) < batchSize {
+			break
+		}
+	}
+	return sent
+}
+
+// deliverWebhook sends a delivery once and records the attempt. A failed delivery is retried with
+// exponential backoff until the configured number of attempts is reached. Every attempt is traced
+// on its own.
+func (s *service) deliverWebhook(ctx context.Context, delivery dueWebhookDelivery) {
+	ctx, span := tracer.Start(ctx, "service.deliverWebhook", trace.WithAttributes(
+		attribute.Int64("webhook.delivery_id", delivery.ID),
+		attribute.Int("webhook.subscription_id", delivery.SubscriptionID),
+		attribute.String("webhook.event_type", delivery.EventType),
+	))
+	defer span.End()
+
+	logger := s.repo.logger
+	attempt := s.sendWebhook(ctx, delivery)
+	if attempt.Error != nil {
+		span.SetStatus(codes.Error, *attempt.Error)
+	}
+
+	// Shutting down is no failure of the receiver, the delivery is sent again once its lease ran out.
+	if ctx.Err() != nil {
+		logger.Warnf("deliverWebhook: Delivery %d interrupted by shutdown", delivery.ID)
+		return
+	}
+
+	maxAttempts := s.cfg.Webhooks.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 10
+	}
+
+	status := model.WebhookDeliveryDelivered
+	var retryAfter time.Duration
+	switch {
+	case attempt.Error == nil:
+		logger.Infof("deliverWebhook: Delivered %s %d to webhook %d", delivery.EventType, delivery.ID, delivery.SubscriptionID)
+	case attempt.Attempt >= maxAttempts:
+		status = model.WebhookDeliveryFailed
+		logger.Errorf("deliverWebhook: Giving up on delivery %d to webhook %d after %d attempts - %s",
+			delivery.ID, delivery.SubscriptionID, attempt.Attempt, *attempt.Error)
+	default:
+		status = model.WebhookDeliveryPending
+		retryAfter = s.webhookBackoff(attempt.Attempt)
+		logger.Warnf("deliverWebhook: Attempt %d of delivery %d to webhook %d failed, retrying in %s - %s",
+			attempt.Attempt, delivery.ID, delivery.SubscriptionID, retryAfter.Round(time.Second), *attempt.Error)
+	}
+
+	if err := s.repo.RecordWebhookAttempt(ctx, delivery.ID, attempt, status, retryAfter); err != nil {
+		logger.Errorf("deliverWebhook: Failed to record attempt of delivery %d - %v", delivery.ID, err)
+	}
+}
+
+// sendWebhook posts a delivery to its subscription. Any answer but a 2xx status fails the attempt.
+func (s *service) sendWebhook(ctx context.Context, delivery dueWebhookDelivery) model.WebhookDeliveryAttempt {
+	attempt := model.WebhookDeliveryAttempt{
+		Attempt:     delivery.Attempts + 1,
+		AttemptedAt: time.Now(),
+	}
+	fail := func(format string, args ...any) model.WebhookDeliveryAttempt {
+		msg := fmt.Sprintf(format, args...)
+		attempt.Error = &msg
+		attempt.DurationMS = int(time.Since(attempt.AttemptedAt).Milliseconds())
+		return attempt
+	}
+
+	body, err := json.Marshal(model.WebhookPayload{
+		EventID:    delivery.EventID,
+		Type:       delivery.EventType,
+		OccurredAt: delivery.CreatedAt,
+		Data:       delivery.Payload,
+	})
+	if err != nil {
+		return fail("encoding payload: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return fail("building request: %v", err)
+	}
+
+	timestamp := attempt.AttemptedAt.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "timetracker-webhooks")
+	req.Header.Set(webhookEventHeader, delivery.EventType)
+	req.Header.Set(webhookDeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(webhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(webhookSignatureHeader, webhookSignature(delivery.Secret, timestamp, body))
+	// Receivers that trace as well continue the trace of the delivery.
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := s.webhookClient.Do(req)
+	if err != nil {
+		return fail("%v", err)
+	}
+	defer resp.Body.Close()
+
+	attempt.StatusCode = &resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, webhookErrorBodyLimit))
+		if text := strings.TrimSpace(string(snippet)); text != "" {
+			return fail("receiver answered %d: %s", resp.StatusCode, text)
+		}
+		return fail("receiver answered %d", resp.StatusCode)
+	}
+
+	// Reading the rest lets the connection be reused.
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	attempt.DurationMS = int(time.Since(attempt.AttemptedAt).Milliseconds())
+	return attempt
+}
+
+// webhookBackoff is the delay after the failed attempt: the initial backoff doubled per earlier
+// attempt and capped at the maximum, plus up to 10% jitter so retries of many deliveries to a
+// receiver that comes back do not arrive all at once.
+func (s *service) webhookBackoff(attempt int) time.Duration {
+	initial := time.Duration(s.cfg.Webhooks.InitialBackoffSeconds) * time.Second
+	if initial <= 0 {
+		initial = 30 * time.Second
+	}
+	maxBackoff := time.Duration(s.cfg.Webhooks.MaxBackoffSeconds) * time.Second
+	if maxBackoff <= 0 {
+		maxBackoff = 6 * time.Hour
+	}
+
+	delay := initial
+	for i := 1; i < attempt && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, maxBackoff)
+
+	return delay + mathrand.N(delay/10+1)
+}
+
+func (s *service) pruneWebhookDeliveries(ctx context.Context, retention time.Duration) {
+	if err := s.repo.DeleteWebhookDeliveriesBefore(ctx, time.Now().Add(-retention)); err != nil {
+		s.repo.logger.Errorf("pruneWebhookDeliveries: %v", err)
+	}
+}
@@ -0,0 +1,366 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"timetracker/api/model"
+	"timetracker/internal/config"
+	"timetracker/logger"
+)
+
+const testWebhookSecret = "webhook-test-secret"
+
+// webhookReceiver is a local endpoint that checks the signature of every delivery it receives and
+// answers with the next of its statuses, 204 No Content once they are used up.
+type webhookReceiver struct {
+	*httptest.Server
+	t *testing.T
+
+	mu         sync.Mutex
+	statuses   []int
+	deliveries []receivedWebhook
+}
+
+type receivedWebhook struct {
+	header  http.Header
+	payload model.WebhookPayload
+}
+
+func newWebhookReceiver(t *testing.T, statuses ...int) *webhookReceiver {
+	t.Helper()
+
+	receiver := &webhookReceiver{t: t, statuses: statuses}
+	receiver.Server = httptest.NewServer(http.HandlerFunc(receiver.serve))
+	t.Cleanup(receiver.Close)
+	return receiver
+}
+
+func (rcv *webhookReceiver) serve(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		rcv.t.Errorf("Failed to read delivery: %v", err)
+	}
+
+	// Verified the way the README tells receivers to.
+	mac := hmac.New(sha256.New, []byte(testWebhookSecret))
+	mac.Write([]byte(req.Header.Get(webhookTimestampHeader) + "."))
+	mac.Write(body)
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got := req.Header.Get(webhookSignatureHeader); !hmac.Equal([]byte(got), []byte(want)) {
+		rcv.t.Errorf("Signature %q, want %q", got, want)
+	}
+
+	timestamp, err := strconv.ParseInt(req.Header.Get(webhookTimestampHeader), 10, 64)
+	if err != nil || time.Since(time.Unix(timestamp, 0)).Abs() > time.Minute {
+		rcv.t.Errorf("Timestamp %q is not the current time", req.Header.Get(webhookTimestampHeader))
+	}
+
+	var payload model.WebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		rcv.t.Errorf("Delivery is no webhook payload: %v\n%s", err, body)
+	}
+
+	rcv.mu.Lock()
+	rcv.deliveries = append(rcv.deliveries, receivedWebhook{header: req.Header.Clone(), payload: payload})
+	status := http.StatusNoContent
+	if len(rcv.statuses) > 0 {
+		status, rcv.statuses = rcv.statuses[0], rcv.statuses[1:]
+	}
+	rcv.mu.Unlock()
+
+	w.WriteHeader(status)
+	if status >= 300 {
+		w.Write([]byte("receiver unavailable"))
+	}
+}
+
+func (rcv *webhookReceiver) received() []receivedWebhook {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	return append([]receivedWebhook(nil), rcv.deliveries...)
+}
+
+// newWebhookTestService returns a service on the test database, withDB is false when
+// TEST_DATABASE_URL is not set.
+func newWebhookTestService(t *testing.T, cfg config.WebhooksConfig) (s *service, withDB bool) {
+	log := logger.NewLogger("webhook", filepath.Join(t.TempDir(), "api.log"))
+	database, withDB := openTestDatabase(t)
+	return Service(Repository(database, log), &config.Config{Webhooks: cfg}), withDB
+}
+
+func testWebhookDelivery(url string) dueWebhookDelivery {
+	return dueWebhookDelivery{
+		WebhookDelivery: model.WebhookDelivery{
+			ID:             7,
+			SubscriptionID: 3,
+			EventID:        42,
+			EventType:      model.WebhookEventTrackerStopped,
+			Payload:        json.RawMessage(`{"id":5,"task":"Write tests","end_time":"2025-10-11T10:00:00.000000Z"}`),
+			Status:         model.WebhookDeliveryPending,
+			Attempts:       2,
+			CreatedAt:      time.Date(2025, 10, 11, 10, 0, 0, 0, time.UTC),
+		},
+		URL:    url,
+		Secret: testWebhookSecret,
+	}
+}
+
+func TestSendWebhook(t *testing.T) {
+	receiver := newWebhookReceiver(t)
+	s, _ := newWebhookTestService(t, config.WebhooksConfig{})
+
+	attempt := s.sendWebhook(context.Background(), testWebhookDelivery(receiver.URL))
+
+	if attempt.Error != nil {
+		t.Fatalf("Attempt failed: %s", *attempt.Error)
+	}
+	if attempt.Attempt != 3 {
+		t.Errorf("Attempt %d, want 3", attempt.Attempt)
+	}
+	if attempt.StatusCode == nil || *attempt.StatusCode != http.StatusNoContent {
+		t.Errorf("Status code %v, want 204", attempt.StatusCode)
+	}
+
+	received := receiver.received()
+	if len(received) != 1 {
+		t.Fatalf("Receiver got %d deliveries, want 1", len(received))
+	}
+	got := received[0]
+	if event := got.header.Get(webhookEventHeader); event != model.WebhookEventTrackerStopped {
+		t.Errorf("Event header %q, want %q", event, model.WebhookEventTrackerStopped)
+	}
+	if id := got.header.Get(webhookDeliveryHeader); id != "7" {
+		t.Errorf("Delivery header %q, want 7", id)
+	}
+	if contentType := got.header.Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("Content-Type %q, want application/json", contentType)
+	}
+	if got.payload.EventID != 42 || got.payload.Type != model.WebhookEventTrackerStopped ||
+		!got.payload.OccurredAt.Equal(time.Date(2025, 10, 11, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Payload %+v does not describe the event", got.payload)
+	}
+	if !strings.Contains(string(got.payload.Data), `"task":"Write tests"`) {
+		t.Errorf("Payload data %s does not hold the tracker", got.payload.Data)
+	}
+}
+
+func TestSendWebhookFailures(t *testing.T) {
+	redirect := httptest.NewServer(http.RedirectHandler("http://127.0.0.1:1/elsewhere", http.StatusFound))
+	defer redirect.Close()
+
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-release
+	}))
+	defer slow.Close()
+	defer close(release)
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	tests := []struct {
+		name   string
+		url    string
+		status int
+		error  string
+	}{
+		{name: "error status", url: newWebhookReceiver(t, http.StatusServiceUnavailable).URL,
+			status: http.StatusServiceUnavailable, error: "receiver answered 503: receiver unavailable"},
+		{name: "redirect is not followed", url: redirect.URL, status: http.StatusFound, error: "receiver answered 302"},
+		{name: "timeout", url: slow.URL, error: "Timeout"},
+		{name: "connection refused", url: closed.URL, error: "refused"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newWebhookTestService(t, config.WebhooksConfig{})
+			s.webhookClient.Timeout = 100 * time.Millisecond
+
+			attempt := s.sendWebhook(context.Background(), testWebhookDelivery(tt.url))
+
+			if attempt.Error == nil || !strings.Contains(*attempt.Error, tt.error) {
+				t.Errorf("Error %v, want one containing %q", attempt.Error, tt.error)
+			}
+			if tt.status == 0 && attempt.StatusCode != nil {
+				t.Errorf("Status code %d without a response", *attempt.StatusCode)
+			}
+			if tt.status != 0 && (attempt.StatusCode == nil || *attempt.StatusCode != tt.status) {
+				t.Errorf("Status code %v, want %d", attempt.StatusCode, tt.status)
+			}
+		})
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	s, _ := newWebhookTestService(t, config.WebhooksConfig{InitialBackoffSeconds: 30, MaxBackoffSeconds: 300})
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, 60 * time.Second},
+		{3, 120 * time.Second},
+		{4, 240 * time.Second},
+		{5, 300 * time.Second},
+		{50, 300 * time.Second},
+	}
+
+	for _, tt := range tests {
+		for range 20 {
+			got := s.webhookBackoff(tt.attempt)
+			if got < tt.want || got > tt.want+tt.want/10 {
+				t.Errorf("Backoff after attempt %d is %s, want %s plus up to 10%%", tt.attempt, got, tt.want)
+			}
+		}
+	}
+}
+
+// TestWebhookDeliveries stops a timer and follows the queued delivery through a failed attempt,
+// the retry and the delivery log. It needs TEST_DATABASE_URL.
+func TestWebhookDeliveries(t *testing.T) {
+	receiver := newWebhookReceiver(t, http.StatusServiceUnavailable)
+	s, withDB := newWebhookTestService(t, config.WebhooksConfig{MaxAttempts: 3, InitialBackoffSeconds: 60})
+	if !withDB {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+	suffix := time.Now().UnixNano()
+	admin, err := s.repo.UpsertOIDCUser(ctx, model.OIDCIdentity{
+		Issuer:  "webhook-test",
+		Subject: fmt.Sprint(suffix),
+		Email:   fmt.Sprintf("webhook-%d@example.com", suffix),
+		Role:    model.RoleAdmin,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create admin: %v", err)
+	}
+
+	webhook, err := s.repo.CreateWebhook(ctx, admin.ID, model.CreateWebhookRequest{
+		URL:        receiver.URL,
+		EventTypes: []string{model.WebhookEventTrackerStopped},
+	}, testWebhookSecret)
+	if err != nil {
+		t.Fatalf("Failed to create webhook: %v", err)
+	}
+	t.Cleanup(func() { s.repo.DeleteWebhook(ctx, webhook.ID) })
+
+	deliveries := func() []model.WebhookDelivery {
+		t.Helper()
+		list, err := s.ListWebhookDeliveriesService(ctx, webhook.ID, model.WebhookDeliveryFilter{})
+		if err != nil {
+			t.Fatalf("Failed to list deliveries: %v", err)
+		}
+		return list
+	}
+	stop := func(repo *repository, id int) error {
+		_, err := repo.UpdateTracker(ctx, id, model.TrackerPatch{
+			EndTime: model.NullableValue(time.Now()),
+		}, nil)
+		return err
+	}
+
+	tracker, err := s.repo.CreateTracker(ctx, model.CreateTrackerRequest{Task: "Webhook test", StartTime: time.Now().Add(-time.Hour)})
+	if err != nil {
+		t.Fatalf("Failed to create tracker: %v", err)
+	}
+	if list := deliveries(); len(list) != 0 {
+		t.Fatalf("Starting a timer queued %d deliveries for tracker.stopped", len(list))
+	}
+
+	// A rolled back write must not leave a delivery behind.
+	errRollback := errors.New("rollback")
+	err = s.repo.InTx(ctx, func(tx *repository) error {
+		if err := stop(tx, tracker.ID); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("Rolled back stop failed: %v", err)
+	}
+	if list := deliveries(); len(list) != 0 {
+		t.Fatalf("A rolled back stop queued %d deliveries", len(list))
+	}
+
+	if err := stop(s.repo, tracker.ID); err != nil {
+		t.Fatalf("Failed to stop tracker: %v", err)
+	}
+
+	// The receiver fails the first attempt, the delivery waits for its retry.
+	s.sendDueWebhooks(ctx)
+	list := deliveries()
+	if len(list) != 1 {
+		t.Fatalf("Stopping the timer queued %d deliveries, want 1", len(list))
+	}
+	delivery := list[0]
+	if delivery.Status != model.WebhookDeliveryPending || delivery.Attempts != 1 || len(delivery.History) != 1 {
+		t.Fatalf("After a failed attempt the delivery is %s with %d attempts and %d log entries",
+			delivery.Status, delivery.Attempts, len(delivery.History))
+	}
+	if code := delivery.History[0].StatusCode; code == nil || *code != http.StatusServiceUnavailable {
+		t.Errorf("Logged status code %v, want 503", code)
+	}
+	if delivery.NextAttemptAt == nil || time.Until(*delivery.NextAttemptAt) < 30*time.Second {
+		t.Errorf("Next attempt at %v, want about a minute from now", delivery.NextAttemptAt)
+	}
+
+	// Not due yet.
+	s.sendDueWebhooks(ctx)
+	if got := len(receiver.received()); got != 1 {
+		t.Fatalf("Receiver got %d deliveries before the retry was due, want 1", got)
+	}
+
+	if _, err := s.repo.db.ExecContext(ctx, `UPDATE webhook_deliveries SET next_attempt_at = CURRENT_TIMESTAMP WHERE id = $1`, delivery.ID); err != nil {
+		t.Fatalf("Failed to make the retry due: %v", err)
+	}
+	s.sendDueWebhooks(ctx)
+
+	delivery = deliveries()[0]
+	if delivery.Status != model.WebhookDeliveryDelivered || delivery.Attempts != 2 || delivery.DeliveredAt == nil {
+		t.Fatalf("After the retry the delivery is %s with %d attempts", delivery.Status, delivery.Attempts)
+	}
+	if len(delivery.History) != 2 || delivery.History[1].Error != nil {
+		t.Errorf("Delivery log %+v, want a failed and a successful attempt", delivery.History)
+	}
+
+	received := receiver.received()
+	if len(received) != 2 {
+		t.Fatalf("Receiver got %d deliveries, want 2", len(received))
+	}
+	for _, got := range received {
+		var data model.Tracker
+		if err := json.Unmarshal(got.payload.Data, &data); err != nil || data.ID != tracker.ID || data.EndTime == nil {
+			t.Errorf("Payload data %s is not the stopped tracker %d", got.payload.Data, tracker.ID)
+		}
+		if got.header.Get(webhookDeliveryHeader) != strconv.FormatInt(delivery.ID, 10) {
+			t.Errorf("Delivery header %q, want %d", got.header.Get(webhookDeliveryHeader), delivery.ID)
+		}
+	}
+
+	failed, err := s.ListWebhookDeliveriesService(ctx, webhook.ID, model.WebhookDeliveryFilter{Status: model.WebhookDeliveryFailed})
+	if err != nil || len(failed) != 0 {
+		t.Errorf("Filtering by failed returned %d deliveries, %v", len(failed), err)
+	}
+}
@@ -8,12 +8,17 @@ package db
 
 import (
 	"database/sql"
+	"time"
+	"timetracker/errorutil"
 	"timetracker/internal/config"
 	"timetracker/logger"
+
+	"github.com/lib/pq"
 )
 
 type initDB struct {
-	db *sql.DB
+	db      *sql.DB
+	connStr string
 }
 
 func Init(logger *logger.Logger) *initDB {
@@ -43,13 +48,36 @@ func Init(logger *logger.Logger) *initDB {
 
 	logger.Infof("Database connection initialized and ready.\n")
 
-	return &initDB{db: pg.GetDB()}
+	return &initDB{db: pg.GetDB(), connStr: pg.createConnStr()}
 }
 
 func (i *initDB) GetDB() *sql.DB {
 	return i.db
 }
 
+// Listen opens a dedicated connection that receives the NOTIFY messages sent on channel.
+// The listener reconnects by itself and signals a reconnect with a nil notification.
+func (i *initDB) Listen(channel string, logger *logger.Logger) (*pq.Listener, error) {
+	listener := pq.NewListener(i.connStr, 10*time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		switch event {
+		case pq.ListenerEventDisconnected:
+			logger.Warnf("Listener for %s disconnected: %v", channel, err)
+		case pq.ListenerEventReconnected:
+			logger.Infof("Listener for %s reconnected", channel)
+		case pq.ListenerEventConnectionAttemptFailed:
+			logger.Errorf("Listener for %s failed to connect: %v", channel, err)
+		}
+	})
+
+	if err := listener.Listen(channel); err != nil {
+		listener.Close()
+		return nil, errorutil.Wrap(err, "failed to listen on "+channel)
+	}
+
+	logger.Infof("Listening for notifications on %s", channel)
+	return listener, nil
+}
+
 func (i *initDB) CloseDB() {
 	if i.db != nil {
 		i.db.Close()
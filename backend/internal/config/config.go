@@ -13,6 +13,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"unicode"
 )
 
 //go:embed config.json
@@ -27,6 +28,189 @@ type Config struct {
 	Password   string `json:"password"`
 	SchemaName string `json:"schema_name"`
 	AppPort    string `json:"app_port"`
+
+	Log         LogConfig         `json:"log" env:"LOG"`
+	Server      ServerConfig      `json:"server" env:"SERVER"`
+	RateLimit   RateLimitConfig   `json:"rate_limit" env:"RATE_LIMIT"`
+	CORS        CORSConfig        `json:"cors" env:"CORS"`
+	Auth        AuthConfig        `json:"auth" env:"AUTH"`
+	OIDC        OIDCConfig        `json:"oidc" env:"OIDC"`
+	Share       ShareConfig       `json:"share" env:"SHARE"`
+	Idempotency IdempotencyConfig `json:"idempotency" env:"IDEMPOTENCY"`
+	Events      EventsConfig      `json:"events" env:"EVENTS"`
+	WebSocket   WebSocketConfig   `json:"websocket" env:"WEBSOCKET"`
+	GRPC        GRPCConfig        `json:"grpc" env:"GRPC"`
+	Webhooks    WebhooksConfig    `json:"webhooks" env:"WEBHOOKS"`
+	Metrics     MetricsConfig     `json:"metrics" env:"METRICS"`
+	Tracing     TracingConfig     `json:"tracing" env:"TRACING"`
+
+	LegacyRoutes LegacyRoutesConfig `json:"legacy_routes" env:"LEGACY_ROUTES"`
+}
+
+// LogConfig sets up the logs of the api and migrate binaries. Level is the minimum level written
+// (debug, info, warn or error) and Format text or json. Records go to stdout when Stdout is set and
+// to <binary>.log in Dir unless Dir is empty. The file is rotated before it grows past MaxSizeMB;
+// the newest MaxBackups rotated files younger than MaxAgeDays are kept, gzipped with Compress.
+// Zero turns rotation, or the respective limit, off.
+type LogConfig struct {
+	Level      string `json:"level"`
+	Format     string `json:"format"`
+	Stdout     bool   `json:"stdout"`
+	Dir        string `json:"dir"`
+	MaxSizeMB  int    `json:"max_size_mb"`
+	MaxBackups int    `json:"max_backups"`
+	MaxAgeDays int    `json:"max_age_days"`
+	Compress   bool   `json:"compress"`
+}
+
+// ServerConfig hardens the HTTP server listening on AppPort. ReadTimeoutSeconds bounds reading a
+// whole request and ReadHeaderTimeoutSeconds its headers, WriteTimeoutSeconds the time until the
+// response is written; the event stream lifts both once it starts. IdleTimeoutSeconds closes idle
+// keep-alive connections. On SIGINT or SIGTERM, requests in flight get ShutdownTimeoutSeconds to
+// finish before the remaining connections are closed.
+type ServerConfig struct {
+	ReadTimeoutSeconds       int `json:"read_timeout_seconds"`
+	ReadHeaderTimeoutSeconds int `json:"read_header_timeout_seconds"`
+	WriteTimeoutSeconds      int `json:"write_timeout_seconds"`
+	IdleTimeoutSeconds       int `json:"idle_timeout_seconds"`
+	MaxHeaderBytes           int `json:"max_header_bytes"`
+	ShutdownTimeoutSeconds   int `json:"shutdown_timeout_seconds"`
+}
+
+// RateLimitConfig holds the token bucket settings applied to every client.
+type RateLimitConfig struct {
+	Enabled            bool    `json:"enabled"`
+	RequestsPerSecond  float64 `json:"requests_per_second"`
+	Burst              int     `json:"burst"`
+	TrustProxyHeaders  bool    `json:"trust_proxy_headers"`
+	IdleTimeoutSeconds int     `json:"idle_timeout_seconds"`
+}
+
+// CORSConfig lists what browser clients such as the web dashboard may send cross-origin.
+// An allowed origin of "*" matches any origin.
+type CORSConfig struct {
+	Enabled          bool     `json:"enabled"`
+	AllowedOrigins   []string `json:"allowed_origins"`
+	AllowedMethods   []string `json:"allowed_methods"`
+	AllowedHeaders   []string `json:"allowed_headers"`
+	ExposedHeaders   []string `json:"exposed_headers"`
+	AllowCredentials bool     `json:"allow_credentials"`
+	MaxAgeSeconds    int      `json:"max_age_seconds"`
+}
+
+// AuthConfig controls the sessions the API issues after a successful login.
+// Users whose email is listed in AdminEmails are provisioned with the admin role.
+type AuthConfig struct {
+	SessionTTLMinutes int      `json:"session_ttl_minutes"`
+	CookieName        string   `json:"cookie_name"`
+	CookieSecure      bool     `json:"cookie_secure"`
+	AdminEmails       []string `json:"admin_emails"`
+	TOTPIssuer        string   `json:"totp_issuer"`
+}
+
+// OIDCConfig registers the API as a client of the company identity provider.
+// When PostLoginRedirectURL is empty the callback answers with the session as JSON.
+type OIDCConfig struct {
+	Enabled              bool     `json:"enabled"`
+	IssuerURL            string   `json:"issuer_url"`
+	ClientID             string   `json:"client_id"`
+	ClientSecret         string   `json:"client_secret"`
+	RedirectURL          string   `json:"redirect_url"`
+	Scopes               []string `json:"scopes"`
+	PostLoginRedirectURL string   `json:"post_login_redirect_url"`
+	AllowedEmailDomains  []string `json:"allowed_email_domains"`
+}
+
+// ShareConfig controls public report links. Links are signed with SigningSecret and
+// cannot be minted while it is empty. BaseURL is the public address links are built on.
+type ShareConfig struct {
+	SigningSecret   string `json:"signing_secret"`
+	BaseURL         string `json:"base_url"`
+	DefaultTTLHours int    `json:"default_ttl_hours"`
+	MaxTTLHours     int    `json:"max_ttl_hours"`
+}
+
+// IdempotencyConfig controls how long responses to requests with an Idempotency-Key are kept
+// for replay. Retries arriving after TTLHours are treated as new requests. A key whose request
+// has not finished after LockTimeoutSeconds, e.g. because the server died while handling it, is
+// taken over by the next retry.
+type IdempotencyConfig struct {
+	Enabled            bool `json:"enabled"`
+	TTLHours           int  `json:"ttl_hours"`
+	LockTimeoutSeconds int  `json:"lock_timeout_seconds"`
+}
+
+// EventsConfig controls the tracker change feed. Changes are kept for RetentionHours, which is
+// how far back a client can resume. Streams send a heartbeat every HeartbeatSeconds so proxies
+// do not close idle connections. An event waits for older running transactions for MaxLagSeconds
+// at most.
+type EventsConfig struct {
+	Enabled          bool `json:"enabled"`
+	HeartbeatSeconds int  `json:"heartbeat_seconds"`
+	RetentionHours   int  `json:"retention_hours"`
+	MaxLagSeconds    int  `json:"max_lag_seconds"`
+}
+
+// WebSocketConfig controls the live timer socket. Clients with a running timer receive a tick
+// every TickSeconds. Connections from browsers are only accepted from the CORS allowed origins.
+type WebSocketConfig struct {
+	Enabled     bool `json:"enabled"`
+	TickSeconds int  `json:"tick_seconds"`
+}
+
+// GRPCConfig controls the gRPC server that runs next to the HTTP server on Port. Reflection
+// lets tools such as grpcurl discover the services without the proto files.
+type GRPCConfig struct {
+	Enabled    bool   `json:"enabled"`
+	Port       string `json:"port"`
+	Reflection bool   `json:"reflection"`
+}
+
+// WebhooksConfig controls the delivery of tracker events to webhook subscriptions. Due deliveries
+// are polled every PollIntervalSeconds, up to BatchSize at a time, and a receiver has
+// TimeoutSeconds to answer. A failed delivery is retried after InitialBackoffSeconds, doubling up
+// to MaxBackoffSeconds, until MaxAttempts attempts failed. Finished deliveries are kept for
+// RetentionHours as delivery log. While disabled, deliveries are still queued but not sent.
+type WebhooksConfig struct {
+	Enabled               bool `json:"enabled"`
+	PollIntervalSeconds   int  `json:"poll_interval_seconds"`
+	BatchSize             int  `json:"batch_size"`
+	TimeoutSeconds        int  `json:"timeout_seconds"`
+	MaxAttempts           int  `json:"max_attempts"`
+	InitialBackoffSeconds int  `json:"initial_backoff_seconds"`
+	MaxBackoffSeconds     int  `json:"max_backoff_seconds"`
+	RetentionHours        int  `json:"retention_hours"`
+}
+
+// MetricsConfig serves the Prometheus metrics on GET /metrics while Enabled, which is off by
+// default. The endpoint is not authenticated and every scrape queries the database, so it belongs
+// behind a proxy that keeps it away from the internet.
+type MetricsConfig struct {
+	Enabled bool `json:"enabled"`
+}
+
+// TracingConfig controls the OpenTelemetry traces of the requests. Exporter is "otlp", which
+// sends the spans to a collector at Endpoint over Protocol "grpc" or "http" (Insecure skips TLS),
+// "stdout" or "file", which writes them as JSON to File for local debugging. A share of
+// SampleRatio of the traces is kept, unless the caller already decided in its traceparent header.
+type TracingConfig struct {
+	Enabled     bool    `json:"enabled"`
+	ServiceName string  `json:"service_name"`
+	Exporter    string  `json:"exporter"`
+	Endpoint    string  `json:"endpoint"`
+	Protocol    string  `json:"protocol"`
+	Insecure    bool    `json:"insecure"`
+	File        string  `json:"file"`
+	SampleRatio float64 `json:"sample_ratio"`
+}
+
+// LegacyRoutesConfig controls the unversioned aliases of the /v1 routes that clients released
+// before versioning still call. They answer with Deprecation and Sunset headers built from
+// DeprecatedAt and SunsetAt, both RFC 3339 timestamps. Disable them once SunsetAt has passed.
+type LegacyRoutesConfig struct {
+	Enabled      bool   `json:"enabled"`
+	DeprecatedAt string `json:"deprecated_at"`
+	SunsetAt     string `json:"sunset_at"`
 }
 
 var cfg *Config
@@ -46,40 +230,86 @@ func LoadConfig() (*Config, error) {
 	}
 
 	// Reflect automatically converts env vars to relevant type of the struct fields
-	v := reflect.ValueOf(&c).Elem()
+	applyEnvOverrides(reflect.ValueOf(&c).Elem(), "DB")
+
+	cfg = &c
+	return cfg, nil
+}
+
+// applyEnvOverrides overwrites the fields of v with matching environment variables.
+// Top level fields use the DB_ prefix (e.g., Type -> DB_TYPE, User -> DB_USER, to avoid
+// system var collisions), nested sections use the prefix from their env tag
+// (e.g., RateLimit.Burst -> RATE_LIMIT_BURST). Lists are read as comma separated values.
+func applyEnvOverrides(v reflect.Value, prefix string) {
 	t := v.Type()
 
 	for i := 0; i < v.NumField(); i++ {
 		field := v.Field(i)
 
+		if field.Kind() == reflect.Struct {
+			if section := t.Field(i).Tag.Get("env"); section != "" {
+				applyEnvOverrides(field, section)
+			}
+			continue
+		}
+
 		fieldName := t.Field(i).Name
 
-		//(e.g., Type -> DB_TYPE, User -> DB_USER, with DB_ prefix to avoid system var collisions)
-		envVarName := "DB_" + strings.ToUpper(strings.Join(splitCamelCase(fieldName), "_"))
-
-		if envVal := os.Getenv(envVarName); envVal != "" {
-			switch field.Kind() {
-			case reflect.String:
-				field.SetString(envVal)
-			case reflect.Int:
-				if intVal, err := strconv.Atoi(envVal); err == nil {
-					field.SetInt(int64(intVal))
-				}
+		envVarName := prefix + "_" + strings.ToUpper(strings.Join(splitCamelCase(fieldName), "_"))
+
+		envVal := os.Getenv(envVarName)
+		if envVal == "" {
+			continue
+		}
+
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(envVal)
+		case reflect.Int:
+			if intVal, err := strconv.Atoi(envVal); err == nil {
+				field.SetInt(int64(intVal))
+			}
+		case reflect.Float64:
+			if floatVal, err := strconv.ParseFloat(envVal, 64); err == nil {
+				field.SetFloat(floatVal)
+			}
+		case reflect.Bool:
+			if boolVal, err := strconv.ParseBool(envVal); err == nil {
+				field.SetBool(boolVal)
+			}
+		case reflect.Slice:
+			if field.Type().Elem().Kind() == reflect.String {
+				field.Set(reflect.ValueOf(splitList(envVal)))
 			}
 		}
 	}
-	cfg = &c
-	return cfg, nil
 }
 
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// splitCamelCase splits a field name into its words. A run of capitals is one word, so
+// IssuerURL becomes Issuer and URL and TTLHours becomes TTL and Hours.
 func splitCamelCase(s string) []string {
 	var words []string
 	var currentWord strings.Builder
 
-	for i, r := range s {
-		if i > 0 && r >= 'A' && r <= 'Z' {
-			words = append(words, currentWord.String())
-			currentWord.Reset()
+	runes := []rune(s)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if !unicode.IsUpper(prev) || nextIsLower {
+				words = append(words, currentWord.String())
+				currentWord.Reset()
+			}
 		}
 		currentWord.WriteRune(r)
 	}
@@ -0,0 +1,71 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyEnvOverrides(t *testing.T) {
+	t.Setenv("OIDC_ISSUER_URL", "https://login.example.com")
+	t.Setenv("OIDC_CLIENT_ID", "timetracker")
+	t.Setenv("AUTH_SESSION_TTL_MINUTES", "90")
+	t.Setenv("RATE_LIMIT_BURST", "7")
+	t.Setenv("DB_HOST", "db.internal")
+
+	var c Config
+	applyEnvOverrides(reflect.ValueOf(&c).Elem(), "DB")
+
+	if c.OIDC.IssuerURL != "https://login.example.com" {
+		t.Errorf("OIDC_ISSUER_URL set IssuerURL to %q", c.OIDC.IssuerURL)
+	}
+	if c.OIDC.ClientID != "timetracker" {
+		t.Errorf("OIDC_CLIENT_ID set ClientID to %q", c.OIDC.ClientID)
+	}
+	if c.Auth.SessionTTLMinutes != 90 {
+		t.Errorf("AUTH_SESSION_TTL_MINUTES set SessionTTLMinutes to %d", c.Auth.SessionTTLMinutes)
+	}
+	if c.RateLimit.Burst != 7 {
+		t.Errorf("RATE_LIMIT_BURST set Burst to %d", c.RateLimit.Burst)
+	}
+	if c.Host != "db.internal" {
+		t.Errorf("DB_HOST set Host to %q", c.Host)
+	}
+}
+
+func TestSplitCamelCase(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"Host", []string{"Host"}},
+		{"TrustProxyHeaders", []string{"Trust", "Proxy", "Headers"}},
+		{"IssuerURL", []string{"Issuer", "URL"}},
+		{"ClientID", []string{"Client", "ID"}},
+		{"SessionTTLMinutes", []string{"Session", "TTL", "Minutes"}},
+		{"URLPath", []string{"URL", "Path"}},
+		{"ID", []string{"ID"}},
+	}
+	for _, tt := range tests {
+		if got := splitCamelCase(tt.name); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitCamelCase(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestMetricsDisabledByDefault guards the shipped config: /metrics is not authenticated and
+// queries the database, so it has to be turned on explicitly.
+func TestMetricsDisabledByDefault(t *testing.T) {
+	c, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Metrics.Enabled {
+		t.Error("config.json enables metrics")
+	}
+}
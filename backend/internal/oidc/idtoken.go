@@ -0,0 +1,188 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+	"timetracker/errorutil"
+)
+
+// clockSkew is the leeway allowed between our clock and the provider's.
+const clockSkew = time.Minute
+
+// Claims are the verified ID token claims the API relies on.
+type Claims struct {
+	Issuer        string   `json:"iss"`
+	Subject       string   `json:"sub"`
+	Audience      audience `json:"aud"`
+	AuthorizedBy  string   `json:"azp,omitempty"`
+	ExpiresAt     int64    `json:"exp"`
+	IssuedAt      int64    `json:"iat"`
+	Nonce         string   `json:"nonce,omitempty"`
+	Email         string   `json:"email,omitempty"`
+	EmailVerified bool     `json:"email_verified,omitempty"`
+	Name          string   `json:"name,omitempty"`
+}
+
+// audience accepts both the single string and the array form of the aud claim.
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		return errorutil.Wrap(err, "invalid aud claim")
+	}
+	*a = many
+	return nil
+}
+
+type tokenHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// VerifyIDToken checks the signature of a compact serialized ID token against the provider
+// keys and validates issuer, audience, expiry and nonce as required by OpenID Connect Core 3.1.3.7.
+func (c *Client) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (*Claims, error) {
+	parts := strings.Split(rawIDToken, ".")
+	if len(parts) != 3 {
+		return nil, errorutil.New("malformed id_token")
+	}
+
+	var header tokenHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, errorutil.Wrap(err, "invalid id_token header")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errorutil.Wrap(err, "invalid id_token signature encoding")
+	}
+
+	key, err := c.signingKey(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifySignature(header.Alg, key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, err
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, errorutil.Wrap(err, "invalid id_token claims")
+	}
+
+	discovery, err := c.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Issuer != discovery.Issuer {
+		return nil, fmt.Errorf("id_token issued by %q, expected %q", claims.Issuer, discovery.Issuer)
+	}
+	if !slices.Contains(claims.Audience, c.cfg.ClientID) {
+		return nil, errorutil.New("id_token was not issued for this client")
+	}
+	if len(claims.Audience) > 1 && claims.AuthorizedBy != c.cfg.ClientID {
+		return nil, errorutil.New("id_token azp claim does not match this client")
+	}
+
+	now := c.now()
+	if claims.ExpiresAt == 0 || now.After(time.Unix(claims.ExpiresAt, 0).Add(clockSkew)) {
+		return nil, errorutil.New("id_token has expired")
+	}
+	if claims.IssuedAt != 0 && time.Unix(claims.IssuedAt, 0).After(now.Add(clockSkew)) {
+		return nil, errorutil.New("id_token was issued in the future")
+	}
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, errorutil.New("id_token nonce does not match the login request")
+	}
+	if claims.Subject == "" {
+		return nil, errorutil.New("id_token has no subject")
+	}
+
+	return &claims, nil
+}
+
+func verifySignature(alg string, key any, signed, signature []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "PS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "PS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512", "PS512", "ES512":
+		hash = crypto.SHA512
+	default:
+		// Rejects "none" and symmetric algorithms, the client secret is never a signing key here.
+		return fmt.Errorf("unsupported id_token algorithm %q", alg)
+	}
+
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch alg[:2] {
+	case "RS", "PS":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type does not match algorithm %s", alg)
+		}
+		var err error
+		if alg[0] == 'R' {
+			err = rsa.VerifyPKCS1v15(rsaKey, hash, digest, signature)
+		} else {
+			err = rsa.VerifyPSS(rsaKey, hash, digest, signature, nil)
+		}
+		if err != nil {
+			return errorutil.New("invalid id_token signature")
+		}
+
+	case "ES":
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type does not match algorithm %s", alg)
+		}
+		// JWS encodes ECDSA signatures as the fixed size concatenation of r and s.
+		size := (ecKey.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errorutil.New("invalid id_token signature")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(ecKey, digest, r, s) {
+			return errorutil.New("invalid id_token signature")
+		}
+	}
+
+	return nil
+}
+
+func decodeSegment(segment string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
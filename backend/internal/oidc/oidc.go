@@ -0,0 +1,212 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+// Package oidc implements the relying party side of the OpenID Connect
+// authorization code flow with PKCE: provider discovery, the authorization
+// redirect, the code exchange and ID token verification against the
+// provider's JSON Web Key Set.
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"timetracker/errorutil"
+)
+
+// Config identifies this API as a client of the identity provider.
+type Config struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// Discovery is the subset of the provider metadata document used by the client.
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint,omitempty"`
+}
+
+// Token is the token endpoint response of a successful code exchange.
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// Client talks to a single OpenID provider. Discovery metadata and signing keys are
+// fetched lazily and cached, so the API starts even when the provider is unreachable.
+type Client struct {
+	cfg        Config
+	httpClient *http.Client
+	now        func() time.Time
+
+	mu            sync.Mutex
+	discovery     *Discovery
+	keys          map[string]any
+	keysFetchedAt time.Time
+}
+
+// New returns a client for the provider described by cfg. A nil httpClient uses a
+// client with a 10 second timeout.
+func New(cfg Config, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+
+	return &Client{
+		cfg:        cfg,
+		httpClient: httpClient,
+		now:        time.Now,
+	}
+}
+
+// Discover returns the provider metadata, fetching it from the well-known endpoint on first use.
+func (c *Client) Discover(ctx context.Context) (*Discovery, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.discovery != nil {
+		return c.discovery, nil
+	}
+
+	wellKnown := strings.TrimSuffix(c.cfg.IssuerURL, "/") + "/.well-known/openid-configuration"
+
+	var discovery Discovery
+	if err := c.getJSON(ctx, wellKnown, &discovery); err != nil {
+		return nil, errorutil.Wrap(err, "failed to fetch provider metadata")
+	}
+
+	// The issuer in the metadata must be the one we were configured with (OpenID Connect Discovery 4.3).
+	if strings.TrimSuffix(discovery.Issuer, "/") != strings.TrimSuffix(c.cfg.IssuerURL, "/") {
+		return nil, fmt.Errorf("provider issuer %q does not match configured issuer %q", discovery.Issuer, c.cfg.IssuerURL)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, errorutil.New("provider metadata is missing required endpoints")
+	}
+
+	c.discovery = &discovery
+	return c.discovery, nil
+}
+
+// AuthCodeURL builds the authorization endpoint URL the user agent is redirected to.
+// The code challenge must be derived from a verifier with NewPKCE.
+func (c *Client) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	discovery, err := c.Discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := url.Parse(discovery.AuthorizationEndpoint)
+	if err != nil {
+		return "", errorutil.Wrap(err, "invalid authorization endpoint")
+	}
+
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", c.cfg.ClientID)
+	query.Set("redirect_uri", c.cfg.RedirectURL)
+	query.Set("scope", strings.Join(c.cfg.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+
+	return authURL.String(), nil
+}
+
+// Exchange trades an authorization code and its PKCE verifier for tokens.
+func (c *Client) Exchange(ctx context.Context, code, codeVerifier string) (*Token, error) {
+	discovery, err := c.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", c.cfg.RedirectURL)
+	form.Set("client_id", c.cfg.ClientID)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, errorutil.Wrap(err, "failed to build token request")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if c.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(c.cfg.ClientID), url.QueryEscape(c.cfg.ClientSecret))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, errorutil.Wrap(err, "token request failed")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, errorutil.Wrap(err, "failed to read token response")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var tokenErr struct {
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+		}
+		if json.Unmarshal(body, &tokenErr) == nil && tokenErr.Error != "" {
+			return nil, fmt.Errorf("token endpoint returned %s: %s", tokenErr.Error, tokenErr.ErrorDescription)
+		}
+		return nil, fmt.Errorf("token endpoint returned status %d", resp.StatusCode)
+	}
+
+	var token Token
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, errorutil.Wrap(err, "failed to parse token response")
+	}
+	if token.IDToken == "" {
+		return nil, errorutil.New("token response does not contain an id_token")
+	}
+
+	return &token, nil
+}
+
+func (c *Client) getJSON(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned status %d", url, resp.StatusCode)
+	}
+
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
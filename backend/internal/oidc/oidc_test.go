@@ -0,0 +1,411 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testClientID     = "timetracker"
+	testClientSecret = "s3cret"
+	testRedirectURL  = "http://localhost:8080/auth/oidc/callback"
+)
+
+// testProvider is a stand-in identity provider implementing just enough of OpenID Connect
+// for the relying party: discovery, an authorization endpoint that approves every request,
+// a token endpoint enforcing PKCE and a JWKS endpoint.
+type testProvider struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu     sync.Mutex
+	key    *rsa.PrivateKey
+	kid    string
+	codes  map[string]authorization
+	claims map[string]any
+}
+
+type authorization struct {
+	challenge string
+	nonce     string
+}
+
+func newTestProvider(t *testing.T) *testProvider {
+	t.Helper()
+
+	p := &testProvider{
+		t:     t,
+		key:   generateKey(t),
+		kid:   "key-1",
+		codes: make(map[string]authorization),
+		claims: map[string]any{
+			"email":          "jane@example.com",
+			"email_verified": true,
+			"name":           "Jane Doe",
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("GET /authorize", p.authorize)
+	mux.HandleFunc("POST /token", p.token)
+	mux.HandleFunc("GET /jwks", p.jwks)
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+
+	return p
+}
+
+func (p *testProvider) discovery(w http.ResponseWriter, req *http.Request) {
+	json.NewEncoder(w).Encode(Discovery{
+		Issuer:                p.server.URL,
+		AuthorizationEndpoint: p.server.URL + "/authorize",
+		TokenEndpoint:         p.server.URL + "/token",
+		JWKSURI:               p.server.URL + "/jwks",
+	})
+}
+
+func (p *testProvider) authorize(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	if query.Get("client_id") != testClientID || query.Get("code_challenge_method") != "S256" {
+		http.Error(w, "bad authorization request", http.StatusBadRequest)
+		return
+	}
+
+	code := randomTestString(p.t)
+	p.mu.Lock()
+	p.codes[code] = authorization{challenge: query.Get("code_challenge"), nonce: query.Get("nonce")}
+	p.mu.Unlock()
+
+	redirect, _ := url.Parse(query.Get("redirect_uri"))
+	callback := redirect.Query()
+	callback.Set("code", code)
+	callback.Set("state", query.Get("state"))
+	redirect.RawQuery = callback.Encode()
+	http.Redirect(w, req, redirect.String(), http.StatusFound)
+}
+
+func (p *testProvider) token(w http.ResponseWriter, req *http.Request) {
+	clientID, secret, ok := req.BasicAuth()
+	if !ok || clientID != testClientID || secret != testClientSecret {
+		tokenError(w, http.StatusUnauthorized, "invalid_client")
+		return
+	}
+
+	p.mu.Lock()
+	auth, found := p.codes[req.PostFormValue("code")]
+	delete(p.codes, req.PostFormValue("code"))
+	p.mu.Unlock()
+
+	if !found || req.PostFormValue("redirect_uri") != testRedirectURL {
+		tokenError(w, http.StatusBadRequest, "invalid_grant")
+		return
+	}
+	if CodeChallenge(req.PostFormValue("code_verifier")) != auth.challenge {
+		tokenError(w, http.StatusBadRequest, "invalid_grant")
+		return
+	}
+
+	claims := map[string]any{
+		"iss":   p.server.URL,
+		"sub":   "user-123",
+		"aud":   testClientID,
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(5 * time.Minute).Unix(),
+		"nonce": auth.nonce,
+	}
+	p.mu.Lock()
+	for k, v := range p.claims {
+		claims[k] = v
+	}
+	p.mu.Unlock()
+
+	json.NewEncoder(w).Encode(Token{
+		AccessToken: "access-token",
+		TokenType:   "Bearer",
+		IDToken:     p.sign(claims),
+		ExpiresIn:   300,
+	})
+}
+
+func (p *testProvider) jwks(w http.ResponseWriter, req *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	json.NewEncoder(w).Encode(map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": p.kid,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (p *testProvider) sign(claims map[string]any) string {
+	p.mu.Lock()
+	key, kid := p.key, p.kid
+	p.mu.Unlock()
+	return signToken(p.t, key, kid, claims)
+}
+
+func (p *testProvider) rotateKey(kid string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.key = generateKey(p.t)
+	p.kid = kid
+}
+
+func (p *testProvider) client() *Client {
+	return New(Config{
+		IssuerURL:    p.server.URL,
+		ClientID:     testClientID,
+		ClientSecret: testClientSecret,
+		RedirectURL:  testRedirectURL,
+	}, p.server.Client())
+}
+
+// login drives the flow a browser would: follow the authorization redirect and read
+// code and state from the callback URL.
+func (p *testProvider) login(t *testing.T, c *Client, nonce, challenge string) (code, state string) {
+	t.Helper()
+
+	authURL, err := c.AuthCodeURL(context.Background(), "state-1", nonce, challenge)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+
+	noRedirect := p.server.Client()
+	noRedirect.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	resp, err := noRedirect.Get(authURL)
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize returned %d", resp.StatusCode)
+	}
+
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("parse callback: %v", err)
+	}
+	if !strings.HasPrefix(callback.String(), testRedirectURL) {
+		t.Fatalf("callback went to %s", callback)
+	}
+	return callback.Query().Get("code"), callback.Query().Get("state")
+}
+
+func TestLoginFlow(t *testing.T) {
+	p := newTestProvider(t)
+	c := p.client()
+	ctx := context.Background()
+
+	verifier, challenge, err := NewPKCE()
+	if err != nil {
+		t.Fatalf("NewPKCE: %v", err)
+	}
+	code, state := p.login(t, c, "nonce-1", challenge)
+	if state != "state-1" {
+		t.Fatalf("state = %q, want state-1", state)
+	}
+
+	token, err := c.Exchange(ctx, code, verifier)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+
+	claims, err := c.VerifyIDToken(ctx, token.IDToken, "nonce-1")
+	if err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+	if claims.Email != "jane@example.com" || !claims.EmailVerified || claims.Subject != "user-123" {
+		t.Fatalf("unexpected claims %+v", claims)
+	}
+}
+
+func TestExchangeRejectsWrongVerifier(t *testing.T) {
+	p := newTestProvider(t)
+	c := p.client()
+
+	_, challenge, _ := NewPKCE()
+	code, _ := p.login(t, c, "nonce-1", challenge)
+
+	otherVerifier, _, _ := NewPKCE()
+	if _, err := c.Exchange(context.Background(), code, otherVerifier); err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Fatalf("Exchange with wrong verifier: err = %v, want invalid_grant", err)
+	}
+}
+
+func TestVerifyIDTokenRejectsNonceMismatch(t *testing.T) {
+	p := newTestProvider(t)
+	c := p.client()
+	ctx := context.Background()
+
+	verifier, challenge, _ := NewPKCE()
+	code, _ := p.login(t, c, "nonce-1", challenge)
+	token, err := c.Exchange(ctx, code, verifier)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+
+	if _, err := c.VerifyIDToken(ctx, token.IDToken, "other-nonce"); err == nil {
+		t.Fatal("VerifyIDToken accepted a token with a different nonce")
+	}
+}
+
+func TestVerifyIDTokenRejectsInvalidTokens(t *testing.T) {
+	p := newTestProvider(t)
+	c := p.client()
+
+	valid := func() map[string]any {
+		return map[string]any{
+			"iss":   p.server.URL,
+			"sub":   "user-123",
+			"aud":   testClientID,
+			"iat":   time.Now().Unix(),
+			"exp":   time.Now().Add(time.Minute).Unix(),
+			"nonce": "n",
+		}
+	}
+
+	tests := []struct {
+		name  string
+		token func() string
+	}{
+		{"expired", func() string {
+			claims := valid()
+			claims["exp"] = time.Now().Add(-time.Hour).Unix()
+			return p.sign(claims)
+		}},
+		{"wrong audience", func() string {
+			claims := valid()
+			claims["aud"] = "someone-else"
+			return p.sign(claims)
+		}},
+		{"wrong issuer", func() string {
+			claims := valid()
+			claims["iss"] = "https://evil.example.com"
+			return p.sign(claims)
+		}},
+		{"signed by unknown key", func() string {
+			return signToken(t, generateKey(t), p.kid, valid())
+		}},
+		{"unsigned", func() string {
+			header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`))
+			payload, _ := json.Marshal(valid())
+			return header + "." + base64.RawURLEncoding.EncodeToString(payload) + "."
+		}},
+		{"tampered payload", func() string {
+			parts := strings.Split(p.sign(valid()), ".")
+			claims := valid()
+			claims["sub"] = "admin"
+			payload, _ := json.Marshal(claims)
+			return parts[0] + "." + base64.RawURLEncoding.EncodeToString(payload) + "." + parts[2]
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := c.VerifyIDToken(context.Background(), tt.token(), "n"); err == nil {
+				t.Fatal("VerifyIDToken accepted an invalid token")
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenPicksUpRotatedKeys(t *testing.T) {
+	p := newTestProvider(t)
+	c := p.client()
+	ctx := context.Background()
+
+	claims := map[string]any{
+		"iss":   p.server.URL,
+		"sub":   "user-123",
+		"aud":   testClientID,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"nonce": "n",
+	}
+	if _, err := c.VerifyIDToken(ctx, p.sign(claims), "n"); err != nil {
+		t.Fatalf("VerifyIDToken before rotation: %v", err)
+	}
+
+	p.rotateKey("key-2")
+	// Pretend the cached key set is older than the refresh interval.
+	c.now = func() time.Time { return time.Now().Add(2 * keyRefreshInterval) }
+
+	if _, err := c.VerifyIDToken(ctx, p.sign(claims), "n"); err != nil {
+		t.Fatalf("VerifyIDToken after rotation: %v", err)
+	}
+}
+
+func TestDiscoverRejectsIssuerMismatch(t *testing.T) {
+	p := newTestProvider(t)
+	c := New(Config{IssuerURL: p.server.URL + "/other", ClientID: testClientID}, p.server.Client())
+
+	if _, err := c.Discover(context.Background()); err == nil {
+		t.Fatal("Discover accepted metadata for a different issuer")
+	}
+}
+
+func generateKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return key
+}
+
+func signToken(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	t.Helper()
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("marshal claims: %v", err)
+	}
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func tokenError(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": code})
+}
+
+func randomTestString(t *testing.T) string {
+	s, err := RandomString(16)
+	if err != nil {
+		t.Fatalf("RandomString: %v", err)
+	}
+	return s
+}
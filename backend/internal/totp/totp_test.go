@@ -0,0 +1,146 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package totp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the ASCII secret "12345678901234567890" of RFC 4226 and RFC 6238, base32 encoded.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeRFC4226(t *testing.T) {
+	// RFC 4226 Appendix D, HOTP values for the counters 0 to 9.
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+
+	for counter, code := range want {
+		got, err := Code(rfcSecret, int64(counter))
+		if err != nil {
+			t.Fatalf("Code(%d): %v", counter, err)
+		}
+		if got != code {
+			t.Errorf("Code(%d) = %s, want %s", counter, got, code)
+		}
+	}
+}
+
+func TestCodeRFC6238(t *testing.T) {
+	// RFC 6238 Appendix B, SHA1 values. The RFC lists 8 digits, the 6 digit codes are their last 6.
+	tests := []struct {
+		unix int64
+		step int64
+		code string
+	}{
+		{59, 0x1, "287082"},
+		{1111111109, 0x23523EC, "081804"},
+		{1111111111, 0x23523ED, "050471"},
+		{1234567890, 0x273EF07, "005924"},
+		{2000000000, 0x3F940AA, "279037"},
+		{20000000000, 0x27BC86AA, "353130"},
+	}
+
+	for _, tt := range tests {
+		now := time.Unix(tt.unix, 0)
+		if step := Step(now); step != tt.step {
+			t.Errorf("Step(%d) = %X, want %X", tt.unix, step, tt.step)
+		}
+		got, err := Code(rfcSecret, Step(now))
+		if err != nil {
+			t.Fatalf("Code at %d: %v", tt.unix, err)
+		}
+		if got != tt.code {
+			t.Errorf("Code at %d = %s, want %s", tt.unix, got, tt.code)
+		}
+		if step, ok := Validate(rfcSecret, tt.code, now, 0); !ok || step != tt.step {
+			t.Errorf("Validate(%s) at %d = %X, %v, want step %X", tt.code, tt.unix, step, ok, tt.step)
+		}
+	}
+}
+
+func TestValidateSkew(t *testing.T) {
+	const skew = 1
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+
+	for offset := int64(-skew - 1); offset <= skew+1; offset++ {
+		code, err := Code(rfcSecret, current+offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		step, ok := Validate(rfcSecret, code, now, skew)
+
+		inWindow := offset >= -skew && offset <= skew
+		if ok != inWindow {
+			t.Errorf("code of step %+d accepted %v, want %v", offset, ok, inWindow)
+		}
+		// The matched step is returned, so the caller can reject it when it was used before.
+		if ok && step != current+offset {
+			t.Errorf("code of step %+d matched step %d, want %d", offset, step, current+offset)
+		}
+	}
+
+	// Steps start on multiples of the period: the last second of a step and the first of the next.
+	code, _ := Code(rfcSecret, 1)
+	if _, ok := Validate(rfcSecret, code, time.Unix(59, 0), 0); !ok {
+		t.Error("code of step 1 rejected in its last second")
+	}
+	if _, ok := Validate(rfcSecret, code, time.Unix(60, 0), 0); ok {
+		t.Error("code of step 1 accepted in step 2 without skew")
+	}
+}
+
+func TestValidateInput(t *testing.T) {
+	now := time.Unix(59, 0)
+
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+		want   bool
+	}{
+		{"valid", rfcSecret, "287082", true},
+		{"spaces", rfcSecret, " 287 082 ", true},
+		{"lower case secret", "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", "287082", true},
+		{"wrong code", rfcSecret, "287083", false},
+		{"too short", rfcSecret, "28708", false},
+		{"too long", rfcSecret, "2870820", false},
+		{"empty", rfcSecret, "", false},
+		{"invalid secret", "not base32!", "287082", false},
+	}
+
+	for _, tt := range tests {
+		if _, ok := Validate(tt.secret, tt.code, now, 1); ok != tt.want {
+			t.Errorf("%s: Validate accepted %v, want %v", tt.name, ok, tt.want)
+		}
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Code(secret, 0); err != nil {
+		t.Errorf("generated secret %q cannot be used: %v", secret, err)
+	}
+
+	other, _ := GenerateSecret()
+	if other == secret {
+		t.Error("two generated secrets are equal")
+	}
+
+	uri, err := url.Parse(URI("Time Tracker", "ada@example.com", secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Query().Get("secret") != secret ||
+		uri.Query().Get("digits") != "6" || uri.Query().Get("period") != "30" {
+		t.Errorf("URI %s does not describe the secret", uri)
+	}
+}
@@ -0,0 +1,68 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Keys of the attributes written with the records.
+const (
+	NameKey      = "logger"
+	RequestIDKey = "request_id"
+	UserIDKey    = "user_id"
+	TrackerIDKey = "tracker_id"
+	TraceIDKey   = "trace_id"
+)
+
+func RequestID(id string) slog.Attr {
+	return slog.String(RequestIDKey, id)
+}
+
+func UserID(id int) slog.Attr {
+	return slog.Int(UserIDKey, id)
+}
+
+func TrackerID(id int) slog.Attr {
+	return slog.Int(TrackerIDKey, id)
+}
+
+func TraceID(id string) slog.Attr {
+	return slog.String(TraceIDKey, id)
+}
+
+type attrsContextKey struct{}
+
+// ContextWith returns a copy of ctx carrying attrs next to the attributes ctx already carries.
+// Records logged through Logger.Ctx or the *Context methods of Slog with the context include them.
+func ContextWith(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing, _ := ctx.Value(attrsContextKey{}).([]slog.Attr)
+	combined := make([]slog.Attr, 0, len(existing)+len(attrs))
+	combined = append(combined, existing...)
+	combined = append(combined, attrs...)
+	return context.WithValue(ctx, attrsContextKey{}, combined)
+}
+
+// contextHandler adds the attributes carried by the context of a record.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if attrs, ok := ctx.Value(attrsContextKey{}).([]slog.Attr); ok {
+		record.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
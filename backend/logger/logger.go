@@ -6,50 +6,177 @@
 package logger
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"timetracker/internal/config"
 )
 
-// Logger holds separate loggers for db, api, and app.
+// Logger writes leveled, structured records through log/slog. The printf style methods stay for
+// the callers in db, api and migrate; attributes added with With or carried by the context given
+// to Ctx are written with every record.
 type Logger struct {
+	// FilePath is the log file, empty when the logger only writes to stdout.
 	FilePath string
 	name     string
+	handler  slog.Handler
+	ctx      context.Context
+	file     *rotatingFile
 }
 
-// NewLogger initializes loggers writing to separate files in the given dir.
+// Options configure a Logger. Without Stdout and File, records go to stdout.
+type Options struct {
+	// Level is the minimum level written: debug, info, warn or error. Empty means info.
+	Level string
+	// Format is text or json. Empty means text.
+	Format string
+	Stdout bool
+	File   string
+
+	// MaxSizeMB rotates File before it grows past the size, 0 never rotates. Of the rotated files
+	// the newest MaxBackups are kept, and none older than MaxAgeDays; 0 keeps all of them.
+	// Compress gzips the rotated files.
+	MaxSizeMB  int
+	MaxBackups int
+	MaxAgeDays int
+	Compress   bool
+}
+
+// NewLogger returns an info level text logger appending to filePath. When the file cannot be
+// opened it logs to stdout instead.
 func NewLogger(name, filePath string) *Logger {
-	return &Logger{
-		FilePath: filePath,
-		name:     name,
+	l, err := New(name, Options{File: filePath})
+	if err != nil {
+		fmt.Printf("Logger error: %v\n", err)
+		l, _ = New(name, Options{Stdout: true})
 	}
+	return l
+}
+
+// NewFromConfig returns the logger of the binary name set up by the log section of the
+// configuration. The log file is <name>.log in the configured directory.
+func NewFromConfig(name string, cfg config.LogConfig) (*Logger, error) {
+	opts := Options{
+		Level:      cfg.Level,
+		Format:     cfg.Format,
+		Stdout:     cfg.Stdout,
+		MaxSizeMB:  cfg.MaxSizeMB,
+		MaxBackups: cfg.MaxBackups,
+		MaxAgeDays: cfg.MaxAgeDays,
+		Compress:   cfg.Compress,
+	}
+	if cfg.Dir != "" {
+		opts.File = filepath.Join(cfg.Dir, name+".log")
+	}
+	return New(name, opts)
+}
+
+// New returns a logger writing the records of opts.Level and above. The log file is opened once
+// and kept open until Close, apart from rotations.
+func New(name string, opts Options) (*Logger, error) {
+	level, err := ParseLevel(opts.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Logger{FilePath: opts.File, name: name, ctx: context.Background()}
+
+	var writers []io.Writer
+	if opts.Stdout || opts.File == "" {
+		writers = append(writers, os.Stdout)
+	}
+	if opts.File != "" {
+		maxSize := int64(max(opts.MaxSizeMB, 0)) << 20
+		maxAge := time.Duration(max(opts.MaxAgeDays, 0)) * 24 * time.Hour
+		if l.file, err = openRotatingFile(opts.File, maxSize, opts.MaxBackups, maxAge, opts.Compress); err != nil {
+			return nil, err
+		}
+		writers = append(writers, l.file)
+	}
+
+	handlerOpts := &slog.HandlerOptions{Level: level}
+	switch strings.ToLower(opts.Format) {
+	case "", "text":
+		l.handler = slog.NewTextHandler(io.MultiWriter(writers...), handlerOpts)
+	case "json":
+		l.handler = slog.NewJSONHandler(io.MultiWriter(writers...), handlerOpts)
+	default:
+		l.Close()
+		return nil, fmt.Errorf("unknown log format %q, use text or json", opts.Format)
+	}
+	l.handler = contextHandler{l.handler.WithAttrs([]slog.Attr{slog.String(NameKey, name)})}
+
+	return l, nil
+}
+
+// ParseLevel reads a level name such as debug, info, warn or error. Empty means info.
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if s == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q, use debug, info, warn or error", s)
+	}
+	return level, nil
 }
 
 func (l *Logger) Infof(format string, v ...any) {
-	l.write(format, v...)
+	l.logf(slog.LevelInfo, format, v...)
 }
 
 func (l *Logger) Errorf(format string, v ...any) {
-	l.write(format, v...)
+	l.logf(slog.LevelError, format, v...)
 }
 
 func (l *Logger) Warnf(format string, v ...any) {
-	l.write(format, v...)
+	l.logf(slog.LevelWarn, format, v...)
 }
 
 func (l *Logger) Debugf(format string, v ...any) {
-	l.write(format, v...)
+	l.logf(slog.LevelDebug, format, v...)
 }
 
-func (l *Logger) write(format string, v ...any) {
-	logFile, err := os.OpenFile(l.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
-	if err != nil {
-		fmt.Printf("Logger error: %v", err)
-		return
+// With returns a logger that adds attrs to every record, e.g. logger.TrackerID(id).
+func (l *Logger) With(attrs ...slog.Attr) *Logger {
+	derived := *l
+	derived.handler = l.handler.WithAttrs(attrs)
+	return &derived
+}
+
+// Ctx returns a logger that adds the attributes carried by ctx, see ContextWith, to every record.
+func (l *Logger) Ctx(ctx context.Context) *Logger {
+	derived := *l
+	derived.ctx = ctx
+	return &derived
+}
+
+// Slog returns the logger as a *slog.Logger for structured calls such as Info(msg, key, value).
+func (l *Logger) Slog() *slog.Logger {
+	return slog.New(l.handler)
+}
+
+// Close closes the log file shared by l and the loggers derived from it, after the rotated files
+// are cleaned up.
+func (l *Logger) Close() error {
+	if l.file == nil {
+		return nil
 	}
+	return l.file.Close()
+}
 
-	defer logFile.Close()
+func (l *Logger) logf(level slog.Level, format string, v ...any) {
+	if !l.handler.Enabled(l.ctx, level) {
+		return
+	}
 
-	logger := log.New(logFile, l.name+": ", log.LstdFlags)
-	logger.Printf(format, v...)
+	record := slog.NewRecord(time.Now(), level, strings.TrimSuffix(fmt.Sprintf(format, v...), "\n"), 0)
+	if err := l.handler.Handle(l.ctx, record); err != nil {
+		fmt.Printf("Logger error: %v\n", err)
+	}
 }
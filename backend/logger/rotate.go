@@ -0,0 +1,279 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat names rotated files, e.g. api.log becomes api-2025-06-02T09-14-07.123.log.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// rotatingFile is an append-only log file that is moved aside once a write would grow it past
+// maxSize. Rotated files beyond maxBackups or older than maxAge are deleted, and gzipped when
+// compress is set, by a background goroutine so writers never wait for it. Writes are serialized,
+// so records from many goroutines never interleave or get lost across a rotation.
+type rotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	maxAge     time.Duration
+	compress   bool
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	closed bool
+
+	cleanup chan struct{}
+	done    chan struct{}
+}
+
+// openRotatingFile opens path for appending. A maxSize of 0 turns rotation off.
+func openRotatingFile(path string, maxSize int64, maxBackups int, maxAge time.Duration, compress bool) (*rotatingFile, error) {
+	f := &rotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+		maxAge:     maxAge,
+		compress:   compress,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+
+	if maxSize > 0 {
+		f.cleanup = make(chan struct{}, 1)
+		f.done = make(chan struct{})
+		go f.cleanupLoop()
+		// Backups left by earlier runs may have aged out meanwhile.
+		f.cleanup <- struct{}{}
+	}
+	return f, nil
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	// A rotation that could not open the new file is retried with the next record.
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the file and waits for the pending cleanup, so no half written archive is left.
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+
+	if f.cleanup != nil {
+		close(f.cleanup)
+		<-f.done
+	}
+	return err
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return fmt.Errorf("opening log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("opening log file: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// rotate moves the current file aside and starts a new one. It is called with mu held.
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("closing log file: %w", err)
+	}
+	f.file = nil
+
+	// Rotations within the same millisecond must not overwrite each other's backup.
+	rotated := time.Now()
+	for {
+		if _, err := os.Lstat(f.backupName(rotated)); os.IsNotExist(err) {
+			break
+		}
+		rotated = rotated.Add(time.Millisecond)
+	}
+
+	if err := os.Rename(f.path, f.backupName(rotated)); err != nil {
+		// Keep logging to the full file rather than losing records.
+		fmt.Fprintf(os.Stderr, "Logger error: rotating %s: %v\n", f.path, err)
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	select {
+	case f.cleanup <- struct{}{}:
+	default:
+		// A cleanup is pending already and will see this backup too.
+	}
+	return nil
+}
+
+func (f *rotatingFile) backupName(t time.Time) string {
+	dir, prefix, ext := f.nameParts()
+	return filepath.Join(dir, prefix+t.UTC().Format(backupTimeFormat)+ext)
+}
+
+// nameParts splits dir/api.log into dir, the backup prefix api- and the extension .log.
+func (f *rotatingFile) nameParts() (dir, prefix, ext string) {
+	dir = filepath.Dir(f.path)
+	base := filepath.Base(f.path)
+	ext = filepath.Ext(base)
+	return dir, strings.TrimSuffix(base, ext) + "-", ext
+}
+
+func (f *rotatingFile) cleanupLoop() {
+	defer close(f.done)
+	for range f.cleanup {
+		if err := f.removeOldBackups(); err != nil {
+			fmt.Fprintf(os.Stderr, "Logger error: cleaning up backups of %s: %v\n", f.path, err)
+		}
+	}
+}
+
+type logBackup struct {
+	path    string
+	rotated time.Time
+	gzipped bool
+}
+
+// backups lists the rotated files of f, newest first.
+func (f *rotatingFile) backups() ([]logBackup, error) {
+	dir, prefix, ext := f.nameParts()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []logBackup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		backup := logBackup{path: filepath.Join(dir, name)}
+		stamp := strings.TrimPrefix(name, prefix)
+		if trimmed, ok := strings.CutSuffix(stamp, ext+".gz"); ok {
+			stamp, backup.gzipped = trimmed, true
+		} else if trimmed, ok := strings.CutSuffix(stamp, ext); ok {
+			stamp = trimmed
+		} else {
+			continue
+		}
+		if backup.rotated, err = time.Parse(backupTimeFormat, stamp); err != nil {
+			continue
+		}
+		backups = append(backups, backup)
+	}
+
+	sort.Slice(backups, func(i, j int) bool { return backups[i].rotated.After(backups[j].rotated) })
+	return backups, nil
+}
+
+func (f *rotatingFile) removeOldBackups() error {
+	backups, err := f.backups()
+	if err != nil {
+		return err
+	}
+
+	for i, backup := range backups {
+		expired := f.maxAge > 0 && time.Since(backup.rotated) > f.maxAge
+		if (f.maxBackups > 0 && i >= f.maxBackups) || expired {
+			if err := os.Remove(backup.path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+		if f.compress && !backup.gzipped {
+			if err := gzipFile(backup.path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// gzipFile replaces path with path.gz. The archive is written under a temporary name first, so
+// a crash never leaves a truncated archive next to a deleted log.
+func gzipFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmp := path + ".gz.tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if closeErr := zw.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path+".gz")
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	src.Close()
+	return os.Remove(path)
+}
@@ -0,0 +1,149 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+package logger
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileConcurrentWrites(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "api.log")
+	const maxSize, writers, records = 4096, 16, 200
+
+	f, err := openRotatingFile(path, maxSize, 0, 0, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for w := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range records {
+				if _, err := fmt.Fprintf(f, "writer=%02d record=%03d %s\n", w, i, strings.Repeat("x", 40)); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "api*.log"))
+	if len(files) < 2 {
+		t.Fatalf("expected the file to be rotated, got %v", files)
+	}
+
+	seen := map[string]bool{}
+	for _, file := range files {
+		info, _ := os.Stat(file)
+		if info.Size() > maxSize {
+			t.Errorf("%s has %d bytes, more than the %d allowed", filepath.Base(file), info.Size(), maxSize)
+		}
+		for _, line := range readLines(t, file) {
+			var w, i int
+			var padding string
+			if n, _ := fmt.Sscanf(line, "writer=%d record=%d %s", &w, &i, &padding); n != 3 || len(padding) != 40 {
+				t.Fatalf("%s holds a mangled record %q", filepath.Base(file), line)
+			}
+			seen[line] = true
+		}
+	}
+	if len(seen) != writers*records {
+		t.Errorf("found %d distinct records, want %d", len(seen), writers*records)
+	}
+}
+
+func TestRotatingFileCleanup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "api.log")
+
+	// Left behind by an earlier run, older than the maximum age.
+	expired := filepath.Join(dir, "api-"+time.Now().Add(-48*time.Hour).UTC().Format(backupTimeFormat)+".log")
+	if err := os.WriteFile(expired, []byte("old\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	unrelated := filepath.Join(dir, "api-notes.log")
+	if err := os.WriteFile(unrelated, []byte("keep\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := openRotatingFile(path, 100, 2, 24*time.Hour, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 6 {
+		fmt.Fprintf(f, "record %d %s\n", i, strings.Repeat("y", 80))
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(expired); !os.IsNotExist(err) {
+		t.Errorf("expired backup was not removed")
+	}
+	if _, err := os.Stat(unrelated); err != nil {
+		t.Errorf("file that is no backup was touched: %v", err)
+	}
+	if plain, _ := filepath.Glob(filepath.Join(dir, "api-*T*.log")); len(plain) != 0 {
+		t.Errorf("uncompressed backups left: %v", plain)
+	}
+
+	archives, _ := filepath.Glob(filepath.Join(dir, "api-*.log.gz"))
+	if len(archives) != 2 {
+		t.Fatalf("kept %d backups, want 2: %v", len(archives), archives)
+	}
+	// The newest backups are kept: records 3 and 4, record 5 is in the current file.
+	for _, archive := range archives {
+		lines := readLines(t, archive)
+		if len(lines) != 1 || !(strings.HasPrefix(lines[0], "record 3 ") || strings.HasPrefix(lines[0], "record 4 ")) {
+			t.Errorf("%s holds %q, want record 3 or 4", filepath.Base(archive), lines)
+		}
+	}
+	if lines := readLines(t, path); len(lines) != 1 || !strings.HasPrefix(lines[0], "record 5 ") {
+		t.Errorf("current file holds %q, want record 5", lines)
+	}
+}
+
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(file)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		r = zr
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines
+}
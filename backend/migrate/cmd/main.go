@@ -6,13 +6,27 @@
 package main
 
 import (
+	"fmt"
+	"os"
 	"timetracker/db"
+	"timetracker/internal/config"
 	"timetracker/logger"
 	"timetracker/migrate"
 )
 
 func main() {
-	logger := logger.NewLogger("migrate", "./migrate.log")
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		logger.NewLogger("migrate", "./migrate.log").Errorf("Failed to load config: %v", err)
+		return
+	}
+
+	logger, err := logger.NewFromConfig("migrate", cfg.Log)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to set up logging: %v\n", err)
+		return
+	}
+	defer logger.Close()
 
 	pgDB := db.Init(logger)
 
@@ -5,20 +5,367 @@ import (
 	"fmt"
 )
 
-func Migrate(db *sql.DB) (error, string) {
-	query := `
+type migration struct {
+	name  string
+	query string
+}
+
+// migrations are applied in order on every run, so each query must be safe to repeat.
+var migrations = []migration{
+	{
+		name: "create tracker table",
+		query: `
 	CREATE TABLE IF NOT EXISTS tracker (
-		id SERIAL PRIMARY KEY,                   
-		task TEXT NOT NULL CHECK (length(task) > 0), 
+		id SERIAL PRIMARY KEY,
+		task TEXT NOT NULL CHECK (length(task) > 0),
 		start_time TIMESTAMP NOT NULL,
 		end_time TIMESTAMP,
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-	);`
+	);`,
+	},
+	{
+		name: "create users table",
+		query: `
+	CREATE TABLE IF NOT EXISTS users (
+		id SERIAL PRIMARY KEY,
+		email TEXT NOT NULL UNIQUE CHECK (email = lower(email)),
+		name TEXT NOT NULL DEFAULT '',
+		role TEXT NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'admin')),
+		oidc_issuer TEXT,
+		oidc_subject TEXT,
+		last_login_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);`,
+	},
+	{
+		name: "create sessions table",
+		query: `
+	CREATE TABLE IF NOT EXISTS sessions (
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		token_hash TEXT NOT NULL UNIQUE,
+		expires_at TIMESTAMPTZ NOT NULL,
+		revoked_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS sessions_user_id_idx ON sessions (user_id);`,
+	},
+	{
+		name: "create oidc login requests table",
+		query: `
+	CREATE TABLE IF NOT EXISTS oidc_login_requests (
+		state TEXT PRIMARY KEY,
+		nonce TEXT NOT NULL,
+		code_verifier TEXT NOT NULL,
+		expires_at TIMESTAMPTZ NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);`,
+	},
+	{
+		name: "add project to tracker table",
+		query: `
+	ALTER TABLE tracker ADD COLUMN IF NOT EXISTS project TEXT;
+	CREATE INDEX IF NOT EXISTS tracker_project_start_time_idx ON tracker (project, start_time);`,
+	},
+	{
+		name: "create share links table",
+		query: `
+	CREATE TABLE IF NOT EXISTS share_links (
+		id SERIAL PRIMARY KEY,
+		created_by INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		project TEXT,
+		from_time TIMESTAMP,
+		to_time TIMESTAMP,
+		expires_at TIMESTAMPTZ NOT NULL,
+		revoked_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS share_links_created_by_idx ON share_links (created_by);`,
+	},
+	{
+		name: "add two-factor authentication",
+		query: `
+	ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_secret TEXT;
+	ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+	ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_last_step BIGINT;
+	ALTER TABLE sessions ADD COLUMN IF NOT EXISTS mfa_pending BOOLEAN NOT NULL DEFAULT FALSE;
+	ALTER TABLE sessions ADD COLUMN IF NOT EXISTS mfa_failed_attempts INTEGER NOT NULL DEFAULT 0;
+	CREATE TABLE IF NOT EXISTS recovery_codes (
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		code_hash TEXT NOT NULL,
+		used_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (user_id, code_hash)
+	);`,
+	},
+	{
+		name: "add version to tracker table",
+		query: `
+	ALTER TABLE tracker ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;`,
+	},
+	{
+		name: "create idempotency keys table",
+		query: `
+	CREATE TABLE IF NOT EXISTS idempotency_keys (
+		scope TEXT NOT NULL,
+		key TEXT NOT NULL,
+		request_hash TEXT NOT NULL,
+		status_code INTEGER,
+		response_headers JSONB,
+		response_body BYTEA,
+		expires_at TIMESTAMPTZ NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (scope, key)
+	);
+	CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);`,
+	},
+	{
+		name: "create tracker events table",
+		query: `
+	CREATE TABLE IF NOT EXISTS tracker_events (
+		id BIGSERIAL PRIMARY KEY,
+		tracker_id INTEGER NOT NULL,
+		type TEXT NOT NULL,
+		version INTEGER NOT NULL,
+		payload JSONB NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS tracker_events_created_at_idx ON tracker_events (created_at);
+
+	CREATE OR REPLACE FUNCTION tracker_event_timestamp(ts TIMESTAMP) RETURNS TEXT AS $$
+		SELECT to_char(ts, 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"');
+	$$ LANGUAGE SQL IMMUTABLE;
+
+	CREATE OR REPLACE FUNCTION record_tracker_event() RETURNS TRIGGER AS $$
+	DECLARE
+		event_id BIGINT;
+	BEGIN
+		IF TG_OP = 'DELETE' THEN
+			INSERT INTO tracker_events (tracker_id, type, version, payload)
+			VALUES (OLD.id, 'deleted', OLD.version, jsonb_build_object('id', OLD.id))
+			RETURNING id INTO event_id;
+		ELSE
+			INSERT INTO tracker_events (tracker_id, type, version, payload)
+			VALUES (NEW.id, CASE TG_OP WHEN 'INSERT' THEN 'created' ELSE 'updated' END, NEW.version,
+				jsonb_build_object(
+					'id', NEW.id,
+					'task', NEW.task,
+					'project', NEW.project,
+					'start_time', tracker_event_timestamp(NEW.start_time),
+					'end_time', tracker_event_timestamp(NEW.end_time),
+					'created_at', tracker_event_timestamp(NEW.created_at),
+					'updated_at', tracker_event_timestamp(NEW.updated_at),
+					'version', NEW.version))
+			RETURNING id INTO event_id;
+		END IF;
+
+		PERFORM pg_notify('tracker_events', event_id::TEXT);
+		RETURN NULL;
+	END;
+	$$ LANGUAGE plpgsql;
+
+	DROP TRIGGER IF EXISTS tracker_events_trigger ON tracker;
+	CREATE TRIGGER tracker_events_trigger
+		AFTER INSERT OR UPDATE OR DELETE ON tracker
+		FOR EACH ROW EXECUTE FUNCTION record_tracker_event();`,
+	},
+	{
+		name: "add user to tracker table",
+		query: `
+	ALTER TABLE tracker ADD COLUMN IF NOT EXISTS user_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
+	CREATE INDEX IF NOT EXISTS tracker_user_running_idx ON tracker (user_id) WHERE end_time IS NULL;
+
+	CREATE OR REPLACE FUNCTION record_tracker_event() RETURNS TRIGGER AS $$
+	DECLARE
+		event_id BIGINT;
+	BEGIN
+		IF TG_OP = 'DELETE' THEN
+			INSERT INTO tracker_events (tracker_id, type, version, payload)
+			VALUES (OLD.id, 'deleted', OLD.version, jsonb_build_object('id', OLD.id))
+			RETURNING id INTO event_id;
+		ELSE
+			INSERT INTO tracker_events (tracker_id, type, version, payload)
+			VALUES (NEW.id, CASE TG_OP WHEN 'INSERT' THEN 'created' ELSE 'updated' END, NEW.version,
+				jsonb_build_object(
+					'id', NEW.id,
+					'task', NEW.task,
+					'project', NEW.project,
+					'start_time', tracker_event_timestamp(NEW.start_time),
+					'end_time', tracker_event_timestamp(NEW.end_time),
+					'created_at', tracker_event_timestamp(NEW.created_at),
+					'updated_at', tracker_event_timestamp(NEW.updated_at),
+					'version', NEW.version,
+					'user_id', NEW.user_id))
+			RETURNING id INTO event_id;
+		END IF;
+
+		PERFORM pg_notify('tracker_events', event_id::TEXT);
+		RETURN NULL;
+	END;
+	$$ LANGUAGE plpgsql;`,
+	},
+	{
+		name: "add delta sync columns",
+		query: `
+	ALTER TABLE tracker ADD COLUMN IF NOT EXISTS client_id UUID;
+	CREATE UNIQUE INDEX IF NOT EXISTS tracker_client_id_idx ON tracker (client_id);
+
+	ALTER TABLE tracker_events ADD COLUMN IF NOT EXISTS txid XID8 NOT NULL DEFAULT pg_current_xact_id();
+	CREATE INDEX IF NOT EXISTS tracker_events_txid_idx ON tracker_events (txid);
 
-	if _, err := db.Exec(query); err != nil {
-		return fmt.Errorf("failed to create tracker table: %w", err), query
+	CREATE OR REPLACE FUNCTION record_tracker_event() RETURNS TRIGGER AS $$
+	DECLARE
+		event_id BIGINT;
+	BEGIN
+		IF TG_OP = 'DELETE' THEN
+			INSERT INTO tracker_events (tracker_id, type, version, payload)
+			VALUES (OLD.id, 'deleted', OLD.version,
+				jsonb_build_object('id', OLD.id, 'user_id', OLD.user_id, 'client_id', OLD.client_id))
+			RETURNING id INTO event_id;
+		ELSE
+			INSERT INTO tracker_events (tracker_id, type, version, payload)
+			VALUES (NEW.id, CASE TG_OP WHEN 'INSERT' THEN 'created' ELSE 'updated' END, NEW.version,
+				jsonb_build_object(
+					'id', NEW.id,
+					'task', NEW.task,
+					'project', NEW.project,
+					'start_time', tracker_event_timestamp(NEW.start_time),
+					'end_time', tracker_event_timestamp(NEW.end_time),
+					'created_at', tracker_event_timestamp(NEW.created_at),
+					'updated_at', tracker_event_timestamp(NEW.updated_at),
+					'version', NEW.version,
+					'user_id', NEW.user_id,
+					'client_id', NEW.client_id))
+			RETURNING id INTO event_id;
+		END IF;
+
+		PERFORM pg_notify('tracker_events', event_id::TEXT);
+		RETURN NULL;
+	END;
+	$$ LANGUAGE plpgsql;`,
+	},
+	{
+		name: "create webhook tables",
+		query: `
+	CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+		id SERIAL PRIMARY KEY,
+		url TEXT NOT NULL,
+		event_types TEXT[] NOT NULL CHECK (cardinality(event_types) > 0),
+		secret TEXT NOT NULL,
+		created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id BIGSERIAL PRIMARY KEY,
+		subscription_id INTEGER NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+		event_id BIGINT NOT NULL,
+		event_type TEXT NOT NULL,
+		payload JSONB NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'failed')),
+		attempts INTEGER NOT NULL DEFAULT 0,
+		next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		last_status_code INTEGER,
+		last_error TEXT,
+		delivered_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+	CREATE INDEX IF NOT EXISTS webhook_deliveries_subscription_idx ON webhook_deliveries (subscription_id, id);
+
+	CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+		id BIGSERIAL PRIMARY KEY,
+		delivery_id BIGINT NOT NULL REFERENCES webhook_deliveries(id) ON DELETE CASCADE,
+		attempt INTEGER NOT NULL,
+		status_code INTEGER,
+		error TEXT,
+		duration_ms INTEGER NOT NULL,
+		attempted_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS webhook_delivery_attempts_delivery_idx ON webhook_delivery_attempts (delivery_id);
+
+	-- Deliveries are queued by the tracker trigger, so they commit or roll back with the write.
+	CREATE OR REPLACE FUNCTION enqueue_webhook_deliveries(tracker_event_id BIGINT, types TEXT[], body JSONB)
+	RETURNS VOID AS $$
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+		SELECT s.id, tracker_event_id, t.type, body
+		FROM webhook_subscriptions s
+		CROSS JOIN unnest(types) AS t(type)
+		WHERE t.type = ANY(s.event_types);
+	$$ LANGUAGE SQL;
+
+	CREATE OR REPLACE FUNCTION record_tracker_event() RETURNS TRIGGER AS $$
+	DECLARE
+		event_id BIGINT;
+		event_kind TEXT;
+		event_payload JSONB;
+		webhook_types TEXT[];
+	BEGIN
+		IF TG_OP = 'DELETE' THEN
+			event_kind := 'deleted';
+			event_payload := jsonb_build_object('id', OLD.id, 'user_id', OLD.user_id, 'client_id', OLD.client_id);
+			INSERT INTO tracker_events (tracker_id, type, version, payload)
+			VALUES (OLD.id, event_kind, OLD.version, event_payload)
+			RETURNING id INTO event_id;
+		ELSE
+			event_kind := CASE TG_OP WHEN 'INSERT' THEN 'created' ELSE 'updated' END;
+			event_payload := jsonb_build_object(
+				'id', NEW.id,
+				'task', NEW.task,
+				'project', NEW.project,
+				'start_time', tracker_event_timestamp(NEW.start_time),
+				'end_time', tracker_event_timestamp(NEW.end_time),
+				'created_at', tracker_event_timestamp(NEW.created_at),
+				'updated_at', tracker_event_timestamp(NEW.updated_at),
+				'version', NEW.version,
+				'user_id', NEW.user_id,
+				'client_id', NEW.client_id);
+			INSERT INTO tracker_events (tracker_id, type, version, payload)
+			VALUES (NEW.id, event_kind, NEW.version, event_payload)
+			RETURNING id INTO event_id;
+		END IF;
+
+		webhook_types := ARRAY['tracker.' || event_kind];
+		IF TG_OP = 'INSERT' THEN
+			IF NEW.end_time IS NULL THEN
+				webhook_types := webhook_types || 'tracker.started'::TEXT;
+			END IF;
+		ELSIF TG_OP = 'UPDATE' THEN
+			IF OLD.end_time IS NOT NULL AND NEW.end_time IS NULL THEN
+				webhook_types := webhook_types || 'tracker.started'::TEXT;
+			ELSIF OLD.end_time IS NULL AND NEW.end_time IS NOT NULL THEN
+				webhook_types := webhook_types || 'tracker.stopped'::TEXT;
+			END IF;
+		END IF;
+		PERFORM enqueue_webhook_deliveries(event_id, webhook_types, event_payload);
+
+		PERFORM pg_notify('tracker_events', event_id::TEXT);
+		RETURN NULL;
+	END;
+	$$ LANGUAGE plpgsql;`,
+	},
+	{
+		name: "add idempotency key locks",
+		query: `
+	ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS lock_token TEXT;
+	ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS locked_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP;`,
+	},
+	{
+		name: "add unique oidc identity index to users table",
+		query: `
+	CREATE UNIQUE INDEX IF NOT EXISTS users_oidc_identity_idx ON users (oidc_issuer, oidc_subject);`,
+	},
+}
+
+func Migrate(db *sql.DB) (error, string) {
+	for _, m := range migrations {
+		if _, err := db.Exec(m.query); err != nil {
+			return fmt.Errorf("failed to %s: %w", m.name, err), m.query
+		}
 	}
 
-	return nil, query
+	return nil, ""
 }
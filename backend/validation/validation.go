@@ -0,0 +1,440 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+// Package validation checks request structs against the rules declared in their validate tags,
+// e.g. `validate:"required,min=1,max=500"`. Rules are checked in the order they are declared and
+// the first rule a field breaks is reported. The supported rules are:
+//   - required: the field must not be nil or zero, strings must not be blank, optional fields
+//     must not be null
+//   - omitempty: skips the remaining rules when the field is nil, or zero when it is no pointer
+//   - min=n, max=n: bounds the number of characters of a string, the number of items of a slice
+//     or map, or the value of a number
+//   - oneof=a b c: the field must be one of the space separated values, every item of a slice
+//     must be one of them
+//   - uuid: the string must be a UUID
+//   - url: the string must be an absolute http or https URL
+//   - gtfield=F, gtefield=F, ltfield=F, ltefield=F: compares a time or number with the field F of
+//     the same struct, skipped while either of them is empty
+//
+// Nested structs are validated as well, their fields are reported as parent.child.
+package validation
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Codes of an Error, stable so they can be passed on to clients.
+const (
+	CodeRequired   = "required"
+	CodeBlank      = "blank"
+	CodeNull       = "null"
+	CodeTooShort   = "too_short"
+	CodeTooLong    = "too_long"
+	CodeOutOfRange = "out_of_range"
+	CodeOutOfOrder = "out_of_order"
+	CodeInvalid    = "invalid"
+)
+
+// Error describes why one field is invalid. Field is the JSON name of the field, nested fields
+// are joined with a dot (e.g. data.task).
+type Error struct {
+	Field   string
+	Code    string
+	Message string
+}
+
+// Optional is implemented by fields that tell apart an absent member from a null one, like the
+// members of a JSON merge patch. Absent fields are not validated, null fields only break required.
+type Optional interface {
+	OptionalValue() (value any, set bool, valid bool)
+}
+
+var (
+	optionalType = reflect.TypeOf((*Optional)(nil)).Elem()
+	timeType     = reflect.TypeOf(time.Time{})
+	uuidPattern  = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+type rule struct {
+	name  string
+	param string
+	// limit is the parsed param of min and max.
+	limit float64
+	// other is the field a cross-field rule compares with.
+	other *field
+}
+
+type field struct {
+	index  int
+	name   string
+	rules  []rule
+	nested bool
+}
+
+// fields caches the parsed rules by struct type.
+var fields sync.Map
+
+// Struct validates v, a struct or a pointer to one, and returns one error per invalid field.
+// It panics when a validate tag is malformed, which is a programming error like a bad regexp.
+func Struct(v any) []Error {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("validation: %T is not a struct", v))
+	}
+	return validateStruct(value, "")
+}
+
+func validateStruct(value reflect.Value, prefix string) []Error {
+	var errs []Error
+
+	for _, f := range structFields(value.Type()) {
+		name := prefix + f.name
+		raw := value.Field(f.index)
+		fieldValue, present, null := resolve(raw)
+		if !present {
+			continue
+		}
+		if null {
+			if f.has("required") {
+				errs = append(errs, fieldError(name, CodeNull, "%s cannot be null", name))
+			}
+			continue
+		}
+
+		failed := false
+		for _, r := range f.rules {
+			if r.name == "omitempty" {
+				// A pointer to a zero value was sent by the client and is validated.
+				if isEmpty(fieldValue) && (raw.Kind() != reflect.Pointer || raw.IsNil()) {
+					break
+				}
+				continue
+			}
+			if err, ok := check(value, fieldValue, name, prefix, r); !ok {
+				errs = append(errs, err)
+				failed = true
+				break
+			}
+		}
+
+		if !failed && f.nested && fieldValue.Kind() == reflect.Struct {
+			errs = append(errs, validateStruct(fieldValue, name+".")...)
+		}
+	}
+
+	return errs
+}
+
+// resolve unwraps optional fields and pointers. present is false for absent optional fields,
+// null is true for null optional fields. Nil pointers are returned as they are.
+func resolve(value reflect.Value) (resolved reflect.Value, present bool, null bool) {
+	if value.Type().Implements(optionalType) {
+		v, set, valid := value.Interface().(Optional).OptionalValue()
+		if !set {
+			return value, false, false
+		}
+		if !valid {
+			return value, true, true
+		}
+		value = reflect.ValueOf(v)
+	}
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+	return value, true, false
+}
+
+func isEmpty(value reflect.Value) bool {
+	return !value.IsValid() || value.IsZero()
+}
+
+func check(parent, value reflect.Value, name, prefix string, r rule) (Error, bool) {
+	if r.name == "required" {
+		if isEmpty(value) {
+			if value.Kind() == reflect.String {
+				return fieldError(name, CodeRequired, "%s is required and cannot be empty", name), false
+			}
+			return fieldError(name, CodeRequired, "%s is required", name), false
+		}
+		if value.Kind() == reflect.String && strings.TrimSpace(value.String()) == "" {
+			return fieldError(name, CodeBlank, "%s cannot contain only whitespace", name), false
+		}
+		return Error{}, true
+	}
+
+	// The other rules have nothing to check on a missing value, required reports it.
+	if value.Kind() == reflect.Pointer && value.IsNil() {
+		return Error{}, true
+	}
+
+	switch r.name {
+	case "min", "max":
+		return checkLimit(value, name, r)
+	case "oneof":
+		allowed := strings.Fields(r.param)
+		if value.Kind() == reflect.Slice {
+			for i := 0; i < value.Len(); i++ {
+				if !isOneOf(value.Index(i), allowed) {
+					return fieldError(name, CodeInvalid, "%s can only hold %s", name, alternatives(allowed)), false
+				}
+			}
+			return Error{}, true
+		}
+		if isOneOf(value, allowed) {
+			return Error{}, true
+		}
+		if len(allowed) == 2 {
+			return fieldError(name, CodeInvalid, "%s must be %s", name, alternatives(allowed)), false
+		}
+		return fieldError(name, CodeInvalid, "%s must be one of %s", name, alternatives(allowed)), false
+	case "uuid":
+		if !uuidPattern.MatchString(value.String()) {
+			return fieldError(name, CodeInvalid, "%s must be a UUID", name), false
+		}
+		return Error{}, true
+	case "url":
+		u, err := url.Parse(value.String())
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fieldError(name, CodeInvalid, "%s must be an absolute http or https URL", name), false
+		}
+		return Error{}, true
+	default:
+		return checkField(parent, value, name, prefix, r)
+	}
+}
+
+func isOneOf(value reflect.Value, allowed []string) bool {
+	actual := fmt.Sprint(value.Interface())
+	for _, a := range allowed {
+		if actual == a {
+			return true
+		}
+	}
+	return false
+}
+
+// alternatives lists values as "a or b" or "a, b or c".
+func alternatives(values []string) string {
+	if len(values) == 1 {
+		return values[0]
+	}
+	return strings.Join(values[:len(values)-1], ", ") + " or " + values[len(values)-1]
+}
+
+func checkLimit(value reflect.Value, name string, r rule) (Error, bool) {
+	min := r.name == "min"
+
+	switch value.Kind() {
+	case reflect.String:
+		length := float64(utf8.RuneCountInString(value.String()))
+		if min && length < r.limit {
+			return fieldError(name, CodeTooShort, "%s must be at least %s characters", name, r.param), false
+		}
+		if !min && length > r.limit {
+			return fieldError(name, CodeTooLong, "%s cannot exceed %s characters", name, r.param), false
+		}
+	case reflect.Slice, reflect.Map, reflect.Array:
+		length := float64(value.Len())
+		items := "items"
+		if r.limit == 1 {
+			items = "item"
+		}
+		if min && length < r.limit {
+			return fieldError(name, CodeOutOfRange, "%s must hold at least %s %s", name, r.param, items), false
+		}
+		if !min && length > r.limit {
+			return fieldError(name, CodeOutOfRange, "%s cannot hold more than %s %s", name, r.param, items), false
+		}
+	default:
+		number, ok := toFloat(value)
+		if !ok {
+			panic(fmt.Sprintf("validation: %s does not apply to %s of type %s", r.name, name, value.Type()))
+		}
+		if min && number < r.limit {
+			if r.limit == 0 {
+				return fieldError(name, CodeOutOfRange, "%s cannot be negative", name), false
+			}
+			return fieldError(name, CodeOutOfRange, "%s must be at least %s", name, r.param), false
+		}
+		if !min && number > r.limit {
+			return fieldError(name, CodeOutOfRange, "%s cannot be greater than %s", name, r.param), false
+		}
+	}
+	return Error{}, true
+}
+
+// checkField checks the cross-field rules. They are skipped while either field is empty, so
+// e.g. the end of a running tracker is not compared with its start.
+func checkField(parent, value reflect.Value, name, prefix string, r rule) (Error, bool) {
+	other, present, null := resolve(parent.Field(r.other.index))
+	if !present || null || isEmpty(other) || isEmpty(value) {
+		return Error{}, true
+	}
+	otherName := prefix + r.other.name
+
+	var cmp int
+	if value.Type() == timeType && other.Type() == timeType {
+		cmp = value.Interface().(time.Time).Compare(other.Interface().(time.Time))
+	} else {
+		a, okA := toFloat(value)
+		b, okB := toFloat(other)
+		if !okA || !okB {
+			panic(fmt.Sprintf("validation: %s cannot compare %s with %s", r.name, name, otherName))
+		}
+		switch {
+		case a < b:
+			cmp = -1
+		case a > b:
+			cmp = 1
+		}
+	}
+
+	isTime := value.Type() == timeType
+	switch {
+	case r.name == "gtfield" && cmp <= 0:
+		if isTime {
+			return fieldError(name, CodeOutOfOrder, "%s must be after %s", name, otherName), false
+		}
+		return fieldError(name, CodeOutOfOrder, "%s must be greater than %s", name, otherName), false
+	case r.name == "gtefield" && cmp < 0:
+		if isTime {
+			return fieldError(name, CodeOutOfOrder, "%s cannot be before %s", name, otherName), false
+		}
+		return fieldError(name, CodeOutOfOrder, "%s cannot be less than %s", name, otherName), false
+	case r.name == "ltfield" && cmp >= 0:
+		if isTime {
+			return fieldError(name, CodeOutOfOrder, "%s must be before %s", name, otherName), false
+		}
+		return fieldError(name, CodeOutOfOrder, "%s must be less than %s", name, otherName), false
+	case r.name == "ltefield" && cmp > 0:
+		if isTime {
+			return fieldError(name, CodeOutOfOrder, "%s cannot be after %s", name, otherName), false
+		}
+		return fieldError(name, CodeOutOfOrder, "%s cannot be greater than %s", name, otherName), false
+	}
+	return Error{}, true
+}
+
+func toFloat(value reflect.Value) (float64, bool) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	}
+	return 0, false
+}
+
+func fieldError(field, code, format string, args ...any) Error {
+	return Error{Field: field, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func (f *field) has(name string) bool {
+	for _, r := range f.rules {
+		if r.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func structFields(t reflect.Type) []*field {
+	if cached, ok := fields.Load(t); ok {
+		return cached.([]*field)
+	}
+	parsed := parseFields(t)
+	fields.Store(t, parsed)
+	return parsed
+}
+
+func parseFields(t reflect.Type) []*field {
+	byName := map[string]*field{}
+	var result []*field
+	tags := map[*field]string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name := sf.Name
+		if jsonName, _, _ := strings.Cut(sf.Tag.Get("json"), ","); jsonName != "" && jsonName != "-" {
+			name = jsonName
+		}
+		f := &field{index: i, name: name, nested: isNestedStruct(sf.Type)}
+		byName[sf.Name] = f
+
+		tag := sf.Tag.Get("validate")
+		if tag == "" && !f.nested {
+			continue
+		}
+		tags[f] = tag
+		result = append(result, f)
+	}
+
+	for _, f := range result {
+		if tags[f] == "" {
+			continue
+		}
+		for _, part := range strings.Split(tags[f], ",") {
+			r := rule{}
+			r.name, r.param, _ = strings.Cut(strings.TrimSpace(part), "=")
+
+			switch r.name {
+			case "required", "omitempty", "uuid", "url":
+			case "min", "max":
+				limit, err := strconv.ParseFloat(r.param, 64)
+				if err != nil {
+					panic(fmt.Sprintf("validation: invalid %s on %s.%s: %q", r.name, t.Name(), f.name, r.param))
+				}
+				r.limit = limit
+			case "oneof":
+				if len(strings.Fields(r.param)) < 2 {
+					panic(fmt.Sprintf("validation: oneof on %s.%s needs at least two values", t.Name(), f.name))
+				}
+			case "gtfield", "gtefield", "ltfield", "ltefield":
+				other, ok := byName[r.param]
+				if !ok {
+					panic(fmt.Sprintf("validation: %s on %s.%s refers to unknown field %q", r.name, t.Name(), f.name, r.param))
+				}
+				r.other = other
+			default:
+				panic(fmt.Sprintf("validation: unknown rule %q on %s.%s", r.name, t.Name(), f.name))
+			}
+			f.rules = append(f.rules, r)
+		}
+	}
+
+	return result
+}
+
+// isNestedStruct reports whether a field holds a struct whose fields are validated in turn.
+func isNestedStruct(t reflect.Type) bool {
+	if t.Implements(optionalType) {
+		return false
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != timeType
+}
@@ -0,0 +1,184 @@
+/*
+ *  Copyright © 2025 My personal.
+ *
+ * All rights reserved.
+ */
+
+package validation
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// optional is a member of a JSON merge patch, like model.Nullable: absent, null or a value.
+type optional[T any] struct {
+	Value T
+	Set   bool
+	Valid bool
+}
+
+func (o optional[T]) OptionalValue() (any, bool, bool) {
+	return o.Value, o.Set, o.Valid
+}
+
+func null[T any]() optional[T] {
+	return optional[T]{Set: true}
+}
+
+func value[T any](v T) optional[T] {
+	return optional[T]{Value: v, Set: true, Valid: true}
+}
+
+type task struct {
+	Name     string              `json:"name" validate:"required,min=2,max=5"`
+	Hours    int                 `json:"hours" validate:"min=0,max=24"`
+	Rate     float64             `json:"rate" validate:"max=1.5"`
+	Project  *string             `json:"project,omitempty" validate:"omitempty,min=3"`
+	Estimate *int                `json:"estimate,omitempty" validate:"omitempty,min=1"`
+	Note     optional[string]    `json:"note" validate:"required,max=3"`
+	Start    time.Time           `json:"start"`
+	End      *time.Time          `json:"end,omitempty" validate:"omitempty,gtefield=Start"`
+	Due      optional[time.Time] `json:"due" validate:"gtefield=Start"`
+}
+
+func validTask() task {
+	return task{Name: "Write", Hours: 8, Rate: 1, Note: value("ok")}
+}
+
+func TestStruct(t *testing.T) {
+	start := time.Date(2025, 10, 9, 9, 0, 0, 0, time.UTC)
+	earlier, later := start.Add(-time.Hour), start.Add(time.Hour)
+	empty, short, zero := "", "ab", 0
+
+	tests := []struct {
+		name   string
+		change func(*task)
+		want   []Error
+	}{
+		{"valid", func(*task) {}, nil},
+
+		{"required string empty", func(v *task) { v.Name = "" },
+			[]Error{{"name", CodeRequired, "name is required and cannot be empty"}}},
+		{"required string whitespace", func(v *task) { v.Name = "   " },
+			[]Error{{"name", CodeBlank, "name cannot contain only whitespace"}}},
+
+		{"string below min", func(v *task) { v.Name = "W" },
+			[]Error{{"name", CodeTooShort, "name must be at least 2 characters"}}},
+		{"string at min", func(v *task) { v.Name = "Wr" }, nil},
+		{"string at max counts characters", func(v *task) { v.Name = "Größe" }, nil},
+		{"string above max", func(v *task) { v.Name = "Writes" },
+			[]Error{{"name", CodeTooLong, "name cannot exceed 5 characters"}}},
+		{"number below min of 0", func(v *task) { v.Hours = -1 },
+			[]Error{{"hours", CodeOutOfRange, "hours cannot be negative"}}},
+		{"number at max", func(v *task) { v.Hours = 24 }, nil},
+		{"number above max", func(v *task) { v.Hours = 25 },
+			[]Error{{"hours", CodeOutOfRange, "hours cannot be greater than 24"}}},
+		{"float above max", func(v *task) { v.Rate = 1.75 },
+			[]Error{{"rate", CodeOutOfRange, "rate cannot be greater than 1.5"}}},
+
+		{"omitempty nil pointer", func(v *task) { v.Project = nil }, nil},
+		{"omitempty pointer to zero value", func(v *task) { v.Project = &empty },
+			[]Error{{"project", CodeTooShort, "project must be at least 3 characters"}}},
+		{"omitempty pointer to short value", func(v *task) { v.Project = &short },
+			[]Error{{"project", CodeTooShort, "project must be at least 3 characters"}}},
+		{"omitempty pointer to zero number", func(v *task) { v.Estimate = &zero },
+			[]Error{{"estimate", CodeOutOfRange, "estimate must be at least 1"}}},
+
+		{"optional absent", func(v *task) { v.Note = optional[string]{} }, nil},
+		{"optional null", func(v *task) { v.Note = null[string]() },
+			[]Error{{"note", CodeNull, "note cannot be null"}}},
+		{"optional value", func(v *task) { v.Note = value("long") },
+			[]Error{{"note", CodeTooLong, "note cannot exceed 3 characters"}}},
+
+		{"gtefield without end", func(v *task) { v.Start = start }, nil},
+		{"gtefield without start", func(v *task) { v.End = &earlier }, nil},
+		{"gtefield equal", func(v *task) { v.Start, v.End = start, &start }, nil},
+		{"gtefield before", func(v *task) { v.Start, v.End = start, &earlier },
+			[]Error{{"end", CodeOutOfOrder, "end cannot be before start"}}},
+		{"gtefield after", func(v *task) { v.Start, v.End = start, &later }, nil},
+		{"gtefield optional absent", func(v *task) { v.Start = start }, nil},
+		{"gtefield optional null", func(v *task) { v.Start, v.Due = start, null[time.Time]() }, nil},
+		{"gtefield optional before", func(v *task) { v.Start, v.Due = start, value(earlier) },
+			[]Error{{"due", CodeOutOfOrder, "due cannot be before start"}}},
+
+		{"first broken rule per field", func(v *task) { v.Name, v.Hours = "", 99 },
+			[]Error{
+				{"name", CodeRequired, "name is required and cannot be empty"},
+				{"hours", CodeOutOfRange, "hours cannot be greater than 24"},
+			}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := validTask()
+			tt.change(&v)
+			if got := Struct(&v); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Struct() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type parent struct {
+	Child  child  `json:"data"`
+	Others *child `json:"others,omitempty"`
+}
+
+type child struct {
+	Task string `json:"task" validate:"required"`
+}
+
+func TestStructNested(t *testing.T) {
+	got := Struct(parent{Others: &child{}})
+	want := []Error{
+		{"data.task", CodeRequired, "data.task is required and cannot be empty"},
+		{"others.task", CodeRequired, "others.task is required and cannot be empty"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Struct() = %v, want %v", got, want)
+	}
+
+	if got := Struct((*parent)(nil)); got != nil {
+		t.Errorf("Struct(nil) = %v, want nil", got)
+	}
+}
+
+func TestStructPanicsOnBadTags(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{"unknown rule", struct {
+			A string `validate:"requird"`
+		}{}, `unknown rule "requird"`},
+		{"malformed limit", struct {
+			A string `validate:"max=ten"`
+		}{}, `invalid max`},
+		{"oneof with one value", struct {
+			A string `validate:"oneof=a"`
+		}{}, `needs at least two values`},
+		{"unknown field", struct {
+			A int `validate:"gtefield=B"`
+		}{}, `unknown field "B"`},
+		{"limit on unsupported type", struct {
+			A bool `validate:"min=1"`
+		}{A: true}, `min does not apply`},
+		{"no struct", "text", `is not a struct`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				recovered := recover()
+				if message, _ := recovered.(string); !strings.Contains(message, tt.want) {
+					t.Errorf("panic %v, want one containing %q", recovered, tt.want)
+				}
+			}()
+			Struct(tt.value)
+		})
+	}
+}